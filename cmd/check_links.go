@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jcdickinson/simplemem/internal/memory"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkLinksJSON      bool
+	checkLinksNoOrphans bool
+)
+
+var checkLinksCmd = &cobra.Command{
+	Use:   "check-links",
+	Short: "Report dangling links and orphan memories",
+	Long: `Lists link targets that do not match any memory, together with the memories
+that contain them and a suggested fix, and lists orphan memories that have no
+links to or from other memories.`,
+	Args: cobra.NoArgs,
+	RunE: runCheckLinks,
+}
+
+func init() {
+	checkLinksCmd.Flags().BoolVar(&checkLinksJSON, "json", false, "Print the report as JSON")
+	checkLinksCmd.Flags().BoolVar(&checkLinksNoOrphans, "no-orphans", false, "Do not list orphan memories")
+	rootCmd.AddCommand(checkLinksCmd)
+}
+
+func runCheckLinks(cmd *cobra.Command, args []string) error {
+	store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	report, err := store.CheckLinks()
+	if err != nil {
+		return fmt.Errorf("failed to check links: %w", err)
+	}
+	if checkLinksNoOrphans {
+		report.Orphans = nil
+	}
+
+	if checkLinksJSON {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	fmt.Fprint(cmd.OutOrStdout(), memory.FormatLinkReportMarkdown(report, !checkLinksNoOrphans))
+	return nil
+}
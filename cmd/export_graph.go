@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jcdickinson/simplemem/internal/memory"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportGraphFormat        string
+	exportGraphOutput        string
+	exportGraphTags          []string
+	exportGraphRequireAll    bool
+	exportGraphEdgeTypes     []string
+	exportGraphClusterBy     string
+	exportGraphMinSimilarity float32
+)
+
+var exportGraphCmd = &cobra.Command{
+	Use:   "export-graph",
+	Short: "Export the memory graph as DOT, GraphML, Mermaid or JSON",
+	Long: `Exports memories as nodes (name, title, tags) and explicit links plus semantic
+backlinks above a similarity threshold as weighted edges.
+
+Examples:
+  simplemem export-graph --format dot --cluster-by project | dot -Tsvg > memories.svg
+  simplemem export-graph --format json --tag project=simplemem -o graph.json`,
+	Args: cobra.NoArgs,
+	RunE: runExportGraph,
+}
+
+func init() {
+	exportGraphCmd.Flags().StringVarP(&exportGraphFormat, "format", "f", memory.ExportDOT, "Output format: "+strings.Join(memory.ExportFormats, ", "))
+	exportGraphCmd.Flags().StringVarP(&exportGraphOutput, "output", "o", "", "Write the graph to a file instead of stdout")
+	exportGraphCmd.Flags().StringArrayVar(&exportGraphTags, "tag", nil, "Only export memories with this tag (key or key=value, repeatable)")
+	exportGraphCmd.Flags().BoolVar(&exportGraphRequireAll, "require-all", false, "Require all --tag filters to match instead of any")
+	exportGraphCmd.Flags().StringSliceVar(&exportGraphEdgeTypes, "edge-types", nil, "Edge types to export: "+strings.Join(memory.EdgeTypes, ", ")+" or a relation type (default: all)")
+	exportGraphCmd.Flags().StringVar(&exportGraphClusterBy, "cluster-by", "", "Tag key to group memories by")
+	exportGraphCmd.Flags().Float32Var(&exportGraphMinSimilarity, "min-similarity", 0.5, "Minimum similarity for semantic edges")
+	rootCmd.AddCommand(exportGraphCmd)
+}
+
+func runExportGraph(cmd *cobra.Command, args []string) error {
+	// Parse key or key=value tag filters; a bare key only checks for presence
+	tags := make(map[string]string)
+	for _, tag := range exportGraphTags {
+		key, value, _ := strings.Cut(tag, "=")
+		tags[key] = value
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	rendered, _, err := store.ExportGraph(memory.ExportOptions{
+		Format:        exportGraphFormat,
+		ClusterBy:     exportGraphClusterBy,
+		MinSimilarity: exportGraphMinSimilarity,
+		Filter: memory.GraphFilter{
+			EdgeTypes:  exportGraphEdgeTypes,
+			Tags:       tags,
+			RequireAll: exportGraphRequireAll,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export graph: %w", err)
+	}
+
+	if exportGraphOutput == "" {
+		fmt.Fprint(cmd.OutOrStdout(), rendered)
+		return nil
+	}
+	return os.WriteFile(exportGraphOutput, []byte(rendered), 0644)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jcdickinson/simplemem/internal/memory"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reconcileJSON   bool
+	reconcileDryRun bool
+)
+
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Remove database entries of memories whose files were deleted",
+	Long: `Removes the tags, embeddings, links and semantic backlinks of memories whose
+files were deleted outside simplemem, so searches stop returning them, and
+lists the memories that were removed. The server also does this at startup
+unless reconcile.on_startup is disabled.`,
+	Args: cobra.NoArgs,
+	RunE: runReconcile,
+}
+
+func init() {
+	reconcileCmd.Flags().BoolVar(&reconcileJSON, "json", false, "Print the report as JSON")
+	reconcileCmd.Flags().BoolVar(&reconcileDryRun, "dry-run", false, "Only list the memories that would be removed")
+	rootCmd.AddCommand(reconcileCmd)
+}
+
+func runReconcile(cmd *cobra.Command, args []string) error {
+	// Only the database is needed, so nothing is synced or embedded first
+	store, err := memory.OpenIndex(".memories", dbPath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	report, err := store.Reconcile(reconcileDryRun)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile: %w", err)
+	}
+
+	if reconcileJSON {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	fmt.Fprint(cmd.OutOrStdout(), memory.FormatReconcileReportMarkdown(report))
+	return nil
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jcdickinson/simplemem/internal/config"
+	"github.com/jcdickinson/simplemem/internal/memory"
+)
+
+// openStore loads the configuration and opens the memory store used by the server, syncing
+// any changed files into the database. The caller must close the store.
+func openStore() (*memory.EnhancedStore, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	store, err := memory.NewEnhancedStoreWithDBPath(".memories", cfg, dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create enhanced store: %w", err)
+	}
+
+	if err := store.Initialize(); err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to initialize enhanced store: %w", err)
+	}
+
+	return store, nil
+}
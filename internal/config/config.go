@@ -24,10 +24,90 @@ type VoyageAIConfig struct {
 	RerankModel string       `mapstructure:"rerank_model"`
 }
 
+// QueryCacheConfig holds configuration for the query embedding cache
+type QueryCacheConfig struct {
+	Size int    `mapstructure:"size"` // Maximum cached query embeddings; 0 disables the cache
+	Path string `mapstructure:"path"` // Optional file to persist the cache across restarts
+}
+
+// SemanticBacklinksConfig holds configuration for memory-to-memory semantic backlinks
+type SemanticBacklinksConfig struct {
+	Threshold float32 `mapstructure:"threshold"` // Minimum similarity for a backlink
+	TopN      int     `mapstructure:"top_n"`     // Maximum backlinks computed per memory
+	Method    string  `mapstructure:"method"`    // "centroid" or "top_k_pairs"
+	TopK      int     `mapstructure:"top_k"`     // Chunk pairs averaged by the top_k_pairs method
+}
+
+// RelationsConfig holds configuration for typed relations between memories
+type RelationsConfig struct {
+	Types    []string          `mapstructure:"types"`    // Relation types; empty uses the built-in set
+	Inverses map[string]string `mapstructure:"inverses"` // Name of each relation as seen from its target
+}
+
+// DuplicatesConfig holds configuration for near-duplicate detection
+type DuplicatesConfig struct {
+	Threshold     float32 `mapstructure:"threshold"`      // Minimum embedding similarity for a duplicate
+	TextThreshold float64 `mapstructure:"text_threshold"` // Minimum Jaccard similarity of word shingles for a duplicate
+	ShingleSize   int     `mapstructure:"shingle_size"`   // Words per shingle
+	WarnOnCreate  bool    `mapstructure:"warn_on_create"` // Report likely duplicates when a memory is created
+}
+
+// NamingConfig holds configuration for normalizing the names of new memories
+type NamingConfig struct {
+	NFC        bool   `mapstructure:"nfc"`         // Compose Unicode characters so equal-looking names are equal
+	Lowercase  bool   `mapstructure:"lowercase"`   // Lowercase names
+	Charset    string `mapstructure:"charset"`     // "unicode" keeps letters and digits of any script, "ascii" transliterates to a-z and 0-9
+	ExtraChars string `mapstructure:"extra_chars"` // Characters allowed besides letters and digits
+	Separator  string `mapstructure:"separator"`   // Replaces each run of other characters
+	MaxLength  int    `mapstructure:"max_length"`  // Maximum name length in bytes, including namespaces
+}
+
+// HistoryConfig holds configuration for memory revision history
+type HistoryConfig struct {
+	Enabled      bool `mapstructure:"enabled"`       // Keep a revision of every change to a memory
+	MaxRevisions int  `mapstructure:"max_revisions"` // Revisions kept per memory; 0 keeps all
+	MaxAgeDays   int  `mapstructure:"max_age_days"`  // Revisions older than this are pruned; 0 keeps them regardless of age
+}
+
+// GitConfig holds configuration for committing memory changes to git
+type GitConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`       // Commit changes to the memory directory automatically
+	QuietSeconds int    `mapstructure:"quiet_seconds"` // Changes are batched into one commit until none arrive for this long
+	AuthorName   string `mapstructure:"author_name"`   // Commit author; empty uses git's configured identity
+	AuthorEmail  string `mapstructure:"author_email"`  // Commit author email; empty uses git's configured identity
+}
+
+// WatchConfig holds configuration for watching the memory directory for external edits
+type WatchConfig struct {
+	Enabled    bool `mapstructure:"enabled"`     // Sync edits made outside simplemem while the server runs
+	DebounceMS int  `mapstructure:"debounce_ms"` // Changes are synced once none have arrived for this long
+}
+
+// ReconcileConfig holds configuration for removing database rows of memories whose files are gone
+type ReconcileConfig struct {
+	OnStartup bool `mapstructure:"on_startup"` // Reconcile when the store is opened
+}
+
+// TrashConfig holds configuration for keeping deleted memories in the trash
+type TrashConfig struct {
+	Enabled       bool `mapstructure:"enabled"`         // Move deleted memories to the trash instead of removing them
+	AutoPurgeDays int  `mapstructure:"auto_purge_days"` // Trashed memories older than this are purged; 0 keeps them until purged
+}
+
 // Config represents the complete simplemem configuration
 type Config struct {
-	VoyageAI        VoyageAIConfig `mapstructure:"voyage_ai"`
-	MaxMemoryLength int            `mapstructure:"max_memory_length"`
+	VoyageAI          VoyageAIConfig          `mapstructure:"voyage_ai"`
+	MaxMemoryLength   int                     `mapstructure:"max_memory_length"`
+	QueryCache        QueryCacheConfig        `mapstructure:"query_cache"`
+	SemanticBacklinks SemanticBacklinksConfig `mapstructure:"semantic_backlinks"`
+	Relations         RelationsConfig         `mapstructure:"relations"`
+	Duplicates        DuplicatesConfig        `mapstructure:"duplicates"`
+	Naming            NamingConfig            `mapstructure:"naming"`
+	History           HistoryConfig           `mapstructure:"history"`
+	Git               GitConfig               `mapstructure:"git"`
+	Watch             WatchConfig             `mapstructure:"watch"`
+	Reconcile         ReconcileConfig         `mapstructure:"reconcile"`
+	Trash             TrashConfig             `mapstructure:"trash"`
 }
 
 // InitializeViper sets up Viper configuration with proper search paths and defaults
@@ -63,6 +143,34 @@ func InitializeViper() error {
 	viper.SetDefault("voyage_ai.model", "voyage-3.5")
 	viper.SetDefault("voyage_ai.rerank_model", "rerank-lite-1")
 	viper.SetDefault("max_memory_length", 2500)
+	viper.SetDefault("query_cache.size", 1000)
+	viper.SetDefault("query_cache.path", "")
+	viper.SetDefault("semantic_backlinks.threshold", 0.5)
+	viper.SetDefault("semantic_backlinks.top_n", 20)
+	viper.SetDefault("semantic_backlinks.method", "centroid")
+	viper.SetDefault("semantic_backlinks.top_k", 3)
+	viper.SetDefault("duplicates.threshold", 0.92)
+	viper.SetDefault("duplicates.text_threshold", 0.5)
+	viper.SetDefault("duplicates.shingle_size", 3)
+	viper.SetDefault("duplicates.warn_on_create", true)
+	viper.SetDefault("naming.nfc", true)
+	viper.SetDefault("naming.lowercase", true)
+	viper.SetDefault("naming.charset", "unicode")
+	viper.SetDefault("naming.extra_chars", "-_.")
+	viper.SetDefault("naming.separator", "-")
+	viper.SetDefault("naming.max_length", 200)
+	viper.SetDefault("history.enabled", true)
+	viper.SetDefault("history.max_revisions", 50)
+	viper.SetDefault("history.max_age_days", 90)
+	viper.SetDefault("git.enabled", false)
+	viper.SetDefault("git.quiet_seconds", 5)
+	viper.SetDefault("git.author_name", "")
+	viper.SetDefault("git.author_email", "")
+	viper.SetDefault("watch.enabled", true)
+	viper.SetDefault("watch.debounce_ms", 500)
+	viper.SetDefault("reconcile.on_startup", true)
+	viper.SetDefault("trash.enabled", true)
+	viper.SetDefault("trash.auto_purge_days", 30)
 
 	// Enable environment variable support
 	viper.SetEnvPrefix("SIMPLEMEM")
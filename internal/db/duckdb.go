@@ -44,11 +44,15 @@ func (db *DB) Close() error {
 
 // initSchema creates all necessary tables
 func (db *DB) initSchema() error {
+	// The vector extension is optional: similarity is computed with the core array functions
+	for _, query := range []string{`INSTALL vss;`, `LOAD vss;`} {
+		if _, err := db.conn.Exec(query); err != nil {
+			log.Printf("[DB] Warning: vss extension unavailable: %v", err)
+			break
+		}
+	}
+
 	queries := []string{
-		// Enable vector extension
-		`INSTALL vss;`,
-		`LOAD vss;`,
-		
 		// Create sequences for auto-increment IDs
 		`CREATE SEQUENCE IF NOT EXISTS seq_memory_id START 1;`,
 		`CREATE SEQUENCE IF NOT EXISTS seq_tag_id START 1;`,
@@ -91,9 +95,13 @@ func (db *DB) initSchema() error {
 			from_memory_id INTEGER REFERENCES memories(id),
 			to_memory_name VARCHAR,
 			link_text VARCHAR,
-			link_type VARCHAR
+			link_type VARCHAR,
+			context TEXT
 		)`,
 
+		// Databases created before links were synced lack the context column
+		`ALTER TABLE memory_links ADD COLUMN IF NOT EXISTS context TEXT`,
+
 		// Create indexes separately for memory_links table
 		`CREATE INDEX IF NOT EXISTS idx_memory_links_from ON memory_links (from_memory_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_memory_links_to ON memory_links (to_memory_name)`,
@@ -125,6 +133,26 @@ func (db *DB) initSchema() error {
 		`CREATE INDEX IF NOT EXISTS idx_semantic_backlinks_a ON semantic_backlinks (memory_a_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_semantic_backlinks_b ON semantic_backlinks (memory_b_id)`,
 		// Note: Removed idx_semantic_backlinks_score index because it prevents ON CONFLICT updates in DuckDB
+
+		// A pair stays linked while either memory has it among its top matches. Pairs stored
+		// before this was tracked count as chosen by both.
+		`ALTER TABLE semantic_backlinks ADD COLUMN IF NOT EXISTS chosen_by_a BOOLEAN DEFAULT true`,
+		`ALTER TABLE semantic_backlinks ADD COLUMN IF NOT EXISTS chosen_by_b BOOLEAN DEFAULT true`,
+
+		// Mean chunk embedding of each memory, for scoring memories against each other
+		`CREATE TABLE IF NOT EXISTS memory_centroids (
+			memory_id INTEGER PRIMARY KEY,
+			centroid FLOAT[1024]
+		)`,
+
+		// Embeddings of deleted memories kept in the trash, so a restore does not re-embed them
+		`CREATE TABLE IF NOT EXISTS trashed_embeddings (
+			trash_id VARCHAR,
+			chunk_text TEXT,
+			chunk_index INTEGER,
+			embedding FLOAT[1024]
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_trashed_embeddings_trash_id ON trashed_embeddings (trash_id)`,
 	}
 
 	for _, query := range queries {
@@ -133,7 +161,8 @@ func (db *DB) initSchema() error {
 		}
 	}
 
-	return nil
+	// Memories embedded before centroids were kept get theirs now
+	return db.updateCentroids(`memory_id NOT IN (SELECT memory_id FROM memory_centroids)`)
 }
 
 // Memory represents a memory document in the database
@@ -169,6 +198,16 @@ type SemanticBacklink struct {
 	CreatedAt       time.Time `json:"created_at"`
 }
 
+// MemoryLink represents an explicit link from one memory to another (by name)
+type MemoryLink struct {
+	ID           int    `json:"id"`
+	FromMemoryID int    `json:"from_memory_id"`
+	ToMemoryName string `json:"to_memory_name"`
+	LinkText     string `json:"link_text"`
+	LinkType     string `json:"link_type"` // "wiki", "markdown", "frontmatter"
+	Context      string `json:"context"`   // Sentence surrounding the link in the source body
+}
+
 // UpsertMemory inserts or updates a memory document
 func (db *DB) UpsertMemory(memory *Memory) error {
 	log.Printf("[DB UPSERT] Upserting memory: %s (created: %v, modified: %v)", 
@@ -288,13 +327,7 @@ func (db *DB) InsertEmbedding(embedding *Embedding) error {
 	
 	// Convert []float32 to a format DuckDB can handle
 	// For DuckDB with VSS extension, we need to convert to proper array format
-	embeddingStr := fmt.Sprintf("[%s]", strings.Join(func() []string {
-		strs := make([]string, len(embedding.Embedding))
-		for i, v := range embedding.Embedding {
-			strs[i] = fmt.Sprintf("%g", v)
-		}
-		return strs
-	}(), ","))
+	embeddingStr := vectorLiteral(embedding.Embedding)
 	
 	query := `INSERT INTO embeddings (id, memory_id, chunk_text, chunk_index, embedding)
 		VALUES (nextval('seq_embedding_id'), ?, ?, ?, ?::FLOAT[1024])`
@@ -334,6 +367,7 @@ func (db *DB) DeleteMemory(name string) error {
 	// Delete related data manually (since we can't use CASCADE)
 	queries := []string{
 		`DELETE FROM embeddings WHERE memory_id = ?`,
+		`DELETE FROM memory_centroids WHERE memory_id = ?`,
 		`DELETE FROM tags WHERE memory_id = ?`,
 		`DELETE FROM memory_links WHERE from_memory_id = ?`,
 		`DELETE FROM semantic_backlinks WHERE memory_a_id = ? OR memory_b_id = ?`,
@@ -341,7 +375,7 @@ func (db *DB) DeleteMemory(name string) error {
 	}
 
 	for i, query := range queries {
-		if i == 3 { // semantic_backlinks query needs both parameters
+		if i == 4 { // semantic_backlinks query needs both parameters
 			_, err := db.conn.Exec(query, memory.ID, memory.ID)
 			if err != nil {
 				return fmt.Errorf("failed to delete semantic backlinks: %w", err)
@@ -357,6 +391,53 @@ func (db *DB) DeleteMemory(name string) error {
 	return nil
 }
 
+// TrashEmbeddings keeps the embeddings of a memory that is moved to the trash under its trash ID
+func (db *DB) TrashEmbeddings(memoryID int, trashID string) error {
+	query := `INSERT INTO trashed_embeddings (trash_id, chunk_text, chunk_index, embedding)
+		SELECT ?, chunk_text, chunk_index, embedding FROM embeddings WHERE memory_id = ?`
+	if _, err := db.conn.Exec(query, trashID, memoryID); err != nil {
+		return fmt.Errorf("failed to trash embeddings: %w", err)
+	}
+	return nil
+}
+
+// GetTrashedEmbeddings retrieves the embeddings kept for a trashed memory, ordered by chunk index
+func (db *DB) GetTrashedEmbeddings(trashID string) ([]Embedding, error) {
+	query := `SELECT chunk_text, chunk_index, embedding
+		FROM trashed_embeddings WHERE trash_id = ? ORDER BY chunk_index`
+
+	rows, err := db.conn.Query(query, trashID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trashed embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var embeddings []Embedding
+	for rows.Next() {
+		var embedding Embedding
+		var vector []interface{}
+		if err := rows.Scan(&embedding.ChunkText, &embedding.ChunkIndex, &vector); err != nil {
+			return nil, fmt.Errorf("failed to scan trashed embedding: %w", err)
+		}
+
+		embedding.Embedding, err = toVector(vector)
+		if err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, embedding)
+	}
+
+	return embeddings, nil
+}
+
+// DeleteTrashedEmbeddings removes the embeddings kept for a trashed memory
+func (db *DB) DeleteTrashedEmbeddings(trashID string) error {
+	if _, err := db.conn.Exec(`DELETE FROM trashed_embeddings WHERE trash_id = ?`, trashID); err != nil {
+		return fmt.Errorf("failed to delete trashed embeddings: %w", err)
+	}
+	return nil
+}
+
 // UpsertTags updates tags for a memory, replacing all existing tags
 func (db *DB) UpsertTags(memoryID int, tags map[string]interface{}) error {
 	// Delete existing tags for this memory
@@ -402,13 +483,7 @@ func (db *DB) FindSimilarMemories(embedding []float32, threshold float32, limit
 	}
 	
 	// Convert embedding to DuckDB array format
-	embeddingStr := fmt.Sprintf("[%s]", strings.Join(func() []string {
-		strs := make([]string, len(embedding))
-		for i, v := range embedding {
-			strs[i] = fmt.Sprintf("%g", v)
-		}
-		return strs
-	}(), ","))
+	embeddingStr := vectorLiteral(embedding)
 
 	// Embed the array directly in the query since DuckDB can't handle array parameters properly
 	query := fmt.Sprintf(`
@@ -461,26 +536,152 @@ func (db *DB) FindSimilarMemories(embedding []float32, threshold float32, limit
 	return results, nil
 }
 
-// UpsertSemanticBacklink creates or updates a bidirectional semantic relationship
-func (db *DB) UpsertSemanticBacklink(memoryAID, memoryBID int, similarity float32) error {
-	// Ensure consistent ordering (smaller ID first) for bidirectional relationship
-	if memoryAID > memoryBID {
-		memoryAID, memoryBID = memoryBID, memoryAID
+// ReplaceSemanticBacklinks replaces the semantic backlinks a memory chose with the given
+// similarities, keyed by the other memory's ID. Pairs the other memories chose are kept.
+func (db *DB) ReplaceSemanticBacklinks(memoryID int, similarities map[int]float32) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE semantic_backlinks SET chosen_by_a = false WHERE memory_a_id = ?`, memoryID); err != nil {
+		return fmt.Errorf("failed to clear semantic backlinks: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE semantic_backlinks SET chosen_by_b = false WHERE memory_b_id = ?`, memoryID); err != nil {
+		return fmt.Errorf("failed to clear semantic backlinks: %w", err)
 	}
 
-	// Use INSERT ... ON CONFLICT but without trying to update the id column
+	for otherID, similarity := range similarities {
+		// Pairs are stored once, with the smaller ID first
+		a, b, chosenByA := memoryID, otherID, true
+		if a > b {
+			a, b, chosenByA = b, a, false
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO semantic_backlinks (id, memory_a_id, memory_b_id, similarity_score, chosen_by_a, chosen_by_b)
+			VALUES (nextval('seq_backlink_id'), ?, ?, ?, ?, ?)
+			ON CONFLICT (memory_a_id, memory_b_id) DO UPDATE SET
+				similarity_score = EXCLUDED.similarity_score,
+				chosen_by_a = semantic_backlinks.chosen_by_a OR EXCLUDED.chosen_by_a,
+				chosen_by_b = semantic_backlinks.chosen_by_b OR EXCLUDED.chosen_by_b`,
+			a, b, similarity, chosenByA, !chosenByA)
+		if err != nil {
+			return fmt.Errorf("failed to upsert semantic backlink: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM semantic_backlinks WHERE NOT chosen_by_a AND NOT chosen_by_b`); err != nil {
+		return fmt.Errorf("failed to delete dropped semantic backlinks: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// UpdateMemoryCentroid recomputes the centroid of a memory from its stored chunk embeddings,
+// removing it if the memory has none
+func (db *DB) UpdateMemoryCentroid(memoryID int) error {
+	if _, err := db.conn.Exec(`DELETE FROM memory_centroids WHERE memory_id = ?`, memoryID); err != nil {
+		return fmt.Errorf("failed to delete memory centroid: %w", err)
+	}
+	return db.updateCentroids(`memory_id = ?`, memoryID)
+}
+
+// updateCentroids computes the centroids of the memories whose embeddings match a condition
+func (db *DB) updateCentroids(condition string, args ...interface{}) error {
+	query := fmt.Sprintf(`
+		INSERT INTO memory_centroids (memory_id, centroid)
+		SELECT memory_id, array_agg(value ORDER BY i)::FLOAT[1024]
+		FROM (
+			SELECT memory_id, i, AVG(embedding[i]) AS value
+			FROM embeddings, range(1, 1025) dims(i)
+			WHERE %s
+			GROUP BY memory_id, i
+		)
+		GROUP BY memory_id
+		ON CONFLICT (memory_id) DO UPDATE SET centroid = EXCLUDED.centroid`, condition)
+
+	if _, err := db.conn.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to update memory centroids: %w", err)
+	}
+	return nil
+}
+
+// SimilarByCentroid scores other memories by the cosine similarity of their centroid to the
+// memory's, returning at most limit scoring at least threshold, keyed by memory ID
+func (db *DB) SimilarByCentroid(memoryID int, threshold float32, limit int) (map[int]float32, error) {
 	query := `
-		INSERT INTO semantic_backlinks (id, memory_a_id, memory_b_id, similarity_score)
-		VALUES (nextval('seq_backlink_id'), ?, ?, ?)
-		ON CONFLICT (memory_a_id, memory_b_id) DO UPDATE SET
-			similarity_score = EXCLUDED.similarity_score`
+		SELECT * FROM (
+			SELECT o.memory_id, array_cosine_similarity(o.centroid, s.centroid) AS similarity
+			FROM memory_centroids s
+			JOIN memory_centroids o ON o.memory_id != s.memory_id
+			JOIN memories m ON m.id = o.memory_id
+			WHERE s.memory_id = ?
+		)
+		WHERE similarity >= ?
+		ORDER BY similarity DESC, memory_id
+		LIMIT ?`
+
+	return db.scoreMemories(query, memoryID, threshold, limit)
+}
 
-	_, err := db.conn.Exec(query, memoryAID, memoryBID, similarity)
+// GetMemoryCentroid retrieves the stored centroid of a memory, or nil if it has no embeddings
+func (db *DB) GetMemoryCentroid(memoryID int) ([]float32, error) {
+	var raw []interface{}
+	err := db.conn.QueryRow(`SELECT centroid FROM memory_centroids WHERE memory_id = ?`, memoryID).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to upsert semantic backlink: %w", err)
+		return nil, fmt.Errorf("failed to get memory centroid: %w", err)
 	}
+	return toVector(raw)
+}
 
-	return nil
+// SimilarByTopKPairs scores other memories by the mean similarity of their k most similar chunk
+// pairs with the memory, returning at most limit scoring at least threshold, keyed by memory ID
+func (db *DB) SimilarByTopKPairs(memoryID int, topK int, threshold float32, limit int) (map[int]float32, error) {
+	query := `
+		SELECT memory_id, AVG(similarity) AS score FROM (
+			SELECT memory_id, similarity,
+			       row_number() OVER (PARTITION BY memory_id ORDER BY similarity DESC) AS rank
+			FROM (
+				SELECT o.memory_id, array_cosine_similarity(o.embedding, s.embedding) AS similarity
+				FROM embeddings s
+				JOIN embeddings o ON o.memory_id != s.memory_id
+				JOIN memories m ON m.id = o.memory_id
+				WHERE s.memory_id = ?
+			)
+		)
+		WHERE rank <= ?
+		GROUP BY memory_id
+		HAVING AVG(similarity) >= ?
+		ORDER BY score DESC, memory_id
+		LIMIT ?`
+
+	return db.scoreMemories(query, memoryID, topK, threshold, limit)
+}
+
+// scoreMemories runs a query returning memory IDs and scores
+func (db *DB) scoreMemories(query string, args ...interface{}) (map[int]float32, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to score similar memories: %w", err)
+	}
+	defer rows.Close()
+
+	scores := make(map[int]float32)
+	for rows.Next() {
+		var memoryID int
+		var score float32
+		if err := rows.Scan(&memoryID, &score); err != nil {
+			return nil, fmt.Errorf("failed to scan memory score: %w", err)
+		}
+		scores[memoryID] = score
+	}
+
+	return scores, rows.Err()
 }
 
 // GetMemoryByID retrieves a memory by its ID
@@ -510,42 +711,73 @@ type TagFilter struct {
 	CheckValue bool // If false, only check for tag presence
 }
 
-// FindSimilarMemoriesWithTags finds memories similar to the given embedding vector, filtered by tags
-func (db *DB) FindSimilarMemoriesWithTags(embedding []float32, threshold float32, limit int, excludeMemoryID int, tagFilters []TagFilter, requireAll bool) ([]struct {
-	Memory     Memory
-	Similarity float32
-}, error) {
-	// Build the base query with tag filtering
+// buildTagCondition builds a parenthesized SQL condition (against memories aliased as m) matching the tag filters
+func buildTagCondition(tagFilters []TagFilter, requireAll bool) (string, []interface{}) {
 	var tagConditions []string
-	var tagParams []interface{}
-	
+	var params []interface{}
+
 	for i, filter := range tagFilters {
 		if filter.CheckValue && filter.Value != "" {
 			tagConditions = append(tagConditions, fmt.Sprintf("EXISTS (SELECT 1 FROM tags t%d WHERE t%d.memory_id = m.id AND t%d.tag_name = ? AND t%d.tag_value = ?)", i, i, i, i))
-			tagParams = append(tagParams, filter.Key, filter.Value)
+			params = append(params, filter.Key, filter.Value)
 		} else {
 			tagConditions = append(tagConditions, fmt.Sprintf("EXISTS (SELECT 1 FROM tags t%d WHERE t%d.memory_id = m.id AND t%d.tag_name = ?)", i, i, i))
-			tagParams = append(tagParams, filter.Key)
+			params = append(params, filter.Key)
 		}
 	}
 
+	if len(tagConditions) == 0 {
+		return "", nil
+	}
+
+	connector := " OR "
+	if requireAll {
+		connector = " AND "
+	}
+	return "(" + strings.Join(tagConditions, connector) + ")", params
+}
+
+// buildNamespaceCondition builds a SQL condition (against memories aliased as m) matching memories
+// in a namespace or its sub-namespaces, or an empty condition for the root namespace
+func buildNamespaceCondition(namespace string) (string, []interface{}) {
+	namespace = strings.Trim(namespace, "/")
+	if namespace == "" {
+		return "", nil
+	}
+	return "starts_with(m.name, ?)", []interface{}{namespace + "/"}
+}
+
+// vectorLiteral converts an embedding to a DuckDB array literal
+func vectorLiteral(embedding []float32) string {
+	strs := make([]string, len(embedding))
+	for i, v := range embedding {
+		strs[i] = fmt.Sprintf("%g", v)
+	}
+	return fmt.Sprintf("[%s]", strings.Join(strs, ","))
+}
+
+// FindSimilarMemoriesWithTags finds memories similar to the given embedding vector, filtered by tags
+// and namespace
+func (db *DB) FindSimilarMemoriesWithTags(embedding []float32, threshold float32, limit int, excludeMemoryID int, tagFilters []TagFilter, requireAll bool, namespace string) ([]struct {
+	Memory     Memory
+	Similarity float32
+}, error) {
+	// Build the base query with tag filtering
+	tagCondition, tagParams := buildTagCondition(tagFilters, requireAll)
+
 	var tagWhereClause string
-	if len(tagConditions) > 0 {
-		connector := " OR "
-		if requireAll {
-			connector = " AND "
-		}
-		tagWhereClause = " AND (" + strings.Join(tagConditions, connector) + ")"
+	if tagCondition != "" {
+		tagWhereClause = " AND " + tagCondition
+	}
+
+	namespaceCondition, namespaceParams := buildNamespaceCondition(namespace)
+	if namespaceCondition != "" {
+		tagWhereClause += " AND " + namespaceCondition
+		tagParams = append(tagParams, namespaceParams...)
 	}
 
 	// Convert embedding to DuckDB array format
-	embeddingStr := fmt.Sprintf("[%s]", strings.Join(func() []string {
-		strs := make([]string, len(embedding))
-		for i, v := range embedding {
-			strs[i] = fmt.Sprintf("%g", v)
-		}
-		return strs
-	}(), ","))
+	embeddingStr := vectorLiteral(embedding)
 
 	// Embed the array directly in the query since DuckDB can't handle array parameters properly
 	query := fmt.Sprintf(`
@@ -593,29 +825,24 @@ func (db *DB) FindSimilarMemoriesWithTags(embedding []float32, threshold float32
 	return results, nil
 }
 
-// GetMemoriesByTags retrieves memories filtered by tags (for non-semantic searches)
-func (db *DB) GetMemoriesByTags(tagFilters []TagFilter, requireAll bool, limit int) ([]Memory, error) {
+// GetMemoriesByTags retrieves memories filtered by tags and namespace (for non-semantic searches)
+func (db *DB) GetMemoriesByTags(tagFilters []TagFilter, requireAll bool, namespace string, limit int) ([]Memory, error) {
 	// Build tag filtering conditions
-	var tagConditions []string
-	var params []interface{}
-	
-	for i, filter := range tagFilters {
-		if filter.CheckValue && filter.Value != "" {
-			tagConditions = append(tagConditions, fmt.Sprintf("EXISTS (SELECT 1 FROM tags t%d WHERE t%d.memory_id = m.id AND t%d.tag_name = ? AND t%d.tag_value = ?)", i, i, i, i))
-			params = append(params, filter.Key, filter.Value)
-		} else {
-			tagConditions = append(tagConditions, fmt.Sprintf("EXISTS (SELECT 1 FROM tags t%d WHERE t%d.memory_id = m.id AND t%d.tag_name = ?)", i, i, i))
-			params = append(params, filter.Key)
-		}
+	var conditions []string
+	tagCondition, params := buildTagCondition(tagFilters, requireAll)
+	if tagCondition != "" {
+		conditions = append(conditions, tagCondition)
+	}
+
+	namespaceCondition, namespaceParams := buildNamespaceCondition(namespace)
+	if namespaceCondition != "" {
+		conditions = append(conditions, namespaceCondition)
+		params = append(params, namespaceParams...)
 	}
 
 	var whereClause string
-	if len(tagConditions) > 0 {
-		connector := " OR "
-		if requireAll {
-			connector = " AND "
-		}
-		whereClause = " WHERE " + strings.Join(tagConditions, connector)
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
 	}
 
 	query := fmt.Sprintf(`
@@ -674,4 +901,518 @@ func (db *DB) GetSemanticBacklinks(memoryID int, minSimilarity float32) ([]Seman
 	}
 
 	return backlinks, nil
-}
\ No newline at end of file
+}
+
+// GetEmbeddingsByMemoryID retrieves all chunk embeddings stored for a memory, ordered by chunk index
+func (db *DB) GetEmbeddingsByMemoryID(memoryID int) ([]Embedding, error) {
+	query := `SELECT id, memory_id, chunk_text, chunk_index, embedding, created_at
+		FROM embeddings WHERE memory_id = ? ORDER BY chunk_index`
+
+	rows, err := db.conn.Query(query, memoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var embeddings []Embedding
+	for rows.Next() {
+		var embedding Embedding
+		var vector []interface{}
+		err := rows.Scan(&embedding.ID, &embedding.MemoryID, &embedding.ChunkText,
+			&embedding.ChunkIndex, &vector, &embedding.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan embedding: %w", err)
+		}
+
+		embedding.Embedding, err = toVector(vector)
+		if err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, embedding)
+	}
+
+	return embeddings, nil
+}
+
+// GetAllChunkVectors retrieves every stored chunk embedding, grouped by memory ID in chunk order
+func (db *DB) GetAllChunkVectors() (map[int][][]float32, error) {
+	rows, err := db.conn.Query(`SELECT memory_id, embedding FROM embeddings ORDER BY memory_id, chunk_index`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunk vectors: %w", err)
+	}
+	defer rows.Close()
+
+	vectors := make(map[int][][]float32)
+	for rows.Next() {
+		var memoryID int
+		var raw []interface{}
+		if err := rows.Scan(&memoryID, &raw); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk vector: %w", err)
+		}
+
+		vector, err := toVector(raw)
+		if err != nil {
+			return nil, err
+		}
+		vectors[memoryID] = append(vectors[memoryID], vector)
+	}
+
+	return vectors, nil
+}
+
+// GetMemoryNames returns the name of every memory, keyed by ID
+func (db *DB) GetMemoryNames() (map[int]string, error) {
+	rows, err := db.conn.Query(`SELECT id, name FROM memories`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memory names: %w", err)
+	}
+	defer rows.Close()
+
+	names := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan memory name: %w", err)
+		}
+		names[id] = name
+	}
+
+	return names, nil
+}
+
+// toVector converts a scanned FLOAT[N] array, which DuckDB returns as []any of float32
+func toVector(raw []interface{}) ([]float32, error) {
+	vector := make([]float32, len(raw))
+	for i, v := range raw {
+		f, ok := v.(float32)
+		if !ok {
+			return nil, fmt.Errorf("unexpected embedding element type %T", v)
+		}
+		vector[i] = f
+	}
+	return vector, nil
+}
+
+// FindSimilarToVectors finds memories whose chunks are closest to any of the given vectors.
+// Each memory is scored by its best chunk/vector pair, so it appears at most once in the results.
+func (db *DB) FindSimilarToVectors(vectors [][]float32, threshold float32, limit int, excludeNames []string, tagFilters []TagFilter, requireAll bool, namespace string) ([]struct {
+	Memory     Memory
+	Similarity float32
+}, error) {
+	if len(vectors) == 0 {
+		return nil, fmt.Errorf("no vectors provided")
+	}
+
+	// Embed the vectors directly in the query since DuckDB can't handle array parameters properly
+	values := make([]string, len(vectors))
+	for i, vector := range vectors {
+		values[i] = fmt.Sprintf("(%s::FLOAT[1024])", vectorLiteral(vector))
+	}
+
+	var conditions []string
+	var params []interface{}
+
+	if len(excludeNames) > 0 {
+		placeholders := make([]string, len(excludeNames))
+		for i, name := range excludeNames {
+			placeholders[i] = "?"
+			params = append(params, name)
+		}
+		conditions = append(conditions, "m.name NOT IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	tagCondition, tagParams := buildTagCondition(tagFilters, requireAll)
+	if tagCondition != "" {
+		conditions = append(conditions, tagCondition)
+		params = append(params, tagParams...)
+	}
+
+	namespaceCondition, namespaceParams := buildNamespaceCondition(namespace)
+	if namespaceCondition != "" {
+		conditions = append(conditions, namespaceCondition)
+		params = append(params, namespaceParams...)
+	}
+
+	var whereClause string
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		WITH query_vectors(vec) AS (VALUES %s)
+		SELECT m.id, m.name, m.title, m.description, m.content, m.body,
+		       m.created, m.modified, m.last_processed, m.file_hash,
+		       MAX(1 - (e.embedding <=> q.vec)) as similarity
+		FROM memories m
+		JOIN embeddings e ON m.id = e.memory_id
+		CROSS JOIN query_vectors q%s
+		GROUP BY ALL
+		HAVING MAX(1 - (e.embedding <=> q.vec)) > ?
+		ORDER BY similarity DESC
+		LIMIT ?`, strings.Join(values, ", "), whereClause)
+
+	params = append(params, threshold, limit)
+
+	rows, err := db.conn.Query(query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find similar memories by vectors: %w", err)
+	}
+	defer rows.Close()
+
+	var results []struct {
+		Memory     Memory
+		Similarity float32
+	}
+
+	for rows.Next() {
+		var result struct {
+			Memory     Memory
+			Similarity float32
+		}
+
+		err := rows.Scan(&result.Memory.ID, &result.Memory.Name, &result.Memory.Title,
+			&result.Memory.Description, &result.Memory.Content, &result.Memory.Body,
+			&result.Memory.Created, &result.Memory.Modified, &result.Memory.LastProcessed,
+			&result.Memory.FileHash, &result.Similarity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan similar memory by vectors: %w", err)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// ReplaceMemoryLinks replaces all outbound links of a memory
+func (db *DB) ReplaceMemoryLinks(fromMemoryID int, links []MemoryLink) error {
+	// Delete existing links for this memory
+	_, err := db.conn.Exec(`DELETE FROM memory_links WHERE from_memory_id = ?`, fromMemoryID)
+	if err != nil {
+		return fmt.Errorf("failed to delete existing links: %w", err)
+	}
+
+	for _, link := range links {
+		_, err := db.conn.Exec(
+			`INSERT INTO memory_links (id, from_memory_id, to_memory_name, link_text, link_type, context)
+			VALUES (nextval('seq_link_id'), ?, ?, ?, ?, ?)`,
+			fromMemoryID, link.ToMemoryName, link.LinkText, link.LinkType, link.Context,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert link to %s: %w", link.ToMemoryName, err)
+		}
+	}
+
+	return nil
+}
+
+// GetOutboundLinks retrieves the links stored for a memory, in insertion order
+func (db *DB) GetOutboundLinks(fromMemoryID int) ([]MemoryLink, error) {
+	query := `SELECT id, from_memory_id, to_memory_name, link_text, link_type, COALESCE(context, '')
+		FROM memory_links WHERE from_memory_id = ? ORDER BY id`
+
+	rows, err := db.conn.Query(query, fromMemoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbound links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []MemoryLink
+	for rows.Next() {
+		var link MemoryLink
+		if err := rows.Scan(&link.ID, &link.FromMemoryID, &link.ToMemoryName, &link.LinkText, &link.LinkType, &link.Context); err != nil {
+			return nil, fmt.Errorf("failed to scan link: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+// InboundLink is an explicit link to a memory together with the memory it comes from
+type InboundLink struct {
+	Link   MemoryLink
+	Source Memory
+}
+
+// GetInboundLinks retrieves the links pointing at a memory name, ordered by source memory
+func (db *DB) GetInboundLinks(toMemoryName string) ([]InboundLink, error) {
+	query := `
+		SELECT l.id, l.from_memory_id, l.to_memory_name, l.link_text, l.link_type, COALESCE(l.context, ''),
+			m.id, m.name, m.title, m.description, m.content, m.body, m.created, m.modified, m.last_processed, m.file_hash
+		FROM memory_links l
+		JOIN memories m ON m.id = l.from_memory_id
+		WHERE l.to_memory_name = ?
+		ORDER BY m.name, l.id`
+
+	rows, err := db.conn.Query(query, toMemoryName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inbound links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []InboundLink
+	for rows.Next() {
+		var inbound InboundLink
+		err := rows.Scan(&inbound.Link.ID, &inbound.Link.FromMemoryID, &inbound.Link.ToMemoryName,
+			&inbound.Link.LinkText, &inbound.Link.LinkType, &inbound.Link.Context,
+			&inbound.Source.ID, &inbound.Source.Name, &inbound.Source.Title, &inbound.Source.Description,
+			&inbound.Source.Content, &inbound.Source.Body, &inbound.Source.Created, &inbound.Source.Modified,
+			&inbound.Source.LastProcessed, &inbound.Source.FileHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan inbound link: %w", err)
+		}
+		links = append(links, inbound)
+	}
+
+	return links, nil
+}
+
+// GetDanglingLinks retrieves links whose target memory does not exist, ordered by target and source
+func (db *DB) GetDanglingLinks() ([]InboundLink, error) {
+	query := `
+		SELECT l.id, l.from_memory_id, l.to_memory_name, l.link_text, l.link_type, COALESCE(l.context, ''),
+			m.id, m.name, m.title, m.description, m.content, m.body, m.created, m.modified, m.last_processed, m.file_hash
+		FROM memory_links l
+		JOIN memories m ON m.id = l.from_memory_id
+		WHERE NOT EXISTS (SELECT 1 FROM memories t WHERE t.name = l.to_memory_name)
+		ORDER BY l.to_memory_name, m.name, l.id`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dangling links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []InboundLink
+	for rows.Next() {
+		var inbound InboundLink
+		err := rows.Scan(&inbound.Link.ID, &inbound.Link.FromMemoryID, &inbound.Link.ToMemoryName,
+			&inbound.Link.LinkText, &inbound.Link.LinkType, &inbound.Link.Context,
+			&inbound.Source.ID, &inbound.Source.Name, &inbound.Source.Title, &inbound.Source.Description,
+			&inbound.Source.Content, &inbound.Source.Body, &inbound.Source.Created, &inbound.Source.Modified,
+			&inbound.Source.LastProcessed, &inbound.Source.FileHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dangling link: %w", err)
+		}
+		links = append(links, inbound)
+	}
+
+	return links, nil
+}
+
+// GetOrphanMemories returns the names of memories with no links to or from any other existing memory
+func (db *DB) GetOrphanMemories() ([]string, error) {
+	query := `
+		SELECT m.name
+		FROM memories m
+		WHERE NOT EXISTS (
+			SELECT 1 FROM memory_links l
+			JOIN memories t ON t.name = l.to_memory_name
+			WHERE l.from_memory_id = m.id AND t.id != m.id
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM memory_links l
+			WHERE l.to_memory_name = m.name AND l.from_memory_id != m.id
+		)
+		ORDER BY m.name`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orphan memories: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan orphan memory: %w", err)
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// GetMemoryTitles returns the title of every memory keyed by name
+func (db *DB) GetMemoryTitles() (map[string]string, error) {
+	rows, err := db.conn.Query(`SELECT name, COALESCE(title, '') FROM memories`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memory titles: %w", err)
+	}
+	defer rows.Close()
+
+	titles := make(map[string]string)
+	for rows.Next() {
+		var name, title string
+		if err := rows.Scan(&name, &title); err != nil {
+			return nil, fmt.Errorf("failed to scan memory title: %w", err)
+		}
+		titles[name] = title
+	}
+
+	return titles, nil
+}
+
+// GraphNode is a memory as a node of the link graph
+type GraphNode struct {
+	Name  string
+	Title string
+	Tags  map[string]string
+}
+
+// GraphEdge is a directed edge of the link graph; semantic edges are undirected and stored once
+type GraphEdge struct {
+	From   string
+	To     string
+	Type   string  // Link type ("wiki", "markdown", "frontmatter") or "semantic"
+	Weight float32 // 1 for explicit links, similarity score for semantic edges
+}
+
+// GetGraphNodes retrieves every memory with its title and tags, ordered by name
+func (db *DB) GetGraphNodes() ([]GraphNode, error) {
+	rows, err := db.conn.Query(`
+		SELECT m.name, COALESCE(m.title, ''), t.tag_name, t.tag_value
+		FROM memories m
+		LEFT JOIN tags t ON t.memory_id = m.id
+		ORDER BY m.name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph nodes: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []GraphNode
+	for rows.Next() {
+		var name, title string
+		var tagName, tagValue sql.NullString
+		if err := rows.Scan(&name, &title, &tagName, &tagValue); err != nil {
+			return nil, fmt.Errorf("failed to scan graph node: %w", err)
+		}
+
+		// Rows are ordered by name, so each memory's tags are consecutive
+		if len(nodes) == 0 || nodes[len(nodes)-1].Name != name {
+			nodes = append(nodes, GraphNode{Name: name, Title: title, Tags: make(map[string]string)})
+		}
+		if tagName.Valid {
+			nodes[len(nodes)-1].Tags[tagName.String] = tagValue.String
+		}
+	}
+
+	return nodes, nil
+}
+
+// GetGraphEdges retrieves explicit links between existing memories and semantic backlinks with at
+// least minSimilarity
+func (db *DB) GetGraphEdges(minSimilarity float32) ([]GraphEdge, error) {
+	query := `
+		SELECT m.name, t.name, l.link_type, 1.0::FLOAT
+		FROM memory_links l
+		JOIN memories m ON m.id = l.from_memory_id
+		JOIN memories t ON t.name = l.to_memory_name
+		WHERE m.id != t.id
+		UNION ALL
+		SELECT a.name, b.name, 'semantic', s.similarity_score
+		FROM semantic_backlinks s
+		JOIN memories a ON a.id = s.memory_a_id
+		JOIN memories b ON b.id = s.memory_b_id
+		WHERE s.similarity_score >= ?`
+
+	rows, err := db.conn.Query(query, minSimilarity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph edges: %w", err)
+	}
+	defer rows.Close()
+
+	var edges []GraphEdge
+	for rows.Next() {
+		var edge GraphEdge
+		if err := rows.Scan(&edge.From, &edge.To, &edge.Type, &edge.Weight); err != nil {
+			return nil, fmt.Errorf("failed to scan graph edge: %w", err)
+		}
+		edges = append(edges, edge)
+	}
+
+	return edges, nil
+}
+
+// renameCopies copies the rows that belong to a memory ($1) to its new ID ($2). Semantic backlinks
+// are stored with the smaller ID first, so pairs are reordered and their chosen flags follow.
+var renameCopies = []struct {
+	table string
+	query string
+}{
+	{"embeddings", `INSERT INTO embeddings SELECT * REPLACE (nextval('seq_embedding_id') AS id, $2 AS memory_id)
+		FROM embeddings WHERE memory_id = $1`},
+	{"memory_centroids", `INSERT INTO memory_centroids SELECT * REPLACE ($2 AS memory_id)
+		FROM memory_centroids WHERE memory_id = $1`},
+	{"tags", `INSERT INTO tags SELECT * REPLACE (nextval('seq_tag_id') AS id, $2 AS memory_id)
+		FROM tags WHERE memory_id = $1`},
+	{"memory_links", `INSERT INTO memory_links SELECT * REPLACE (nextval('seq_link_id') AS id, $2 AS from_memory_id)
+		FROM memory_links WHERE from_memory_id = $1`},
+	{"semantic_backlinks", `INSERT INTO semantic_backlinks (id, memory_a_id, memory_b_id, similarity_score, created_at, chosen_by_a, chosen_by_b)
+		SELECT nextval('seq_backlink_id'), LEAST(other, $2), GREATEST(other, $2), similarity_score, created_at,
+			CASE WHEN other < $2 THEN other_chose ELSE own_chose END,
+			CASE WHEN other < $2 THEN own_chose ELSE other_chose END
+		FROM (
+			SELECT memory_b_id AS other, chosen_by_a AS own_chose, chosen_by_b AS other_chose, similarity_score, created_at
+			FROM semantic_backlinks WHERE memory_a_id = $1
+			UNION ALL
+			SELECT memory_a_id, chosen_by_b, chosen_by_a, similarity_score, created_at
+			FROM semantic_backlinks WHERE memory_b_id = $1
+		)`},
+}
+
+// RenameMemory changes the name of a memory, keeping its embeddings, centroid, tags, outbound
+// links and semantic backlinks. Inbound links are not touched; they are refreshed when the
+// linking memories are synced.
+//
+// DuckDB checks unique and foreign key constraints eagerly, so the name cannot be updated while
+// other tables reference the row, and a row cannot be deleted and re-inserted with the same key
+// in one transaction. Instead the memory and its rows are copied to a new ID in one transaction,
+// and the old rows are deleted afterwards. The memory therefore gets a new ID; if deleting the
+// old rows fails, the stale row under the old name is left for the next reconcile to prune.
+func (db *DB) RenameMemory(oldName, newName string) error {
+	memory, err := db.GetMemory(oldName)
+	if err != nil {
+		return fmt.Errorf("failed to get memory for rename: %w", err)
+	}
+	if memory == nil {
+		return fmt.Errorf("memory not found: %s", oldName)
+	}
+	if existing, err := db.GetMemory(newName); err != nil {
+		return fmt.Errorf("failed to check new memory name: %w", err)
+	} else if existing != nil {
+		return fmt.Errorf("memory already exists: %s", newName)
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var newID int
+	err = tx.QueryRow(`INSERT INTO memories SELECT * REPLACE (nextval('seq_memory_id') AS id, $2 AS name)
+		FROM memories WHERE id = $1 RETURNING id`, memory.ID, newName).Scan(&newID)
+	if err != nil {
+		return fmt.Errorf("failed to copy memory: %w", err)
+	}
+	for _, c := range renameCopies {
+		if _, err := tx.Exec(c.query, memory.ID, newID); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", c.table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit rename: %w", err)
+	}
+
+	// The rename is committed; rows left under the old name are only stale
+	if err := db.DeleteMemory(oldName); err != nil {
+		log.Printf("[DB RENAME] Warning: failed to delete memory %s after renaming it to %s: %v", oldName, newName, err)
+	}
+
+	log.Printf("[DB RENAME] Renamed memory %s to %s (ID: %d -> %d)", oldName, newName, memory.ID, newID)
+	return nil
+}
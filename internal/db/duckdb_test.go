@@ -0,0 +1,293 @@
+package db
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestDB opens a database in a temporary directory
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	database, err := New(filepath.Join(t.TempDir(), "test.duckdb"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+// vector pads the given leading values to an embedding
+func vector(values ...float32) []float32 {
+	v := make([]float32, 1024)
+	copy(v, values)
+	return v
+}
+
+// addMemory stores a memory with one embedding per vector and returns its ID
+func addMemory(t *testing.T, database *DB, name string, vectors ...[]float32) int {
+	t.Helper()
+	memory := &Memory{Name: name, Content: name, Body: name}
+	if err := database.UpsertMemory(memory); err != nil {
+		t.Fatalf("UpsertMemory(%s) error = %v", name, err)
+	}
+	stored, err := database.GetMemory(name)
+	if err != nil || stored == nil {
+		t.Fatalf("GetMemory(%s) = %v, %v", name, stored, err)
+	}
+	for i, v := range vectors {
+		if err := database.InsertEmbedding(&Embedding{MemoryID: stored.ID, ChunkText: name, ChunkIndex: i, Embedding: v}); err != nil {
+			t.Fatalf("InsertEmbedding(%s) error = %v", name, err)
+		}
+	}
+	if err := database.UpdateMemoryCentroid(stored.ID); err != nil {
+		t.Fatalf("UpdateMemoryCentroid(%s) error = %v", name, err)
+	}
+	return stored.ID
+}
+
+func TestSimilarMemories(t *testing.T) {
+	database := newTestDB(t)
+	a := addMemory(t, database, "a", vector(1, 0, 0), vector(0, 1, 0))
+	b := addMemory(t, database, "b", vector(1, 0, 0), vector(0, 0, 1))
+	c := addMemory(t, database, "c", vector(0, 0, 0, 1))
+
+	// Centroids (0.5, 0.5, 0) and (0.5, 0, 0.5) meet at 60 degrees; c is orthogonal to both
+	scores, err := database.SimilarByCentroid(a, 0.1, 10)
+	if err != nil {
+		t.Fatalf("SimilarByCentroid() error = %v", err)
+	}
+	if len(scores) != 1 || math.Abs(float64(scores[b])-0.5) > 1e-6 {
+		t.Errorf("SimilarByCentroid() = %v, want only %d scoring 0.5", scores, b)
+	}
+
+	// One identical pair, the other three pairs are orthogonal
+	scores, err = database.SimilarByTopKPairs(a, 1, 0.1, 10)
+	if err != nil || math.Abs(float64(scores[b])-1) > 1e-6 {
+		t.Errorf("SimilarByTopKPairs(k=1) = %v, %v, want %d scoring 1", scores, err, b)
+	}
+	scores, err = database.SimilarByTopKPairs(a, 2, 0.1, 10)
+	if err != nil || math.Abs(float64(scores[b])-0.5) > 1e-6 {
+		t.Errorf("SimilarByTopKPairs(k=2) = %v, %v, want %d scoring 0.5", scores, err, b)
+	}
+	if _, ok := scores[c]; ok {
+		t.Errorf("expected %d below the threshold, got %v", c, scores)
+	}
+}
+
+func TestReplaceSemanticBacklinks(t *testing.T) {
+	database := newTestDB(t)
+	a := addMemory(t, database, "a", vector(1))
+	b := addMemory(t, database, "b", vector(1))
+	c := addMemory(t, database, "c", vector(1))
+
+	linked := func(memoryID int) map[int]float32 {
+		t.Helper()
+		backlinks, err := database.GetSemanticBacklinks(memoryID, 0)
+		if err != nil {
+			t.Fatalf("GetSemanticBacklinks() error = %v", err)
+		}
+		others := make(map[int]float32)
+		for _, backlink := range backlinks {
+			if backlink.MemoryAID > backlink.MemoryBID {
+				t.Errorf("expected the smaller ID first, got %+v", backlink)
+			}
+			other := backlink.MemoryAID
+			if other == memoryID {
+				other = backlink.MemoryBID
+			}
+			others[other] = backlink.SimilarityScore
+		}
+		return others
+	}
+
+	// b and c both choose a; a chooses b
+	for _, step := range []struct {
+		id    int
+		chose map[int]float32
+	}{
+		{b, map[int]float32{a: 0.9}},
+		{c, map[int]float32{a: 0.8}},
+		{a, map[int]float32{b: 0.9}},
+	} {
+		if err := database.ReplaceSemanticBacklinks(step.id, step.chose); err != nil {
+			t.Fatalf("ReplaceSemanticBacklinks(%d) error = %v", step.id, err)
+		}
+	}
+	if got := linked(a); len(got) != 2 || got[b] != 0.9 || got[c] != 0.8 {
+		t.Errorf("backlinks of a = %v, want b and c", got)
+	}
+
+	// a dropping its choices keeps the pairs b and c chose
+	if err := database.ReplaceSemanticBacklinks(a, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := linked(a); len(got) != 2 {
+		t.Errorf("backlinks of a = %v, want the pairs chosen by b and c", got)
+	}
+
+	// Once neither side chooses a pair it is removed
+	if err := database.ReplaceSemanticBacklinks(b, map[int]float32{c: 0.7}); err != nil {
+		t.Fatal(err)
+	}
+	if got := linked(a); len(got) != 1 || got[c] != 0.8 {
+		t.Errorf("backlinks of a = %v, want only c", got)
+	}
+	if got := linked(b); len(got) != 1 || got[c] != 0.7 {
+		t.Errorf("backlinks of b = %v, want only c", got)
+	}
+}
+
+func TestRenameMemory(t *testing.T) {
+	database := newTestDB(t)
+	a := addMemory(t, database, "a", vector(1, 0), vector(0, 1))
+	b := addMemory(t, database, "b", vector(1, 0))
+	c := addMemory(t, database, "c", vector(0, 1))
+
+	if err := database.UpsertTags(b, map[string]interface{}{"topic": "go"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.ReplaceMemoryLinks(b, []MemoryLink{{ToMemoryName: "c", LinkType: "wiki"}}); err != nil {
+		t.Fatal(err)
+	}
+	// a chooses b, and b chooses c
+	if err := database.ReplaceSemanticBacklinks(a, map[int]float32{b: 0.9}); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.ReplaceSemanticBacklinks(b, map[int]float32{c: 0.6}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := database.RenameMemory("b", "z"); err != nil {
+		t.Fatalf("RenameMemory() error = %v", err)
+	}
+	if old, err := database.GetMemory("b"); err != nil || old != nil {
+		t.Fatalf("GetMemory(old name) = %v, %v, want nothing", old, err)
+	}
+	renamed, err := database.GetMemory("z")
+	if err != nil || renamed == nil {
+		t.Fatalf("GetMemory(new name) = %v, %v", renamed, err)
+	}
+	z := renamed.ID
+
+	if embeddings, err := database.GetEmbeddingsByMemoryID(z); err != nil || len(embeddings) != 1 {
+		t.Errorf("embeddings = %v, %v, want 1", embeddings, err)
+	}
+	if memories, err := database.GetMemoriesByTags([]TagFilter{{Key: "topic", Value: "go", CheckValue: true}}, true, "", 10); err != nil || len(memories) != 1 || memories[0].Name != "z" {
+		t.Errorf("memories tagged topic=go = %v, %v, want z", memories, err)
+	}
+	if links, err := database.GetOutboundLinks(z); err != nil || len(links) != 1 || links[0].ToMemoryName != "c" {
+		t.Errorf("outbound links = %v, %v, want c", links, err)
+	}
+	if scores, err := database.SimilarByCentroid(a, 0.1, 10); err != nil || scores[z] == 0 {
+		t.Errorf("SimilarByCentroid() = %v, %v, want the centroid of z", scores, err)
+	}
+
+	backlinks, err := database.GetSemanticBacklinks(z, 0)
+	if err != nil || len(backlinks) != 2 {
+		t.Fatalf("GetSemanticBacklinks() = %v, %v, want a and c", backlinks, err)
+	}
+	// The pair b chose is dropped with its choice; the pair a chose is kept
+	if err := database.ReplaceSemanticBacklinks(z, nil); err != nil {
+		t.Fatal(err)
+	}
+	backlinks, err = database.GetSemanticBacklinks(z, 0)
+	if err != nil || len(backlinks) != 1 || backlinks[0].MemoryAID != a {
+		t.Errorf("GetSemanticBacklinks() = %v, %v, want only the pair chosen by a", backlinks, err)
+	}
+
+	if err := database.RenameMemory("a", "z"); err == nil {
+		t.Error("expected renaming onto an existing memory to fail")
+	}
+}
+
+func TestFindSimilarToVectors(t *testing.T) {
+	database := newTestDB(t)
+	a := addMemory(t, database, "a", vector(1, 0), vector(0, 1))
+	addMemory(t, database, "b", vector(0.8, 0.6))
+	addMemory(t, database, "c", vector(0, 1))
+	addMemory(t, database, "tagged/d", vector(1, 0))
+	if err := database.UpsertTags(a, map[string]interface{}{"topic": "x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Chunks come back in index order
+	embeddings, err := database.GetEmbeddingsByMemoryID(a)
+	if err != nil || len(embeddings) != 2 || embeddings[0].ChunkIndex != 0 || embeddings[1].Embedding[1] != 1 {
+		t.Fatalf("GetEmbeddingsByMemoryID() = %v, %v", embeddings, err)
+	}
+
+	names := func(vectors [][]float32, exclude []string, tags []TagFilter, namespace string) string {
+		t.Helper()
+		results, err := database.FindSimilarToVectors(vectors, 0.1, 10, exclude, tags, false, namespace)
+		if err != nil {
+			t.Fatalf("FindSimilarToVectors() error = %v", err)
+		}
+		var found []string
+		for _, result := range results {
+			found = append(found, result.Memory.Name)
+		}
+		return strings.Join(found, ",")
+	}
+
+	// Each memory scores its best chunk against the best query vector; orthogonal ones drop out
+	if got := names([][]float32{vector(1, 0)}, []string{"a"}, nil, ""); got != "tagged/d,b" {
+		t.Errorf("one vector = %s, want tagged/d,b", got)
+	}
+	if got := names([][]float32{vector(0, 1), vector(0.6, 0.8)}, []string{"a", "tagged/d"}, nil, ""); got != "c,b" {
+		t.Errorf("two vectors = %s, want c,b", got)
+	}
+	if got := names([][]float32{vector(1, 0)}, nil, []TagFilter{{Key: "topic"}}, ""); got != "a" {
+		t.Errorf("tag filter = %s, want a", got)
+	}
+	if got := names([][]float32{vector(1, 0)}, nil, nil, "tagged"); got != "tagged/d" {
+		t.Errorf("namespace = %s, want tagged/d", got)
+	}
+}
+
+func TestLinkHealth(t *testing.T) {
+	database := newTestDB(t)
+	a := addMemory(t, database, "a", vector(1))
+	addMemory(t, database, "b", vector(1))
+	c := addMemory(t, database, "c", vector(1))
+	addMemory(t, database, "d", vector(1))
+
+	// a links to b and a missing memory; c only links to itself and a missing memory
+	if err := database.ReplaceMemoryLinks(a, []MemoryLink{{ToMemoryName: "b", LinkType: "wiki"}, {ToMemoryName: "missing", LinkType: "wiki"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.ReplaceMemoryLinks(c, []MemoryLink{{ToMemoryName: "c", LinkType: "wiki"}, {ToMemoryName: "missing", LinkType: "frontmatter"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	dangling, err := database.GetDanglingLinks()
+	if err != nil {
+		t.Fatalf("GetDanglingLinks() error = %v", err)
+	}
+	var got []string
+	for _, link := range dangling {
+		got = append(got, link.Source.Name+"->"+link.Link.ToMemoryName)
+	}
+	if want := []string{"a->missing", "c->missing"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetDanglingLinks() = %v, want %v", got, want)
+	}
+
+	orphans, err := database.GetOrphanMemories()
+	if err != nil {
+		t.Fatalf("GetOrphanMemories() error = %v", err)
+	}
+	if want := []string{"c", "d"}; !reflect.DeepEqual(orphans, want) {
+		t.Errorf("GetOrphanMemories() = %v, want %v", orphans, want)
+	}
+
+	// Creating the missing memory resolves both links and gives c a neighbour
+	addMemory(t, database, "missing", vector(1))
+	if dangling, err := database.GetDanglingLinks(); err != nil || len(dangling) != 0 {
+		t.Errorf("GetDanglingLinks() = %+v, %v, want none", dangling, err)
+	}
+	if orphans, err := database.GetOrphanMemories(); err != nil || !reflect.DeepEqual(orphans, []string{"d"}) {
+		t.Errorf("GetOrphanMemories() = %v, %v, want [d]", orphans, err)
+	}
+}
@@ -0,0 +1,193 @@
+package embeddings
+
+import (
+	"container/list"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// cacheKey identifies a cached embedding; vectors differ per model and input type
+type cacheKey struct {
+	Model     string
+	InputType string
+	Text      string
+}
+
+// cacheEntry is a single cached embedding, also used as the on-disk record format
+type cacheEntry struct {
+	Key       cacheKey
+	Embedding []float32
+}
+
+// CacheStats reports query cache effectiveness
+type CacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Size      int    `json:"size" jsonschema:"description=Embeddings currently cached"`
+	Capacity  int    `json:"capacity" jsonschema:"description=Maximum embeddings cached"`
+}
+
+// HitRate returns the fraction of lookups served from the cache
+func (s CacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// QueryCache is a bounded LRU cache of text-to-embedding results, optionally persisted to a file
+type QueryCache struct {
+	mu       sync.Mutex
+	capacity int
+	path     string
+	order    *list.List // Front is most recently used
+	items    map[cacheKey]*list.Element
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// NewQueryCache creates a cache holding up to capacity embeddings. If path is non-empty, Load and
+// Save read and write the cache entries from that file.
+func NewQueryCache(capacity int, path string) *QueryCache {
+	if capacity <= 0 {
+		capacity = 1000 // Default capacity
+	}
+
+	return &QueryCache{
+		capacity: capacity,
+		path:     path,
+		order:    list.New(),
+		items:    make(map[cacheKey]*list.Element),
+	}
+}
+
+// Get returns the cached embedding for a text, marking it as recently used
+func (c *QueryCache) Get(model, inputType, text string) ([]float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[cacheKey{Model: model, InputType: inputType, Text: text}]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	c.hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).Embedding, true
+}
+
+// Put stores an embedding, evicting the least recently used entry when the cache is full
+func (c *QueryCache) Put(model, inputType, text string, embedding []float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.put(cacheKey{Model: model, InputType: inputType, Text: text}, embedding)
+}
+
+// put inserts or refreshes an entry; the caller must hold the lock
+func (c *QueryCache) put(key cacheKey, embedding []float32) {
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*cacheEntry).Embedding = embedding
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&cacheEntry{Key: key, Embedding: embedding})
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).Key)
+		c.evictions++
+	}
+}
+
+// Stats returns a snapshot of the cache metrics
+func (c *QueryCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Size:      c.order.Len(),
+		Capacity:  c.capacity,
+	}
+}
+
+// Save writes the cache to its file, if persistence is enabled
+func (c *QueryCache) Save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	// Oldest first, so loading replays entries in LRU order
+	entries := make([]cacheEntry, 0, c.order.Len())
+	for elem := c.order.Back(); elem != nil; elem = elem.Prev() {
+		entries = append(entries, *elem.Value.(*cacheEntry))
+	}
+	c.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create query cache directory: %w", err)
+	}
+
+	tmpPath := c.path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create query cache file: %w", err)
+	}
+
+	if err := gob.NewEncoder(file).Encode(entries); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to encode query cache: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write query cache: %w", err)
+	}
+
+	return os.Rename(tmpPath, c.path)
+}
+
+// Load reads previously saved entries, if persistence is enabled; a missing file is not an error
+func (c *QueryCache) Load() error {
+	if c.path == "" {
+		return nil
+	}
+
+	file, err := os.Open(c.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open query cache file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []cacheEntry
+	if err := gob.NewDecoder(file).Decode(&entries); err != nil {
+		return fmt.Errorf("failed to decode query cache file %s: %w", c.path, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, entry := range entries {
+		c.put(entry.Key, entry.Embedding)
+	}
+	// Entries dropped while loading into a smaller cache are not real evictions
+	c.evictions = 0
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package embeddings
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// queryInputType is the input type the processor embeds queries with
+const queryInputType = ""
+
+func TestQueryCacheLRU(t *testing.T) {
+	cache := NewQueryCache(2, "")
+
+	cache.Put("voyage-3.5", queryInputType, "a", []float32{1})
+	cache.Put("voyage-3.5", queryInputType, "b", []float32{2})
+
+	// Touch "a" so "b" becomes the least recently used entry
+	if _, ok := cache.Get("voyage-3.5", queryInputType, "a"); !ok {
+		t.Fatalf("expected 'a' to be cached")
+	}
+	cache.Put("voyage-3.5", queryInputType, "c", []float32{3})
+
+	if _, ok := cache.Get("voyage-3.5", queryInputType, "b"); ok {
+		t.Errorf("expected 'b' to be evicted")
+	}
+	if embedding, ok := cache.Get("voyage-3.5", queryInputType, "c"); !ok || embedding[0] != 3 {
+		t.Errorf("expected 'c' to be cached, got %v", embedding)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Evictions != 1 || stats.Size != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestQueryCacheKeyedByModelAndInputType(t *testing.T) {
+	cache := NewQueryCache(10, "")
+	cache.Put("voyage-3.5", queryInputType, "text", []float32{1})
+
+	if _, ok := cache.Get("voyage-3-large", queryInputType, "text"); ok {
+		t.Errorf("expected a miss for a different model")
+	}
+	if _, ok := cache.Get("voyage-3.5", "rerank", "text"); ok {
+		t.Errorf("expected a miss for a different input type")
+	}
+	if _, ok := cache.Get("voyage-3.5", queryInputType, "text"); !ok {
+		t.Errorf("expected a hit for the same model and input type")
+	}
+}
+
+func TestQueryCachePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "queries.gob")
+
+	cache := NewQueryCache(10, path)
+	if err := cache.Load(); err != nil {
+		t.Fatalf("Load() on missing file error = %v", err)
+	}
+	cache.Put("voyage-3.5", queryInputType, "old", []float32{1})
+	cache.Put("voyage-3.5", queryInputType, "new", []float32{2})
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Reload into a smaller cache: only the most recently used entry fits
+	reloaded := NewQueryCache(1, path)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if embedding, ok := reloaded.Get("voyage-3.5", queryInputType, "new"); !ok || embedding[0] != 2 {
+		t.Errorf("expected 'new' to survive reload, got %v", embedding)
+	}
+	if _, ok := reloaded.Get("voyage-3.5", queryInputType, "old"); ok {
+		t.Errorf("expected 'old' to be dropped when reloading into a smaller cache")
+	}
+	if stats := reloaded.Stats(); stats.Evictions != 0 {
+		t.Errorf("expected loading to not count evictions, got %d", stats.Evictions)
+	}
+}
@@ -30,8 +30,9 @@ func NewVoyageClient(apiKey string) *VoyageClient {
 
 // EmbedRequest represents a request to the embeddings API
 type EmbedRequest struct {
-	Input []string `json:"input"`
-	Model string   `json:"model"`
+	Input     []string `json:"input"`
+	Model     string   `json:"model"`
+	InputType string   `json:"input_type,omitempty"`
 }
 
 // EmbedResponse represents a response from the embeddings API
@@ -48,6 +49,12 @@ type EmbedResponse struct {
 
 // EmbedTexts generates embeddings for a list of texts
 func (c *VoyageClient) EmbedTexts(texts []string, model string) ([][]float32, error) {
+	return c.EmbedTextsWithInputType(texts, model, "")
+}
+
+// EmbedTextsWithInputType generates embeddings for a list of texts, telling the API whether they are queries or documents.
+// An empty input type embeds the texts as-is.
+func (c *VoyageClient) EmbedTextsWithInputType(texts []string, model string, inputType string) ([][]float32, error) {
 	log.Printf("[VOYAGE AI] Starting embedding generation for %d texts using model: %s", len(texts), model)
 	
 	if len(texts) == 0 {
@@ -69,8 +76,9 @@ func (c *VoyageClient) EmbedTexts(texts []string, model string) ([][]float32, er
 
 	// Create request payload
 	reqData := EmbedRequest{
-		Input: texts,
-		Model: model,
+		Input:     texts,
+		Model:     model,
+		InputType: inputType,
 	}
 
 	jsonData, err := json.Marshal(reqData)
@@ -191,17 +199,32 @@ func (b *BatchEmbedder) EmbedAllChunks(chunks []Chunk, model string) ([][]float3
 		return nil, fmt.Errorf("no chunks provided")
 	}
 
+	// Extract text from chunks
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
+	}
+
+	return b.EmbedAllTexts(texts, model, "")
+}
+
+// EmbedAllTexts processes plain texts (such as search queries) in batches with rate limiting
+func (b *BatchEmbedder) EmbedAllTexts(texts []string, model string, inputType string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("no texts provided")
+	}
+
 	var allEmbeddings [][]float32
 
 	// Process in batches
-	for i := 0; i < len(chunks); i += b.batchSize {
+	for i := 0; i < len(texts); i += b.batchSize {
 		end := i + b.batchSize
-		if end > len(chunks) {
-			end = len(chunks)
+		if end > len(texts) {
+			end = len(texts)
 		}
 
-		batch := chunks[i:end]
-		embeddings, err := b.client.EmbedChunks(batch, model)
+		batch := texts[i:end]
+		embeddings, err := b.client.EmbedTextsWithInputType(batch, model, inputType)
 		if err != nil {
 			return nil, fmt.Errorf("failed to embed batch starting at %d: %w", i, err)
 		}
@@ -209,7 +232,7 @@ func (b *BatchEmbedder) EmbedAllChunks(chunks []Chunk, model string) ([][]float3
 		allEmbeddings = append(allEmbeddings, embeddings...)
 
 		// Add delay between batches (except for the last batch)
-		if end < len(chunks) {
+		if end < len(texts) {
 			time.Sleep(b.delay)
 		}
 	}
@@ -0,0 +1,493 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jcdickinson/simplemem/internal/db"
+	"github.com/jcdickinson/simplemem/internal/embeddings"
+	"github.com/jcdickinson/simplemem/internal/memory"
+	"github.com/jcdickinson/simplemem/internal/rag"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Output formats accepted by the format argument of every tool
+const (
+	formatMarkdown = "markdown"
+	formatJSON     = "json"
+	formatBoth     = "both"
+)
+
+// withFormat adds the shared format argument to a tool definition
+func withFormat() mcp.ToolOption {
+	return mcp.WithString("format",
+		mcp.Description("Text output format: 'markdown' (default, human-readable), 'json' (the structured content serialized as text) or 'both'. Structured content is always included."),
+		mcp.Enum(formatMarkdown, formatJSON, formatBoth),
+	)
+}
+
+// withIfMatch adds the optional if_match argument of tools that change a memory
+func withIfMatch() mcp.ToolOption {
+	return mcp.WithString("if_match",
+		mcp.Description("Version of the memory as returned by read_memory, or a prefix of at least 12 characters. The change fails with a conflict, showing the current version and what changed, if the memory was modified since"),
+	)
+}
+
+// newConflictResult builds the error result of a change whose if_match did not match the memory
+func newConflictResult(request mcp.CallToolRequest, conflict *memory.ConflictError) (*mcp.CallToolResult, error) {
+	result, err := newFormattedResult(request, memory.FormatConflictMarkdown(conflict), ConflictOutput{Error: "conflict", ConflictError: *conflict})
+	if err != nil {
+		return nil, err
+	}
+	result.IsError = true
+	return result, nil
+}
+
+// newFormattedResult builds a tool result carrying structured content plus the text requested by the format argument
+func newFormattedResult(request mcp.CallToolRequest, markdown string, structured any) (*mcp.CallToolResult, error) {
+	format := request.GetString("format", formatMarkdown)
+
+	var content []mcp.Content
+	switch format {
+	case formatMarkdown:
+		content = append(content, mcp.NewTextContent(markdown))
+	case formatJSON, formatBoth:
+		data, err := json.MarshalIndent(structured, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode structured result: %w", err)
+		}
+		if format == formatBoth {
+			content = append(content, mcp.NewTextContent(markdown))
+		}
+		content = append(content, mcp.NewTextContent(string(data)))
+	default:
+		return nil, fmt.Errorf("unknown format '%s' (expected markdown, json or both)", format)
+	}
+
+	return &mcp.CallToolResult{
+		Content:           content,
+		StructuredContent: structured,
+	}, nil
+}
+
+// MemoryHit is a ranked memory returned by search_memories and find_similar
+type MemoryHit struct {
+	Name           string         `json:"name"`
+	Title          string         `json:"title,omitempty"`
+	Description    string         `json:"description,omitempty"`
+	Tags           map[string]any `json:"tags,omitempty"`
+	Snippet        string         `json:"snippet"`
+	Similarity     float32        `json:"similarity" jsonschema:"description=Cosine similarity (best matching query for multi-query searches)"`
+	FusionScore    float32        `json:"fusion_score,omitempty" jsonschema:"description=Reciprocal rank fusion score (multi-query searches only)"`
+	MatchedQueries []string       `json:"matched_queries,omitempty" jsonschema:"description=Queries that returned this memory (multi-query searches only)"`
+}
+
+// SearchOutput is the structured result of search_memories
+type SearchOutput struct {
+	Queries    []string          `json:"queries"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	RequireAll bool              `json:"require_all"`
+	Namespace  string            `json:"namespace,omitempty"`
+	Results    []MemoryHit       `json:"results"`
+}
+
+// SimilarOutput is the structured result of find_similar
+type SimilarOutput struct {
+	Name         string      `json:"name"`
+	Mode         string      `json:"mode"`
+	Results      []MemoryHit `json:"results"`
+	ResolvedFrom string      `json:"resolved_from,omitempty" jsonschema:"description=Name as requested when it was resolved to a different memory"`
+}
+
+// GrepHit is a single matching line returned by grep_memories
+type GrepHit struct {
+	Name   string   `json:"name"`
+	Line   int      `json:"line" jsonschema:"description=1-based line number within the memory body"`
+	Text   string   `json:"text"`
+	Before []string `json:"before,omitempty" jsonschema:"description=Context lines before the match"`
+	After  []string `json:"after,omitempty" jsonschema:"description=Context lines after the match"`
+}
+
+// GrepOutput is the structured result of grep_memories
+type GrepOutput struct {
+	Pattern    string    `json:"pattern"`
+	Mode       string    `json:"mode" jsonschema:"enum=literal,enum=ignore_case,enum=regex"`
+	Total      int       `json:"total" jsonschema:"description=Number of matching lines across all pages"`
+	Offset     int       `json:"offset"`
+	NextOffset int       `json:"next_offset,omitempty" jsonschema:"description=Offset of the next page, if there are more matches"`
+	Matches    []GrepHit `json:"matches"`
+}
+
+// BacklinkHit is a single related memory returned by get_backlinks
+type BacklinkHit struct {
+	Name        string  `json:"name"`
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Snippet     string  `json:"snippet"`
+	LinkType    string  `json:"link_type" jsonschema:"description=explicit or semantic"`
+	SourceType  string  `json:"source_type" jsonschema:"description=wiki, markdown, frontmatter, a relation type or embedding"`
+	Relevance   float32 `json:"relevance"`
+}
+
+// BacklinksOutput is the structured result of get_backlinks
+type BacklinksOutput struct {
+	Name         string        `json:"name"`
+	Query        string        `json:"query,omitempty"`
+	Backlinks    []BacklinkHit `json:"backlinks"`
+	ResolvedFrom string        `json:"resolved_from,omitempty" jsonschema:"description=Name as requested when it was resolved to a different memory"`
+}
+
+// LinkOutput is a link found in a memory body
+type LinkOutput struct {
+	Text   string `json:"text"`
+	Target string `json:"target"`
+	Type   string `json:"type" jsonschema:"description=wiki or markdown"`
+}
+
+// MemoryOutput is the structured result of read_memory
+type MemoryOutput struct {
+	Name         string            `json:"name"`
+	Title        string            `json:"title,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	Tags         map[string]any    `json:"tags,omitempty"`
+	Created      string            `json:"created,omitempty" jsonschema:"description=RFC 3339 timestamp"`
+	Modified     string            `json:"modified,omitempty" jsonschema:"description=RFC 3339 timestamp"`
+	Metadata     map[string]any    `json:"metadata,omitempty"`
+	Version      string            `json:"version" jsonschema:"description=Version token to pass as if_match to tools that change the memory"`
+	Body         string            `json:"body"`
+	Content      string            `json:"content" jsonschema:"description=Full document including frontmatter"`
+	Links        []LinkOutput      `json:"links"`
+	Relations    []memory.Relation `json:"relations" jsonschema:"description=Typed relations to and from other memories"`
+	ResolvedFrom string            `json:"resolved_from,omitempty" jsonschema:"description=Name as requested when it was resolved to a different memory"`
+}
+
+// TagChangeOutput is the structured result of change_tag
+type TagChangeOutput struct {
+	Name         string             `json:"name"`
+	Changes      []memory.TagChange `json:"changes"`
+	Tags         map[string]any     `json:"tags" jsonschema:"description=All tags on the memory after the change"`
+	ResolvedFrom string             `json:"resolved_from,omitempty" jsonschema:"description=Name as requested when it was resolved to a different memory"`
+}
+
+// ConflictOutput is the structured error result of a change whose if_match did not match the memory
+type ConflictOutput struct {
+	Error string `json:"error" jsonschema:"enum=conflict"`
+	memory.ConflictError
+}
+
+// MutationOutput is the structured result of update_memory and delete_memory, and the base of create_memory's
+type MutationOutput struct {
+	Name         string `json:"name"`
+	Action       string `json:"action" jsonschema:"enum=created,enum=updated,enum=edited,enum=deleted"`
+	Message      string `json:"message"`
+	ResolvedFrom string `json:"resolved_from,omitempty" jsonschema:"description=Name as requested when it was resolved to a different memory"`
+}
+
+// CreateOutput is the structured result of create_memory
+type CreateOutput struct {
+	MutationOutput
+	NormalizedFrom string                 `json:"normalized_from,omitempty" jsonschema:"description=Name as requested when it was normalized to a different name"`
+	Duplicates     []memory.DuplicatePair `json:"duplicates,omitempty" jsonschema:"description=Existing memories the new memory looks like a near-duplicate of"`
+}
+
+// RenameOutput is the structured result of rename_memory
+type RenameOutput struct {
+	Name            string   `json:"name" jsonschema:"description=Previous name of the memory"`
+	NewName         string   `json:"new_name"`
+	UpdatedMemories []string `json:"updated_memories" jsonschema:"description=Other memories whose links were rewritten"`
+	LinksRewritten  int      `json:"links_rewritten"`
+	Redirect        bool     `json:"redirect" jsonschema:"description=Whether a redirect stub was left at the old name"`
+	Message         string   `json:"message"`
+	ResolvedFrom    string   `json:"resolved_from,omitempty" jsonschema:"description=Name as requested when it was resolved to a different memory"`
+}
+
+// HistoryOutput is the structured result of memory_history
+type HistoryOutput struct {
+	Name         string            `json:"name"`
+	Exists       bool              `json:"exists" jsonschema:"description=Whether the memory still exists; deleted memories can be restored"`
+	Revisions    []memory.Revision `json:"revisions" jsonschema:"description=Revisions, newest first"`
+	ResolvedFrom string            `json:"resolved_from,omitempty" jsonschema:"description=Name as requested when it was resolved to a different memory"`
+}
+
+// DiffOutput is the structured result of diff_memory
+type DiffOutput struct {
+	Name    string `json:"name"`
+	From    string `json:"from" jsonschema:"description=Older version compared, e.g. 'revision 3'"`
+	To      string `json:"to" jsonschema:"description=Newer version compared, e.g. 'current'"`
+	Diff    string `json:"diff" jsonschema:"description=Unified diff; empty if the versions are identical"`
+	Changed bool   `json:"changed"`
+}
+
+// RestoreOutput is the structured result of restore_memory
+type RestoreOutput struct {
+	Name         string `json:"name"`
+	Revision     int    `json:"revision,omitempty" jsonschema:"description=Revision that was restored; absent when restored from the trash"`
+	TrashID      string `json:"trash_id,omitempty" jsonschema:"description=Trash entry that was restored"`
+	Message      string `json:"message"`
+	ResolvedFrom string `json:"resolved_from,omitempty" jsonschema:"description=Name as requested when it was resolved to a different memory"`
+}
+
+// TrashOutput is the structured result of list_trash
+type TrashOutput struct {
+	Entries       []memory.TrashEntry `json:"entries" jsonschema:"description=Trashed memories, most recently deleted first"`
+	AutoPurgeDays int                 `json:"auto_purge_days" jsonschema:"description=Days after which trashed memories are purged; 0 if they are kept until purge_trash"`
+}
+
+// PurgeTrashOutput is the structured result of purge_trash
+type PurgeTrashOutput struct {
+	Purged  []memory.TrashEntry `json:"purged"`
+	Message string              `json:"message"`
+}
+
+// GitHistoryOutput is the structured result of git_history
+type GitHistoryOutput struct {
+	Name         string             `json:"name,omitempty" jsonschema:"description=Memory whose commits are listed; empty for all memories"`
+	Commits      []memory.GitCommit `json:"commits" jsonschema:"description=Commits, newest first"`
+	ResolvedFrom string             `json:"resolved_from,omitempty" jsonschema:"description=Name as requested when it was resolved to a different memory"`
+}
+
+// DuplicatesOutput is the structured result of find_duplicates
+type DuplicatesOutput struct {
+	Pairs []memory.DuplicatePair `json:"pairs"`
+}
+
+// NamespacesOutput is the structured result of list_namespaces
+type NamespacesOutput struct {
+	Namespaces []memory.NamespaceInfo `json:"namespaces"`
+}
+
+// MergeOutput is the structured result of merge_memories
+type MergeOutput struct {
+	Source          string               `json:"source" jsonschema:"description=Memory that was merged and removed"`
+	Target          string               `json:"target" jsonschema:"description=Memory the source was merged into"`
+	TagConflicts    []memory.TagConflict `json:"tag_conflicts" jsonschema:"description=Tags both memories carried with different values; the target's value was kept"`
+	UpdatedMemories []string             `json:"updated_memories" jsonschema:"description=Other memories whose links were redirected to the target"`
+	LinksRewritten  int                  `json:"links_rewritten"`
+	ArchivedTo      string               `json:"archived_to,omitempty" jsonschema:"description=Archive path of the source relative to the memory directory (archive=true only)"`
+	TrashID         string               `json:"trash_id,omitempty" jsonschema:"description=Trash ID of the deleted source for restore_memory (archive=false with the trash enabled)"`
+	Message         string               `json:"message"`
+}
+
+// RelationsOutput is the structured result of link_memories and unlink_memories
+type RelationsOutput struct {
+	Name         string            `json:"name"`
+	Relation     string            `json:"relation,omitempty"`
+	Changed      []string          `json:"changed" jsonschema:"description=Targets that were linked or unlinked"`
+	Relations    []memory.Relation `json:"relations" jsonschema:"description=Typed relations of the memory after the change"`
+	Message      string            `json:"message"`
+	ResolvedFrom string            `json:"resolved_from,omitempty" jsonschema:"description=Name as requested when it was resolved to a different memory"`
+}
+
+// ClusterOutput is the structured result of cluster_memories
+type ClusterOutput struct {
+	memory.ClusterReport
+	Applied []string `json:"applied" jsonschema:"description=Memories whose suggested tags were set (apply=true only)"`
+}
+
+// ReconcileOutput is the structured result of reconcile_memories
+type ReconcileOutput struct {
+	memory.ReconcileReport
+}
+
+// QueryCacheOutput is the structured result of query_cache_stats
+type QueryCacheOutput struct {
+	Enabled bool `json:"enabled" jsonschema:"description=Whether the query embedding cache is enabled ([query_cache] size > 0)"`
+	embeddings.CacheStats
+	HitRate float64 `json:"hit_rate" jsonschema:"description=Fraction of query lookups served from the cache"`
+}
+
+// LinkReportOutput is the structured result of check_links
+type LinkReportOutput struct {
+	Dangling []memory.DanglingLink `json:"dangling"`
+	Orphans  []string              `json:"orphans,omitempty" jsonschema:"description=Memories with no links to or from other memories"`
+}
+
+// GraphNodeOutput is a memory in a graph query result
+type GraphNodeOutput struct {
+	Name  string            `json:"name"`
+	Title string            `json:"title,omitempty"`
+	Tags  map[string]string `json:"tags,omitempty"`
+	Depth int               `json:"depth" jsonschema:"description=Number of hops from the start memory"`
+}
+
+// GraphEdgeOutput is an edge in a graph query result
+type GraphEdgeOutput struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Type   string  `json:"type" jsonschema:"description=wiki, markdown, frontmatter or semantic"`
+	Weight float32 `json:"weight" jsonschema:"description=1 for explicit links, similarity for semantic edges"`
+}
+
+// GraphQueryOutput is the structured result of graph_query
+type GraphQueryOutput struct {
+	Start     string            `json:"start"`
+	Target    string            `json:"target,omitempty"`
+	Nodes     []GraphNodeOutput `json:"nodes"`
+	Edges     []GraphEdgeOutput `json:"edges"`
+	Path      []string          `json:"path,omitempty" jsonschema:"description=Memory names along the shortest path (path queries only)"`
+	Truncated bool              `json:"truncated,omitempty" jsonschema:"description=True if the node limit cut the traversal short"`
+}
+
+// ExportGraphOutput is the structured result of export_graph
+type ExportGraphOutput struct {
+	GraphFormat string `json:"graph_format" jsonschema:"enum=dot,enum=graphml,enum=mermaid,enum=json"`
+	Nodes       int    `json:"nodes" jsonschema:"description=Number of exported memories"`
+	Edges       int    `json:"edges" jsonschema:"description=Number of exported edges"`
+	Graph       string `json:"graph" jsonschema:"description=The rendered graph"`
+}
+
+// newMemoryHit converts a memory and its similarity to a structured search hit
+func newMemoryHit(info memory.MemoryInfo, similarity float32) MemoryHit {
+	return MemoryHit{
+		Name:        info.Name,
+		Title:       info.Frontmatter.Title,
+		Description: info.Frontmatter.Description,
+		Tags:        info.Frontmatter.Tags,
+		Snippet:     memory.Snippet(info.Body, 300),
+		Similarity:  similarity,
+	}
+}
+
+// newGrepHits converts text search matches to structured hits
+func newGrepHits(matches []memory.SearchMatch) []GrepHit {
+	hits := make([]GrepHit, 0, len(matches))
+	for _, match := range matches {
+		hits = append(hits, GrepHit{
+			Name:   match.Name,
+			Line:   match.Line,
+			Text:   match.Text,
+			Before: match.Before,
+			After:  match.After,
+		})
+	}
+	return hits
+}
+
+// newMemoryHits converts ranked memories to structured search hits
+func newMemoryHits(memories []memory.MemoryInfo, similarities []float32) []MemoryHit {
+	hits := make([]MemoryHit, 0, len(memories))
+	for i, info := range memories {
+		hits = append(hits, newMemoryHit(info, similarities[i]))
+	}
+	return hits
+}
+
+// newFusedMemoryHits converts fused multi-query results to structured search hits
+func newFusedMemoryHits(results []memory.FusedMemoryInfo) []MemoryHit {
+	hits := make([]MemoryHit, 0, len(results))
+	for _, result := range results {
+		// Report the best similarity across the queries that matched
+		var best float32
+		for _, similarity := range result.Similarities {
+			if similarity > best {
+				best = similarity
+			}
+		}
+
+		hit := newMemoryHit(result.MemoryInfo, best)
+		hit.FusionScore = result.Score
+		hit.MatchedQueries = result.MatchedQueries
+		hits = append(hits, hit)
+	}
+	return hits
+}
+
+// newBacklinkHits converts backlink results to structured hits
+func newBacklinkHits(backlinks []rag.BacklinkResult) []BacklinkHit {
+	hits := make([]BacklinkHit, 0, len(backlinks))
+	for _, backlink := range backlinks {
+		hits = append(hits, BacklinkHit{
+			Name:        backlink.Memory.Name,
+			Title:       backlink.Memory.Title,
+			Description: backlink.Memory.Description,
+			Snippet:     backlink.Snippet,
+			LinkType:    backlink.LinkType,
+			SourceType:  backlink.SourceType,
+			Relevance:   backlink.RelevanceScore,
+		})
+	}
+	return hits
+}
+
+// newMemoryOutput converts a memory read from the store to its structured form
+func newMemoryOutput(info *memory.MemoryInfo, links []memory.Link) MemoryOutput {
+	output := MemoryOutput{
+		Name:    info.Name,
+		Body:    info.Body,
+		Content: info.Content,
+		Version: memory.ContentVersion(info.Content),
+		Links:   make([]LinkOutput, 0, len(links)),
+	}
+
+	if fm := info.Frontmatter; fm != nil {
+		output.Title = fm.Title
+		output.Description = fm.Description
+		output.Tags = fm.Tags
+		output.Metadata = fm.Metadata
+		output.Created = formatTimestamp(fm.Created)
+		output.Modified = formatTimestamp(fm.Modified)
+	}
+
+	for _, link := range links {
+		output.Links = append(output.Links, LinkOutput{
+			Text:   link.Text,
+			Target: link.Target,
+			Type:   link.Type,
+		})
+	}
+
+	return output
+}
+
+// formatTimestamp renders a timestamp as RFC 3339, or an empty string when unset
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// newGraphEdges converts graph edges to structured output
+func newGraphEdges(edges []db.GraphEdge) []GraphEdgeOutput {
+	result := make([]GraphEdgeOutput, 0, len(edges))
+	for _, edge := range edges {
+		result = append(result, GraphEdgeOutput{From: edge.From, To: edge.To, Type: edge.Type, Weight: edge.Weight})
+	}
+	return result
+}
+
+// newNeighborhoodOutput converts a neighborhood traversal to structured output
+func newNeighborhoodOutput(start string, neighborhood *memory.Neighborhood) GraphQueryOutput {
+	output := GraphQueryOutput{
+		Start:     start,
+		Nodes:     make([]GraphNodeOutput, 0, len(neighborhood.Nodes)),
+		Edges:     newGraphEdges(neighborhood.Edges),
+		Truncated: neighborhood.Truncated,
+	}
+	for _, node := range neighborhood.Nodes {
+		output.Nodes = append(output.Nodes, GraphNodeOutput{Name: node.Name, Title: node.Title, Tags: node.Tags, Depth: node.Depth})
+	}
+	return output
+}
+
+// newPathOutput converts a shortest path to structured output; path may be nil
+func newPathOutput(start, target string, graph *memory.Graph, path *memory.GraphPath) GraphQueryOutput {
+	output := GraphQueryOutput{
+		Start:  start,
+		Target: target,
+		Nodes:  []GraphNodeOutput{},
+		Edges:  []GraphEdgeOutput{},
+	}
+	if path == nil {
+		return output
+	}
+
+	output.Path = path.Nodes
+	output.Edges = newGraphEdges(path.Edges)
+	for i, name := range path.Nodes {
+		node := graph.Nodes[name]
+		output.Nodes = append(output.Nodes, GraphNodeOutput{Name: node.Name, Title: node.Title, Tags: node.Tags, Depth: i})
+	}
+	return output
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jcdickinson/simplemem/internal/memory"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func newFormatRequest(format string) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	if format != "" {
+		request.Params.Arguments = map[string]any{"format": format}
+	}
+	return request
+}
+
+func TestNewFormattedResult(t *testing.T) {
+	structured := MutationOutput{Name: "my-memory", Action: "created", Message: "done"}
+
+	tests := []struct {
+		name      string
+		format    string
+		wantTexts int
+		wantJSON  bool
+	}{
+		{name: "default is markdown", format: "", wantTexts: 1, wantJSON: false},
+		{name: "markdown", format: "markdown", wantTexts: 1, wantJSON: false},
+		{name: "json", format: "json", wantTexts: 1, wantJSON: true},
+		{name: "both", format: "both", wantTexts: 2, wantJSON: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := newFormattedResult(newFormatRequest(tt.format), "# Markdown", structured)
+			if err != nil {
+				t.Fatalf("newFormattedResult() error = %v", err)
+			}
+
+			if result.StructuredContent == nil {
+				t.Errorf("expected structured content to always be set")
+			}
+			if len(result.Content) != tt.wantTexts {
+				t.Fatalf("expected %d text blocks, got %d", tt.wantTexts, len(result.Content))
+			}
+
+			first := result.Content[0].(mcp.TextContent).Text
+			last := result.Content[len(result.Content)-1].(mcp.TextContent).Text
+			if tt.format != "json" && first != "# Markdown" {
+				t.Errorf("expected markdown first, got %q", first)
+			}
+
+			var decoded MutationOutput
+			err = json.Unmarshal([]byte(last), &decoded)
+			if tt.wantJSON && (err != nil || decoded != structured) {
+				t.Errorf("expected JSON text %+v, got %q (err: %v)", structured, last, err)
+			}
+			if !tt.wantJSON && err == nil {
+				t.Errorf("expected no JSON text, got %q", last)
+			}
+		})
+	}
+}
+
+func TestNewFormattedResultUnknownFormat(t *testing.T) {
+	if _, err := newFormattedResult(newFormatRequest("xml"), "", MutationOutput{}); err == nil {
+		t.Errorf("expected error for unknown format")
+	}
+}
+
+func TestNewConflictResult(t *testing.T) {
+	conflict := &memory.ConflictError{Name: "notes", Expected: "0123456789ab", Current: "fedcba9876543210", Diff: "-old\n+new\n"}
+
+	result, err := newConflictResult(newFormatRequest("json"), conflict)
+	if err != nil {
+		t.Fatalf("newConflictResult() error = %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("expected the conflict to be an error result")
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &decoded); err != nil {
+		t.Fatalf("expected JSON text: %v", err)
+	}
+	if decoded["error"] != "conflict" || decoded["current_version"] != conflict.Current || decoded["diff"] != conflict.Diff {
+		t.Errorf("unexpected conflict JSON %v", decoded)
+	}
+}
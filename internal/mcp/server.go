@@ -3,8 +3,11 @@ package mcp
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
+	"log"
 	"strings"
+	"time"
 
 	"github.com/gomarkdown/markdown"
 	"github.com/gomarkdown/markdown/ast"
@@ -49,6 +52,11 @@ func NewServer(dbPath string) (*Server, error) {
 		return nil, fmt.Errorf("failed to initialize enhanced store: %w", err)
 	}
 
+	// Keep the index in sync with edits made in an editor while the server runs
+	if err := s.enhancedStore.Watch(cfg.Watch); err != nil {
+		log.Printf("Warning: failed to watch memories for external changes: %v", err)
+	}
+
 	actualInitialInstructions := initialInstructions
 
 	if cfg.MaxMemoryLength > 0 {
@@ -77,17 +85,19 @@ func (s *Server) registerTools(mcpServer *server.MCPServer) {
 		mcp.NewTool("create_memory",
 			mcp.WithDescription("Create a new memory document. Requires metadata object for title, description, and tags. Content length is limited to 2000 characters."),
 			mcp.WithString("name",
-				mcp.Description("Name of the memory (without .md extension)"),
+				mcp.Description("Name of the memory (without .md extension). Use path-style names such as 'project/db/schema' to file it under a namespace. Names are normalized to slugs (by default lowercase, with spaces and punctuation turned into '-'), and absolute paths, '..' segments and names differing from existing ones only by case are rejected"),
 				mcp.Required(),
 			),
 			mcp.WithObject("metadata",
-				mcp.Description("Metadata object with required title, description, and tags fields, plus any additional properties"),
+				mcp.Description("Metadata object with required title, description, and tags fields, plus any additional properties. Optional 'relations' maps relation types (e.g. depends_on, blocks) to arrays of memory names"),
 				mcp.Required(),
 			),
 			mcp.WithString("content",
 				mcp.Description("Content of the memory in markdown format"),
 				mcp.Required(),
 			),
+			withFormat(),
+			mcp.WithOutputSchema[CreateOutput](),
 		),
 		s.handleCreateMemory,
 	)
@@ -97,29 +107,114 @@ func (s *Server) registerTools(mcpServer *server.MCPServer) {
 		mcp.NewTool("update_memory",
 			mcp.WithDescription("Update an existing memory document. Requires metadata object for title, description, and tags. Timestamps (created/modified) are automatically managed by the server. Content length is limited to 2000 characters."),
 			mcp.WithString("name",
-				mcp.Description("Name of the memory to update"),
+				mcp.Description("Name of the memory to update. Falls back to a unique case-insensitive or title match; otherwise the error suggests close names"),
 				mcp.Required(),
 			),
 			mcp.WithObject("metadata",
-				mcp.Description("Metadata object with required title, description, and tags fields, plus any additional properties"),
+				mcp.Description("Metadata object with required title, description, and tags fields, plus any additional properties. Optional 'relations' maps relation types (e.g. depends_on, blocks) to arrays of memory names"),
 				mcp.Required(),
 			),
 			mcp.WithString("content",
 				mcp.Description("New content for the memory"),
 				mcp.Required(),
 			),
+			withIfMatch(),
+			withFormat(),
+			mcp.WithOutputSchema[MutationOutput](),
 		),
 		s.handleUpdateMemory,
 	)
 
+	// Edit Memory tool
+	operations := make([]string, len(memory.EditOperations))
+	for i, operation := range memory.EditOperations {
+		operations[i] = string(operation)
+	}
+	mcpServer.AddTool(
+		mcp.NewTool("edit_memory",
+			mcp.WithDescription("Edit part of a memory without resending it. append and prepend add content at the end or start; replace_section replaces everything below a heading up to the next heading of the same or a higher level, keeping the heading; insert_after_heading adds content right below a heading; str_replace replaces old_string, which must occur exactly once. The frontmatter is kept and the same length limit as update_memory applies."),
+			mcp.WithString("name",
+				mcp.Description("Name of the memory to edit. Falls back to a unique case-insensitive or title match; otherwise the error suggests close names"),
+				mcp.Required(),
+			),
+			mcp.WithString("operation",
+				mcp.Description("Edit operation"),
+				mcp.Required(),
+				mcp.Enum(operations...),
+			),
+			mcp.WithString("content",
+				mcp.Description("Content to add, the new section content, or the replacement for old_string. May be empty to remove a section or string"),
+			),
+			mcp.WithString("heading",
+				mcp.Description("Heading of the section for replace_section and insert_after_heading, with or without leading #s; case-insensitive"),
+			),
+			mcp.WithString("old_string",
+				mcp.Description("Exact text to replace for str_replace"),
+			),
+			withIfMatch(),
+			withFormat(),
+			mcp.WithOutputSchema[MutationOutput](),
+		),
+		s.handleEditMemory,
+	)
+
+	// Link Memories tool
+	mcpServer.AddTool(
+		mcp.NewTool("link_memories",
+			mcp.WithDescription("Add a typed relation from a memory to one or more other memories, stored in the memory's frontmatter. Use it for dependencies between TODOs (depends_on, blocks), replacements (supersedes), implementations and related reading (see_also). Incoming relations are reported under their inverse name, e.g. blocks appears as blocked_by on the target."),
+			mcp.WithString("name",
+				mcp.Description("Memory declaring the relation. Falls back to a unique case-insensitive or title match; otherwise the error suggests close names"),
+				mcp.Required(),
+			),
+			mcp.WithString("relation",
+				mcp.Description("Relation type"),
+				mcp.Required(),
+				mcp.Enum(s.enhancedStore.RelationTypes()...),
+			),
+			mcp.WithArray("targets",
+				mcp.Description("Memories the relation points to"),
+				mcp.Required(),
+				mcp.WithStringItems(),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[RelationsOutput](),
+		),
+		s.handleLinkMemories,
+	)
+
+	// Unlink Memories tool
+	mcpServer.AddTool(
+		mcp.NewTool("unlink_memories",
+			mcp.WithDescription("Remove typed relations from a memory to one or more other memories"),
+			mcp.WithString("name",
+				mcp.Description("Memory declaring the relation. Falls back to a unique case-insensitive or title match; otherwise the error suggests close names"),
+				mcp.Required(),
+			),
+			mcp.WithString("relation",
+				mcp.Description("Relation type to remove (default: every relation to the targets)"),
+				mcp.Enum(s.enhancedStore.RelationTypes()...),
+			),
+			mcp.WithArray("targets",
+				mcp.Description("Memories to unlink"),
+				mcp.Required(),
+				mcp.WithStringItems(),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[RelationsOutput](),
+		),
+		s.handleUnlinkMemories,
+	)
+
 	// Read Memory tool
 	mcpServer.AddTool(
 		mcp.NewTool("read_memory",
 			mcp.WithDescription("Read a memory document with full metadata including tags, timestamps, and links"),
 			mcp.WithString("name",
-				mcp.Description("Name of the memory to read"),
+				mcp.Description("Name of the memory to read. Falls back to a unique case-insensitive or title match; otherwise the error suggests close names"),
 				mcp.Required(),
 			),
+			withFormat(),
+			mcp.WithOutputSchema[MemoryOutput](),
 		),
 		s.handleReadMemory,
 	)
@@ -127,15 +222,161 @@ func (s *Server) registerTools(mcpServer *server.MCPServer) {
 	// Delete Memory tool
 	mcpServer.AddTool(
 		mcp.NewTool("delete_memory",
-			mcp.WithDescription("Delete a memory document"),
+			mcp.WithDescription("Delete a memory document. Unless the trash is disabled, the memory is moved to the trash, from which restore_memory can bring it back until it is purged."),
 			mcp.WithString("name",
-				mcp.Description("Name of the memory to delete"),
+				mcp.Description("Name of the memory to delete. Falls back to a unique case-insensitive or title match; otherwise the error suggests close names"),
 				mcp.Required(),
 			),
+			withIfMatch(),
+			withFormat(),
+			mcp.WithOutputSchema[MutationOutput](),
 		),
 		s.handleDeleteMemory,
 	)
 
+	// Rename Memory tool
+	mcpServer.AddTool(
+		mcp.NewTool("rename_memory",
+			mcp.WithDescription("Rename a memory document, keeping its embeddings and backlinks and rewriting wiki, markdown and frontmatter links and relations to it in other memories"),
+			mcp.WithString("name",
+				mcp.Description("Current name of the memory. Falls back to a unique case-insensitive or title match; otherwise the error suggests close names"),
+				mcp.Required(),
+			),
+			mcp.WithString("new_name",
+				mcp.Description("New name for the memory (without .md extension), normalized and validated like create_memory names"),
+				mcp.Required(),
+			),
+			mcp.WithBoolean("redirect",
+				mcp.Description("Leave a stub at the old name that links to the new one (default: false)"),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[RenameOutput](),
+		),
+		s.handleRenameMemory,
+	)
+
+	// Merge Memories tool
+	mcpServer.AddTool(
+		mcp.NewTool("merge_memories",
+			mcp.WithDescription("Merge a duplicate memory into another. The source body is appended to the target's, tags are unioned (the target's value wins and conflicts are reported), links and relations are combined, links to the source are redirected to the target across the store, and the source is deleted or archived."),
+			mcp.WithString("source",
+				mcp.Description("Memory to merge and remove. Falls back to a unique case-insensitive or title match; otherwise the error suggests close names"),
+				mcp.Required(),
+			),
+			mcp.WithString("target",
+				mcp.Description("Memory to merge into. Falls back to a unique case-insensitive or title match; otherwise the error suggests close names"),
+				mcp.Required(),
+			),
+			mcp.WithBoolean("archive",
+				mcp.Description("Move the source into the .archive directory instead of deleting it; deleted sources go to the trash when it is enabled (default: false)"),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[MergeOutput](),
+		),
+		s.handleMergeMemories,
+	)
+
+	// Memory History tool
+	mcpServer.AddTool(
+		mcp.NewTool("memory_history",
+			mcp.WithDescription("List the stored revisions of a memory, newest first, with when and how each came about. Works for deleted memories too. Use diff_memory to compare revisions and restore_memory to bring one back."),
+			mcp.WithString("name",
+				mcp.Description("Name of the memory. Falls back to a unique case-insensitive or title match; otherwise the error suggests close names"),
+				mcp.Required(),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[HistoryOutput](),
+		),
+		s.handleMemoryHistory,
+	)
+
+	// Diff Memory tool
+	mcpServer.AddTool(
+		mcp.NewTool("diff_memory",
+			mcp.WithDescription("Show a unified diff between two revisions of a memory. By default compares the current content with the revision before it."),
+			mcp.WithString("name",
+				mcp.Description("Name of the memory. Falls back to a unique case-insensitive or title match; otherwise the error suggests close names"),
+				mcp.Required(),
+			),
+			mcp.WithNumber("from",
+				mcp.Description("Older revision number (default: the newest revision before 'to' that differs from it)"),
+			),
+			mcp.WithNumber("to",
+				mcp.Description("Newer revision number (default: the current content)"),
+			),
+			mcp.WithNumber("context",
+				mcp.Description("Number of unchanged lines shown around each change (default: 3)"),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[DiffOutput](),
+		),
+		s.handleDiffMemory,
+	)
+
+	// Restore Memory tool
+	mcpServer.AddTool(
+		mcp.NewTool("restore_memory",
+			mcp.WithDescription("Restore a memory to an earlier revision from memory_history, recreating it if it was deleted, or, without a revision, bring back a deleted memory from the trash. The current content stays in the history, so a restore can itself be undone."),
+			mcp.WithString("name",
+				mcp.Description("Name of the memory. Falls back to a unique case-insensitive or title match, among the deleted memories when restoring from the trash; otherwise the error suggests close names"),
+				mcp.Required(),
+			),
+			mcp.WithNumber("revision",
+				mcp.Description("Revision number to restore (default: restore the memory from the trash)"),
+			),
+			mcp.WithString("trash_id",
+				mcp.Description("ID from list_trash of the copy to restore when the memory was deleted more than once (default: the most recently deleted copy)"),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[RestoreOutput](),
+		),
+		s.handleRestoreMemory,
+	)
+
+	// List Trash tool
+	mcpServer.AddTool(
+		mcp.NewTool("list_trash",
+			mcp.WithDescription("List deleted memories in the trash, most recently deleted first, with when each will be purged. Use restore_memory to bring one back."),
+			withFormat(),
+			mcp.WithOutputSchema[TrashOutput](),
+		),
+		s.handleListTrash,
+	)
+
+	// Purge Trash tool
+	mcpServer.AddTool(
+		mcp.NewTool("purge_trash",
+			mcp.WithDescription("Permanently remove memories from the trash, together with their saved embeddings. They can no longer be restored from the trash, though memory_history still has their revisions."),
+			mcp.WithString("name",
+				mcp.Description("Only purge trashed copies of this memory (default: all memories)"),
+			),
+			mcp.WithNumber("older_than_days",
+				mcp.Description("Only purge memories deleted at least this many days ago (default: regardless of age)"),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[PurgeTrashOutput](),
+		),
+		s.handlePurgeTrash,
+	)
+
+	// Git History tool, only offered when changes are committed to git
+	if s.enhancedStore.GitEnabled() {
+		mcpServer.AddTool(
+			mcp.NewTool("git_history",
+				mcp.WithDescription("List the git commits that changed a memory, or all memories, newest first. Each commit names the tools and memories involved; batched commits list every change."),
+				mcp.WithString("name",
+					mcp.Description("Optional name of the memory; follows renames and works for deleted memories (default: all memories)"),
+				),
+				mcp.WithNumber("limit",
+					mcp.Description("Maximum number of commits to return (default: 20)"),
+				),
+				withFormat(),
+				mcp.WithOutputSchema[GitHistoryOutput](),
+			),
+			s.handleGitHistory,
+		)
+	}
+
 	// List Memories tool - temporarily removed to encourage semantic search usage
 	// mcpServer.AddTool(
 	// 	mcp.NewTool("list_memories",
@@ -147,10 +388,13 @@ func (s *Server) registerTools(mcpServer *server.MCPServer) {
 	// Search Memories tool
 	mcpServer.AddTool(
 		mcp.NewTool("search_memories",
-			mcp.WithDescription("Semantically search memories using natural language queries with optional tag filtering. Returns ranked results with snippets and relevance scores. Tags can filter by presence (empty value) or specific values."),
+			mcp.WithDescription("Semantically search memories using natural language queries with optional tag filtering. Returns ranked results with snippets and relevance scores. Tags can filter by presence (empty value) or specific values. Pass several phrasings in 'queries' to search them in one call; results are merged with reciprocal rank fusion and annotated with the queries that matched."),
 			mcp.WithString("query",
-				mcp.Description("Semantic search query to find related memories"),
-				mcp.Required(),
+				mcp.Description("Semantic search query to find related memories (either query or queries is required)"),
+			),
+			mcp.WithArray("queries",
+				mcp.Description("Optional list of queries to search together, e.g. [\"duckdb schema\", \"migrations\", \"table creation\"]"),
+				mcp.WithStringItems(),
 			),
 			mcp.WithObject("tags",
 				mcp.Description("Optional tag filters - key:value pairs. Use empty string as value to check for tag presence only"),
@@ -158,37 +402,295 @@ func (s *Server) registerTools(mcpServer *server.MCPServer) {
 			mcp.WithBoolean("require_all",
 				mcp.Description("If true, memory must have ALL specified tags. If false, memory needs ANY of the tags (default: false)"),
 			),
+			mcp.WithString("namespace",
+				mcp.Description("Optional namespace to search within, including its sub-namespaces, e.g. 'project/db'"),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[SearchOutput](),
 		),
 		s.handleSearchMemories,
 	)
 
+	// Grep Memories tool
+	mcpServer.AddTool(
+		mcp.NewTool("grep_memories",
+			mcp.WithDescription("Find every line in memory bodies that matches a pattern, with line numbers and surrounding context. Use this instead of search_memories when you need exact text such as an identifier or type name (e.g. FLOAT[1024]). Results are ordered by memory name and paged."),
+			mcp.WithString("pattern",
+				mcp.Description("Text or regular expression to search for"),
+				mcp.Required(),
+			),
+			mcp.WithString("mode",
+				mcp.Description("'literal' for an exact case-sensitive match, 'ignore_case' for a case-insensitive match, 'regex' for an RE2 regular expression (default: literal)"),
+				mcp.Enum(memory.SearchModeLiteral, memory.SearchModeIgnoreCase, memory.SearchModeRegex),
+			),
+			mcp.WithObject("tags",
+				mcp.Description("Optional tag filters - key:value pairs. Use empty string as value to check for tag presence only"),
+			),
+			mcp.WithBoolean("require_all",
+				mcp.Description("If true, memory must have ALL specified tags. If false, memory needs ANY of the tags (default: false)"),
+			),
+			mcp.WithString("name_glob",
+				mcp.Description("Optional glob to restrict which memories are searched, e.g. 'project-*'"),
+			),
+			mcp.WithString("namespace",
+				mcp.Description("Optional namespace to search within, including its sub-namespaces, e.g. 'project/db'"),
+			),
+			mcp.WithNumber("context",
+				mcp.Description("Number of context lines before and after each match (default: 2, max: 10)"),
+			),
+			mcp.WithNumber("offset",
+				mcp.Description("Index of the first match to return, for paging (default: 0)"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Maximum number of matches to return (default: 50)"),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[GrepOutput](),
+		),
+		s.handleGrepMemories,
+	)
+
 	// Get Backlinks tool
 	mcpServer.AddTool(
 		mcp.NewTool("get_backlinks",
 			mcp.WithDescription("Get memories related to a specific memory through explicit links and semantic similarity. Optionally rerank by query relevance."),
 			mcp.WithString("name",
-				mcp.Description("Name of the memory to find backlinks for"),
+				mcp.Description("Name of the memory to find backlinks for. Falls back to a unique case-insensitive or title match; otherwise the error suggests close names"),
 				mcp.Required(),
 			),
 			mcp.WithString("query",
 				mcp.Description("Optional query to rerank backlinks by relevance"),
 			),
+			withFormat(),
+			mcp.WithOutputSchema[BacklinksOutput](),
 		),
 		s.handleGetBacklinks,
 	)
 
+	// Find Similar tool
+	mcpServer.AddTool(
+		mcp.NewTool("find_similar",
+			mcp.WithDescription("Find memories similar to an existing memory (\"more like this\") using its stored chunk embeddings. Makes no embedding API calls. Supports tag filtering and excluding specific memories."),
+			mcp.WithString("name",
+				mcp.Description("Name of the memory to find similar memories for. Falls back to a unique case-insensitive or title match; otherwise the error suggests close names"),
+				mcp.Required(),
+			),
+			mcp.WithString("mode",
+				mcp.Description("How to compare chunks: 'centroid' uses the mean of the memory's chunk vectors, 'max' scores candidates by their best chunk match (default: centroid)"),
+				mcp.Enum("centroid", "max"),
+			),
+			mcp.WithObject("tags",
+				mcp.Description("Optional tag filters - key:value pairs. Use empty string as value to check for tag presence only"),
+			),
+			mcp.WithBoolean("require_all",
+				mcp.Description("If true, memory must have ALL specified tags. If false, memory needs ANY of the tags (default: false)"),
+			),
+			mcp.WithString("namespace",
+				mcp.Description("Optional namespace to restrict results to, including its sub-namespaces"),
+			),
+			mcp.WithArray("exclude",
+				mcp.Description("Optional list of memory names to leave out of the results"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Maximum number of results (default: 5)"),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[SimilarOutput](),
+		),
+		s.handleFindSimilar,
+	)
+
+	// Check Links tool
+	mcpServer.AddTool(
+		mcp.NewTool("check_links",
+			mcp.WithDescription("Report broken [[wiki]] and markdown links whose target memory does not exist, with the memories containing them and a suggested fix. Also lists orphan memories that have no links to or from other memories."),
+			mcp.WithBoolean("include_orphans",
+				mcp.Description("Whether to list orphan memories (default: true)"),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[LinkReportOutput](),
+		),
+		s.handleCheckLinks,
+	)
+
+	// Reconcile Memories tool
+	mcpServer.AddTool(
+		mcp.NewTool("reconcile_memories",
+			mcp.WithDescription("Remove search index entries of memories whose files were deleted outside simplemem, so searches stop returning memories that read_memory cannot find. Lists the memories removed."),
+			mcp.WithBoolean("dry_run",
+				mcp.Description("Only list the memories that would be removed (default: false)"),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[ReconcileOutput](),
+		),
+		s.handleReconcileMemories,
+	)
+
+	// Query Cache Stats tool
+	mcpServer.AddTool(
+		mcp.NewTool("query_cache_stats",
+			mcp.WithDescription("Report how well the query cache is working in this session: hits, misses, evictions, size and hit rate. Repeated searches and get_backlinks reranks served from the cache make no API calls."),
+			withFormat(),
+			mcp.WithOutputSchema[QueryCacheOutput](),
+		),
+		s.handleQueryCacheStats,
+	)
+
+	// Graph Query tool
+	mcpServer.AddTool(
+		mcp.NewTool("graph_query",
+			mcp.WithDescription("Traverse the knowledge graph built from [[wiki]] links, markdown links, frontmatter links, typed relations and semantic similarity. Returns the N-hop neighborhood of a memory, or the shortest path to a target memory when 'target' is given."),
+			mcp.WithString("name",
+				mcp.Description("Memory to start from"),
+				mcp.Required(),
+			),
+			mcp.WithString("target",
+				mcp.Description("Optional memory to find the shortest path to"),
+			),
+			mcp.WithNumber("hops",
+				mcp.Description("Neighborhood radius in edges (default: 2, max: 5); ignored for path queries"),
+			),
+			mcp.WithArray("edge_types",
+				mcp.Description("Edge types to follow (default: all)"),
+				mcp.Items(map[string]any{"type": "string", "enum": s.enhancedStore.EdgeTypes()}),
+			),
+			mcp.WithString("direction",
+				mcp.Description("Direction to follow explicit links; semantic edges are always followed both ways (default: both)"),
+				mcp.Enum(memory.DirectionBoth, memory.DirectionOutbound, memory.DirectionInbound),
+			),
+			mcp.WithObject("tags",
+				mcp.Description("Optional tag filters for memories along the way - key:value pairs. Use empty string as value to check for tag presence only"),
+			),
+			mcp.WithBoolean("require_all",
+				mcp.Description("If true, memory must have ALL specified tags. If false, memory needs ANY of the tags (default: false)"),
+			),
+			mcp.WithNumber("min_similarity",
+				mcp.Description("Minimum similarity for semantic edges (default: 0)"),
+			),
+			mcp.WithNumber("max_nodes",
+				mcp.Description("Maximum number of memories to return (default: 50)"),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[GraphQueryOutput](),
+		),
+		s.handleGraphQuery,
+	)
+
+	// Export Graph tool
+	mcpServer.AddTool(
+		mcp.NewTool("export_graph",
+			mcp.WithDescription("Export the memory graph for visualization: memories as nodes (name, title, tags) and explicit links plus semantic backlinks as weighted edges. Supports Graphviz DOT, GraphML, Mermaid and JSON node-link output, tag filtering and clustering by a tag key."),
+			mcp.WithString("graph_format",
+				mcp.Description("Output format for the graph (default: mermaid)"),
+				mcp.Enum(memory.ExportFormats...),
+			),
+			mcp.WithObject("tags",
+				mcp.Description("Optional tag filters for exported memories - key:value pairs. Use empty string as value to check for tag presence only"),
+			),
+			mcp.WithBoolean("require_all",
+				mcp.Description("If true, memory must have ALL specified tags. If false, memory needs ANY of the tags (default: false)"),
+			),
+			mcp.WithArray("edge_types",
+				mcp.Description("Edge types to export (default: all)"),
+				mcp.Items(map[string]any{"type": "string", "enum": s.enhancedStore.EdgeTypes()}),
+			),
+			mcp.WithString("cluster_by",
+				mcp.Description("Optional tag key to group memories by, e.g. 'project'"),
+			),
+			mcp.WithNumber("min_similarity",
+				mcp.Description("Minimum similarity for semantic edges (default: 0.5)"),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[ExportGraphOutput](),
+		),
+		s.handleExportGraph,
+	)
+
+	// Cluster Memories tool
+	mcpServer.AddTool(
+		mcp.NewTool("cluster_memories",
+			mcp.WithDescription("Group memories into topics by k-means over their embeddings. Each cluster is described by TF-IDF keywords, and members missing the cluster's common tags get tag suggestions, which can be applied like change_tag. Use it to find unlabeled piles and make tagging consistent."),
+			mcp.WithNumber("k",
+				mcp.Description("Number of clusters (default: chosen from the number of memories)"),
+			),
+			mcp.WithObject("tags",
+				mcp.Description("Optional tag filters restricting which memories are clustered - key:value pairs. Use empty string as value to check for tag presence only"),
+			),
+			mcp.WithBoolean("require_all",
+				mcp.Description("If true, memory must have ALL specified tags. If false, memory needs ANY of the tags (default: false)"),
+			),
+			mcp.WithString("namespace",
+				mcp.Description("Optional namespace restricting which memories are clustered, including its sub-namespaces"),
+			),
+			mcp.WithNumber("min_share",
+				mcp.Description("Share of a cluster's members that must carry a tag for it to be suggested to the rest (default: 0.5)"),
+			),
+			mcp.WithNumber("keywords",
+				mcp.Description("Keywords per cluster (default: 5)"),
+			),
+			mcp.WithBoolean("apply",
+				mcp.Description("Set the suggested tags on the memories (default: false, report only)"),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[ClusterOutput](),
+		),
+		s.handleClusterMemories,
+	)
+
+	// Find Duplicates tool
+	mcpServer.AddTool(
+		mcp.NewTool("find_duplicates",
+			mcp.WithDescription("Find near-duplicate memories by embedding similarity and word-shingle overlap. A pair is reported when either score reaches its threshold. Combine duplicates with merge_memories."),
+			mcp.WithNumber("threshold",
+				mcp.Description("Minimum embedding similarity, 0.0 to 1.0 (default: from configuration, 0.92)"),
+			),
+			mcp.WithNumber("text_threshold",
+				mcp.Description("Minimum Jaccard overlap of word shingles, 0.0 to 1.0 (default: from configuration, 0.5)"),
+			),
+			mcp.WithObject("tags",
+				mcp.Description("Optional tag filters restricting which memories are compared - key:value pairs. Use empty string as value to check for tag presence only"),
+			),
+			mcp.WithBoolean("require_all",
+				mcp.Description("If true, memory must have ALL specified tags. If false, memory needs ANY of the tags (default: false)"),
+			),
+			mcp.WithString("namespace",
+				mcp.Description("Optional namespace restricting which memories are compared, including its sub-namespaces"),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[DuplicatesOutput](),
+		),
+		s.handleFindDuplicates,
+	)
+
+	// List Namespaces tool
+	mcpServer.AddTool(
+		mcp.NewTool("list_namespaces",
+			mcp.WithDescription("List the namespaces memories are filed under (the directories of path-style names like 'project/db/schema') with how many memories each holds. Cheap way to see how the store is organized before searching within a namespace."),
+			mcp.WithString("namespace",
+				mcp.Description("Optional namespace to list the sub-namespaces of (default: all namespaces)"),
+			),
+			withFormat(),
+			mcp.WithOutputSchema[NamespacesOutput](),
+		),
+		s.handleListNamespaces,
+	)
+
 	// Change Tag tool
 	mcpServer.AddTool(
 		mcp.NewTool("change_tag",
 			mcp.WithDescription("Change multiple tags on a memory document. Useful for setting TODO states and other metadata. Tags with null values will be removed. Example: {\"todo\": true, \"status\": \"in_progress\", \"priority\": \"high\", \"old_tag\": null}"),
 			mcp.WithString("name",
-				mcp.Description("Name of the memory to modify"),
+				mcp.Description("Name of the memory to modify. Falls back to a unique case-insensitive or title match; otherwise the error suggests close names"),
 				mcp.Required(),
 			),
 			mcp.WithObject("tags",
 				mcp.Description("Object containing tag key-value pairs to set. Use null values to remove tags."),
 				mcp.Required(),
 			),
+			withIfMatch(),
+			withFormat(),
+			mcp.WithOutputSchema[TagChangeOutput](),
 		),
 		s.handleChangeTag,
 	)
@@ -249,6 +751,74 @@ func (s *Server) validateMemoryLength(content string) error {
 	return nil
 }
 
+// parseTagFilters reads the tags and require_all arguments shared by the search and listing
+// tools. Tag values are compared as strings.
+func parseTagFilters(args map[string]any) (map[string]string, bool) {
+	var tags map[string]string
+	if tagsMap, ok := args["tags"].(map[string]interface{}); ok {
+		tags = make(map[string]string)
+		for k, v := range tagsMap {
+			tags[k] = fmt.Sprintf("%v", v)
+		}
+	}
+
+	requireAll, _ := args["require_all"].(bool)
+	return tags, requireAll
+}
+
+// parseTagChanges reads the tags argument of change_tag, where a null value removes a tag
+func parseTagChanges(args map[string]any) (map[string]interface{}, error) {
+	tagsArg, ok := args["tags"]
+	if !ok {
+		return nil, fmt.Errorf("tags parameter is required")
+	}
+
+	tagsMap, ok := tagsArg.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("tags must be an object")
+	}
+
+	if len(tagsMap) == 0 {
+		return nil, fmt.Errorf("at least one tag must be specified")
+	}
+	return tagsMap, nil
+}
+
+// resolveName maps a requested memory name to an existing memory. When the name had to be
+// resolved, the returned note explains how so responses can mention it.
+func (s *Server) resolveName(name string) (string, string, error) {
+	if name == "" {
+		return "", "", fmt.Errorf("memory name is required")
+	}
+
+	resolved, method, err := s.enhancedStore.ResolveName(name)
+	if err != nil {
+		return "", "", err
+	}
+
+	note := ""
+	if method != memory.ResolvedExact {
+		note = fmt.Sprintf("Resolved '%s' to '%s' (%s match)", name, resolved, method)
+	}
+	return resolved, note, nil
+}
+
+// withNote prefixes a response message with a resolution note, if any
+func withNote(note, message string) string {
+	if note == "" {
+		return message
+	}
+	return note + "\n\n" + message
+}
+
+// resolvedFrom returns the requested name when it differs from the resolved one
+func resolvedFrom(requested, note string) string {
+	if note == "" {
+		return ""
+	}
+	return requested
+}
+
 func (s *Server) handleCreateMemory(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	name := request.GetString("name", "")
 	content := request.GetString("content", "")
@@ -270,6 +840,13 @@ func (s *Server) handleCreateMemory(_ context.Context, request mcp.CallToolReque
 		return nil, fmt.Errorf("memory name is required")
 	}
 
+	// New names are normalized to the configured slug form; unsafe names are rejected
+	requested := name
+	name, err := s.enhancedStore.NormalizeName(requested)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate required fields
 	title, titleExists := metadataMap["title"]
 	if !titleExists {
@@ -309,10 +886,16 @@ func (s *Server) handleCreateMemory(_ context.Context, request mcp.CallToolReque
 	// Add any additional metadata properties (excluding the standard ones)
 	for key, value := range metadataMap {
 		if key != "title" && key != "description" && key != "tags" {
-			fm.Metadata[key] = value
+			if err := fm.SetMetadata(key, value); err != nil {
+				return nil, err
+			}
 		}
 	}
 
+	if err := s.enhancedStore.ValidateRelations(fm); err != nil {
+		return nil, err
+	}
+
 	// Create document content with frontmatter
 	finalContent, err := memory.FormatDocument(fm, content)
 	if err != nil {
@@ -327,19 +910,36 @@ func (s *Server) handleCreateMemory(_ context.Context, request mcp.CallToolReque
 	if err := s.enhancedStore.Create(name, finalContent); err != nil {
 		return nil, err
 	}
+	s.enhancedStore.RecordChange("create_memory", name, "")
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: fmt.Sprintf("Memory '%s' created successfully", name),
-			},
-		},
-	}, nil
+	message := fmt.Sprintf("Memory '%s' created successfully", name)
+	output := CreateOutput{MutationOutput: MutationOutput{Name: name, Action: "created"}}
+	if name != strings.TrimSuffix(requested, ".md") {
+		message += fmt.Sprintf(" (name normalized from '%s')", requested)
+		output.NormalizedFrom = requested
+	}
+
+	if s.config.Duplicates.WarnOnCreate {
+		duplicates, err := s.enhancedStore.DuplicatesOf(name, memory.DuplicateOptions{})
+		if err != nil {
+			log.Printf("Warning: failed to check memory %s for duplicates: %v", name, err)
+		} else if len(duplicates) > 0 {
+			message += "\n\n" + memory.FormatDuplicateWarning(duplicates)
+			output.Duplicates = duplicates
+		}
+	}
+
+	output.Message = message
+	return newFormattedResult(request, message, output)
 }
 
 func (s *Server) handleReadMemory(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	name := request.GetString("name", "")
+	requested := request.GetString("name", "")
+
+	name, note, err := s.resolveName(requested)
+	if err != nil {
+		return nil, err
+	}
 
 	memInfo, err := s.store.ReadWithMetadata(name)
 	if err != nil {
@@ -377,6 +977,7 @@ func (s *Server) handleReadMemory(_ context.Context, request mcp.CallToolRequest
 			metaInfo += fmt.Sprintf("\n🔄 **Modified:** %s", memInfo.Frontmatter.Modified.Format("2006-01-02 15:04:05"))
 		}
 	}
+	metaInfo += fmt.Sprintf("\n🔖 **Version:** `%s` (pass as if_match when changing this memory)", memory.ContentVersion(memInfo.Content))
 
 	// Extract links from the content body
 	links := memory.ExtractLinks(memInfo.Body)
@@ -387,18 +988,23 @@ func (s *Server) handleReadMemory(_ context.Context, request mcp.CallToolRequest
 		}
 	}
 
+	relations, err := s.enhancedStore.Relations(name)
+	if err != nil {
+		log.Printf("[READ] Warning: failed to load relations for %s: %v", name, err)
+		relations = []memory.Relation{}
+	}
+	if len(relations) > 0 {
+		metaInfo += "\n\n🧭 **Relations:**\n" + memory.FormatRelationsMarkdown(relations)
+	}
+
 	if metaInfo != "" {
 		response += "\n\n---" + metaInfo
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: response,
-			},
-		},
-	}, nil
+	output := newMemoryOutput(memInfo, links)
+	output.Relations = relations
+	output.ResolvedFrom = resolvedFrom(requested, note)
+	return newFormattedResult(request, withNote(note, response), output)
 }
 
 func (s *Server) handleUpdateMemory(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -461,10 +1067,16 @@ func (s *Server) handleUpdateMemory(_ context.Context, request mcp.CallToolReque
 	// Add any additional metadata properties (excluding the standard ones)
 	for key, value := range metadataMap {
 		if key != "title" && key != "description" && key != "tags" {
-			fm.Metadata[key] = value
+			if err := fm.SetMetadata(key, value); err != nil {
+				return nil, err
+			}
 		}
 	}
 
+	if err := s.enhancedStore.ValidateRelations(fm); err != nil {
+		return nil, err
+	}
+
 	// Create document content with frontmatter
 	finalContent, err := memory.FormatDocument(fm, content)
 	if err != nil {
@@ -476,35 +1088,468 @@ func (s *Server) handleUpdateMemory(_ context.Context, request mcp.CallToolReque
 		return nil, err
 	}
 
-	if err := s.enhancedStore.Update(name, finalContent); err != nil {
+	requested := name
+	name, note, err := s.resolveName(requested)
+	if err != nil {
 		return nil, err
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: fmt.Sprintf("Memory '%s' updated successfully", name),
-			},
-		},
-	}, nil
+	if err := s.enhancedStore.UpdateIfMatch(name, finalContent, request.GetString("if_match", "")); err != nil {
+		var conflict *memory.ConflictError
+		if errors.As(err, &conflict) {
+			return newConflictResult(request, conflict)
+		}
+		return nil, err
+	}
+	s.enhancedStore.RecordChange("update_memory", name, "")
+
+	message := withNote(note, fmt.Sprintf("Memory '%s' updated successfully", name))
+	return newFormattedResult(request, message, MutationOutput{Name: name, Action: "updated", Message: message, ResolvedFrom: resolvedFrom(requested, note)})
+}
+
+func (s *Server) handleEditMemory(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	edit := memory.Edit{
+		Operation: memory.EditOperation(request.GetString("operation", "")),
+		Content:   request.GetString("content", ""),
+		Heading:   request.GetString("heading", ""),
+		Old:       request.GetString("old_string", ""),
+		IfMatch:   request.GetString("if_match", ""),
+	}
+	if edit.Operation == "" {
+		return nil, fmt.Errorf("operation is required")
+	}
+	if (edit.Operation == memory.EditAppend || edit.Operation == memory.EditPrepend) && edit.Content == "" {
+		return nil, fmt.Errorf("content is required for %s", edit.Operation)
+	}
+
+	requested := request.GetString("name", "")
+	name, note, err := s.resolveName(requested)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.enhancedStore.Edit(name, edit, s.validateMemoryLength); err != nil {
+		var conflict *memory.ConflictError
+		if errors.As(err, &conflict) {
+			return newConflictResult(request, conflict)
+		}
+		return nil, err
+	}
+	s.enhancedStore.RecordChange("edit_memory", name, edit.String())
+
+	message := withNote(note, fmt.Sprintf("Memory '%s' edited successfully (%s)", name, edit))
+	return newFormattedResult(request, message, MutationOutput{Name: name, Action: "edited", Message: message, ResolvedFrom: resolvedFrom(requested, note)})
 }
 
 func (s *Server) handleDeleteMemory(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requested := request.GetString("name", "")
+
+	name, note, err := s.resolveName(requested)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.enhancedStore.DeleteIfMatch(name, request.GetString("if_match", "")); err != nil {
+		var conflict *memory.ConflictError
+		if errors.As(err, &conflict) {
+			return newConflictResult(request, conflict)
+		}
+		return nil, err
+	}
+	s.enhancedStore.RecordChange("delete_memory", name, "")
+
+	message := fmt.Sprintf("Memory '%s' deleted successfully", name)
+	if s.config.Trash.Enabled {
+		message = fmt.Sprintf("Memory '%s' moved to the trash; restore_memory can bring it back", name)
+	}
+	message = withNote(note, message)
+	return newFormattedResult(request, message, MutationOutput{Name: name, Action: "deleted", Message: message, ResolvedFrom: resolvedFrom(requested, note)})
+}
+
+func (s *Server) handleRenameMemory(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requested := request.GetString("name", "")
+	newName := request.GetString("new_name", "")
+	redirect := request.GetBool("redirect", false)
+
+	if newName == "" {
+		return nil, fmt.Errorf("new_name is required")
+	}
+
+	name, note, err := s.resolveName(requested)
+	if err != nil {
+		return nil, err
+	}
+
+	requestedNewName := newName
+	newName, err = s.enhancedStore.NormalizeName(requestedNewName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.enhancedStore.Rename(name, newName, redirect)
+	if err != nil {
+		return nil, err
+	}
+	s.enhancedStore.RecordChange("rename_memory", result.OldName, "to "+result.NewName)
+
+	message := fmt.Sprintf("Memory '%s' renamed to '%s'", result.OldName, result.NewName)
+	if newName != strings.TrimSuffix(requestedNewName, ".md") {
+		message += fmt.Sprintf(" (name normalized from '%s')", requestedNewName)
+	}
+	if result.LinksRewritten > 0 {
+		message += fmt.Sprintf("; rewrote %d links in %d memories", result.LinksRewritten, len(result.UpdatedMemories))
+		if len(result.UpdatedMemories) > 0 {
+			message += fmt.Sprintf(" (%s)", strings.Join(result.UpdatedMemories, ", "))
+		}
+	}
+	if result.Redirect {
+		message += fmt.Sprintf("; left a redirect stub at '%s'", result.OldName)
+	}
+	message = withNote(note, message)
+
+	return newFormattedResult(request, message, RenameOutput{
+		Name:            result.OldName,
+		NewName:         result.NewName,
+		UpdatedMemories: result.UpdatedMemories,
+		LinksRewritten:  result.LinksRewritten,
+		Redirect:        result.Redirect,
+		Message:         message,
+		ResolvedFrom:    resolvedFrom(requested, note),
+	})
+}
+
+func (s *Server) handleMergeMemories(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requestedSource := request.GetString("source", "")
+	requestedTarget := request.GetString("target", "")
+	archive := request.GetBool("archive", false)
+
+	source, sourceNote, err := s.resolveName(requestedSource)
+	if err != nil {
+		return nil, err
+	}
+	target, targetNote, err := s.resolveName(requestedTarget)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.enhancedStore.Merge(source, target, archive, s.validateMemoryLength)
+	if err != nil {
+		return nil, err
+	}
+	s.enhancedStore.RecordChange("merge_memories", result.Source, "into "+result.Target)
+
+	message := fmt.Sprintf("Memory '%s' merged into '%s'", result.Source, result.Target)
+	if result.LinksRewritten > 0 {
+		message += fmt.Sprintf("; redirected %d links in %d memories (%s)", result.LinksRewritten, len(result.UpdatedMemories), strings.Join(result.UpdatedMemories, ", "))
+	}
+	if result.ArchivedTo != "" {
+		message += fmt.Sprintf("; source archived to %s", result.ArchivedTo)
+	} else if result.TrashID != "" {
+		message += fmt.Sprintf("; source moved to the trash (id %s)", result.TrashID)
+	} else {
+		message += "; source deleted"
+	}
+	if len(result.TagConflicts) > 0 {
+		conflicts := make([]string, len(result.TagConflicts))
+		for i, conflict := range result.TagConflicts {
+			conflicts[i] = fmt.Sprintf("'%s' kept %v, dropped %v", conflict.Tag, conflict.Kept, conflict.Dropped)
+		}
+		message += "\n\nTag conflicts:\n- " + strings.Join(conflicts, "\n- ")
+	}
+	for _, note := range []string{targetNote, sourceNote} {
+		message = withNote(note, message)
+	}
+
+	return newFormattedResult(request, message, MergeOutput{
+		Source:          result.Source,
+		Target:          result.Target,
+		TagConflicts:    result.TagConflicts,
+		UpdatedMemories: result.UpdatedMemories,
+		LinksRewritten:  result.LinksRewritten,
+		ArchivedTo:      result.ArchivedTo,
+		TrashID:         result.TrashID,
+		Message:         message,
+	})
+}
+
+// resolveHistoryName resolves the name of a memory that may have been deleted but still has revisions
+func (s *Server) resolveHistoryName(requested string) (string, string, error) {
+	name, note, err := s.resolveName(requested)
+	if err == nil {
+		return name, note, nil
+	}
+
+	var notFound *memory.NotFoundError
+	if errors.As(err, &notFound) && s.enhancedStore.HasHistory(requested) {
+		return strings.TrimSuffix(requested, ".md"), "", nil
+	}
+	return "", "", err
+}
+
+func (s *Server) handleMemoryHistory(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requested := request.GetString("name", "")
+
+	name, note, err := s.resolveHistoryName(requested)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.enhancedStore.History(name)
+	if err != nil {
+		return nil, err
+	}
+	_, readErr := s.enhancedStore.Read(name)
+	exists := readErr == nil
+
+	message := withNote(note, memory.FormatHistoryMarkdown(name, exists, revisions))
+	return newFormattedResult(request, message, HistoryOutput{
+		Name:         name,
+		Exists:       exists,
+		Revisions:    revisions,
+		ResolvedFrom: resolvedFrom(requested, note),
+	})
+}
+
+func (s *Server) handleDiffMemory(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requested := request.GetString("name", "")
+	from := request.GetInt("from", 0)
+	to := request.GetInt("to", 0)
+	contextLines := request.GetInt("context", 3)
+
+	if from < 0 || to < 0 {
+		return nil, fmt.Errorf("revision numbers must be positive")
+	}
+
+	name, note, err := s.resolveHistoryName(requested)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, fromLabel, toLabel, err := s.enhancedStore.DiffRevisions(name, from, to, contextLines)
+	if err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("No differences between %s and %s of '%s'", fromLabel, toLabel, name)
+	if diff != "" {
+		message = fmt.Sprintf("# Changes to %s from %s to %s\n\n```diff\n%s```\n", name, fromLabel, toLabel, diff)
+	}
+	message = withNote(note, message)
+
+	return newFormattedResult(request, message, DiffOutput{
+		Name:    name,
+		From:    fromLabel,
+		To:      toLabel,
+		Diff:    diff,
+		Changed: diff != "",
+	})
+}
+
+func (s *Server) handleRestoreMemory(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requested := request.GetString("name", "")
+	revision := request.GetInt("revision", 0)
+
+	if revision <= 0 {
+		return s.restoreFromTrash(request)
+	}
+
+	name, note, err := s.resolveHistoryName(requested)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.enhancedStore.RestoreRevision(name, revision); err != nil {
+		return nil, err
+	}
+	s.enhancedStore.RecordChange("restore_memory", name, fmt.Sprintf("revision %d", revision))
+
+	message := withNote(note, fmt.Sprintf("Memory '%s' restored to revision %d", name, revision))
+	return newFormattedResult(request, message, RestoreOutput{
+		Name:         name,
+		Revision:     revision,
+		Message:      message,
+		ResolvedFrom: resolvedFrom(requested, note),
+	})
+}
+
+// restoreFromTrash implements restore_memory without a revision
+func (s *Server) restoreFromTrash(request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requested := request.GetString("name", "")
+	if requested == "" {
+		return nil, fmt.Errorf("memory name is required")
+	}
+
+	entry, method, err := s.enhancedStore.RestoreFromTrash(requested, request.GetString("trash_id", ""))
+	if err != nil {
+		return nil, err
+	}
+	s.enhancedStore.RecordChange("restore_memory", entry.Name, "from trash")
+
+	note := ""
+	if method != memory.ResolvedExact {
+		note = fmt.Sprintf("Resolved '%s' to '%s' (%s match in the trash)", requested, entry.Name, method)
+	}
+	message := withNote(note, fmt.Sprintf("Memory '%s' restored from the trash (deleted %s)", entry.Name, entry.Deleted.Local().Format("2006-01-02 15:04:05")))
+	return newFormattedResult(request, message, RestoreOutput{
+		Name:         entry.Name,
+		TrashID:      entry.ID,
+		Message:      message,
+		ResolvedFrom: resolvedFrom(requested, note),
+	})
+}
+
+func (s *Server) handleListTrash(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	entries, err := s.enhancedStore.Trash()
+	if err != nil {
+		return nil, err
+	}
+
+	autoPurgeDays := s.config.Trash.AutoPurgeDays
+	return newFormattedResult(request, memory.FormatTrashMarkdown(entries, autoPurgeDays), TrashOutput{
+		Entries:       entries,
+		AutoPurgeDays: autoPurgeDays,
+	})
+}
+
+func (s *Server) handlePurgeTrash(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	name := request.GetString("name", "")
+	olderThanDays := request.GetFloat("older_than_days", 0)
+	if olderThanDays < 0 {
+		return nil, fmt.Errorf("older_than_days must not be negative")
+	}
 
-	if err := s.enhancedStore.Delete(name); err != nil {
+	purged, err := s.enhancedStore.PurgeTrash(name, time.Duration(olderThanDays*24*float64(time.Hour)))
+	if err != nil {
 		return nil, err
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: fmt.Sprintf("Memory '%s' deleted successfully", name),
-			},
-		},
-	}, nil
+	message := fmt.Sprintf("Purged %d memories from the trash", len(purged))
+	if len(purged) > 0 {
+		names := make([]string, len(purged))
+		for i, entry := range purged {
+			names[i] = entry.Name
+		}
+		message += ": " + strings.Join(names, ", ")
+	}
+	return newFormattedResult(request, message, PurgeTrashOutput{Purged: purged, Message: message})
+}
+
+func (s *Server) handleGitHistory(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requested := request.GetString("name", "")
+	limit := request.GetInt("limit", 20)
+
+	// Deleted memories still have commits, so fall back to the name as given
+	name, note := "", ""
+	if requested != "" {
+		var err error
+		name, note, err = s.resolveName(requested)
+		if err != nil {
+			var notFound *memory.NotFoundError
+			if !errors.As(err, &notFound) {
+				return nil, err
+			}
+			name, note = strings.TrimSuffix(requested, ".md"), ""
+		}
+	}
+
+	commits, err := s.enhancedStore.GitLog(name, limit)
+	if err != nil {
+		return nil, err
+	}
+	if commits == nil {
+		commits = []memory.GitCommit{}
+	}
+
+	message := withNote(note, memory.FormatGitLogMarkdown(name, commits))
+	return newFormattedResult(request, message, GitHistoryOutput{
+		Name:         name,
+		Commits:      commits,
+		ResolvedFrom: resolvedFrom(requested, note),
+	})
+}
+
+func (s *Server) handleLinkMemories(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return s.editRelations(request, true)
+}
+
+func (s *Server) handleUnlinkMemories(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return s.editRelations(request, false)
+}
+
+// editRelations implements link_memories and unlink_memories
+func (s *Server) editRelations(request mcp.CallToolRequest, link bool) (*mcp.CallToolResult, error) {
+	requested := request.GetString("name", "")
+	relation := request.GetString("relation", "")
+	requestedTargets := request.GetStringSlice("targets", nil)
+
+	if link && relation == "" {
+		return nil, fmt.Errorf("relation is required")
+	}
+	if len(requestedTargets) == 0 {
+		return nil, fmt.Errorf("at least one target is required")
+	}
+
+	name, note, err := s.resolveName(requested)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve targets when linking; unlinking must also work for targets that no longer exist
+	targets := make([]string, len(requestedTargets))
+	for i, target := range requestedTargets {
+		targets[i] = strings.TrimSuffix(target, ".md")
+		if link {
+			resolved, _, err := s.resolveName(target)
+			if err != nil {
+				return nil, err
+			}
+			targets[i] = resolved
+		}
+	}
+
+	var changed []string
+	var message string
+	if link {
+		changed, err = s.enhancedStore.LinkMemories(name, relation, targets)
+		if err != nil {
+			return nil, err
+		}
+		message = fmt.Sprintf("Added %d %s relations from '%s'", len(changed), relation, name)
+	} else {
+		changed, err = s.enhancedStore.UnlinkMemories(name, relation, targets)
+		if err != nil {
+			return nil, err
+		}
+		message = fmt.Sprintf("Removed relations from '%s' to %d memories", name, len(changed))
+	}
+	if len(changed) > 0 {
+		message += ": " + strings.Join(changed, ", ")
+		tool := "unlink_memories"
+		if link {
+			tool = "link_memories"
+		}
+		s.enhancedStore.RecordChange(tool, name, strings.Join(changed, ", "))
+	} else {
+		message += " (nothing to change)"
+	}
+
+	relations, err := s.enhancedStore.Relations(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load relations: %w", err)
+	}
+	if len(relations) > 0 {
+		message += "\n\n**Relations of " + name + ":**\n" + memory.FormatRelationsMarkdown(relations)
+	}
+	message = withNote(note, message)
+
+	return newFormattedResult(request, message, RelationsOutput{
+		Name:         name,
+		Relation:     relation,
+		Changed:      changed,
+		Relations:    relations,
+		Message:      message,
+		ResolvedFrom: resolvedFrom(requested, note),
+	})
 }
 
 func (s *Server) handleListMemories(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -610,152 +1655,450 @@ func (s *Server) handleListMemories(_ context.Context, request mcp.CallToolReque
 func (s *Server) handleSearchMemories(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	query := request.GetString("query", "")
 
-	// Get tags and require_all from arguments
-	args := request.GetArguments()
-	var tags map[string]string
-	var requireAll bool
+	// Collect query plus any additional queries, dropping blanks and duplicates
+	var queries []string
+	seen := make(map[string]bool)
+	for _, q := range append([]string{query}, request.GetStringSlice("queries", nil)...) {
+		q = strings.TrimSpace(q)
+		if q == "" || seen[q] {
+			continue
+		}
+		seen[q] = true
+		queries = append(queries, q)
+	}
 
-	if tagsArg, ok := args["tags"]; ok {
-		if tagsMap, ok := tagsArg.(map[string]interface{}); ok {
-			tags = make(map[string]string)
-			for k, v := range tagsMap {
-				tags[k] = fmt.Sprintf("%v", v)
-			}
+	// An empty query would embed to an arbitrary vector, so tag filters alone are not a search
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("query or queries is required")
+	}
+
+	tags, requireAll := parseTagFilters(request.GetArguments())
+
+	namespace := memory.NormalizeNamespace(request.GetString("namespace", ""))
+
+	output := SearchOutput{
+		Queries:    queries,
+		Tags:       tags,
+		RequireAll: requireAll,
+		Namespace:  namespace,
+	}
+	if output.Queries == nil {
+		output.Queries = []string{}
+	}
+
+	var result string
+	if len(queries) > 1 {
+		// Multiple queries are embedded together and fused
+		fused, err := s.enhancedStore.SearchSemanticMultiWithTags(queries, tags, requireAll, namespace, 5)
+		if err != nil {
+			return nil, fmt.Errorf("failed to perform semantic search: %w", err)
+		}
+		result = memory.FormatMultiSearchMarkdown(queries, tags, requireAll, namespace, fused)
+		output.Results = newFusedMemoryHits(fused)
+	} else {
+		query = queries[0]
+		// Use semantic search with tag filtering (set to 5 docs as requested)
+		memories, similarities, err := s.enhancedStore.SearchSemanticWithTags(query, tags, requireAll, namespace, 5)
+		if err != nil {
+			return nil, fmt.Errorf("failed to perform semantic search: %w", err)
 		}
+		result = memory.FormatSemanticSearchMarkdown(query, tags, requireAll, namespace, memories, similarities)
+		output.Results = newMemoryHits(memories, similarities)
 	}
 
-	if requireAllArg, ok := args["require_all"]; ok {
-		requireAll, _ = requireAllArg.(bool)
+	return newFormattedResult(request, result, output)
+}
+
+func (s *Server) handleGrepMemories(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pattern := request.GetString("pattern", "")
+	mode := request.GetString("mode", memory.SearchModeLiteral)
+	contextLines := request.GetInt("context", 2)
+	offset := request.GetInt("offset", 0)
+	limit := request.GetInt("limit", 50)
+
+	if pattern == "" {
+		return nil, fmt.Errorf("pattern is required")
+	}
+	if contextLines < 0 {
+		contextLines = 0
+	}
+	if contextLines > 10 {
+		contextLines = 10
+	}
+	if offset < 0 {
+		offset = 0
 	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	tags, requireAll := parseTagFilters(request.GetArguments())
 
-	// Use semantic search with tag filtering (set to 5 docs as requested)
-	result, err := s.enhancedStore.SearchSemanticMarkdownWithTags(query, tags, requireAll, 5)
+	matches, err := s.store.SearchWithOptions(pattern, memory.SearchOptions{
+		Mode:         mode,
+		Tags:         tags,
+		RequireAll:   requireAll,
+		NameGlob:     request.GetString("name_glob", ""),
+		Namespace:    request.GetString("namespace", ""),
+		ContextLines: contextLines,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to perform semantic search: %w", err)
+		return nil, fmt.Errorf("failed to search memories: %w", err)
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: result,
-			},
-		},
-	}, nil
+	// Page through the full result set
+	page := []memory.SearchMatch{}
+	if offset < len(matches) {
+		end := offset + limit
+		if end > len(matches) {
+			end = len(matches)
+		}
+		page = matches[offset:end]
+	}
+
+	output := GrepOutput{
+		Pattern: pattern,
+		Mode:    mode,
+		Total:   len(matches),
+		Offset:  offset,
+		Matches: newGrepHits(page),
+	}
+	if next := offset + len(page); next < len(matches) {
+		output.NextOffset = next
+	}
+
+	result := memory.FormatSearchMatchesMarkdown(pattern, mode, page, len(matches), offset)
+	return newFormattedResult(request, result, output)
 }
 
 func (s *Server) handleGetBacklinks(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	name := request.GetString("name", "")
+	requested := request.GetString("name", "")
 	query := request.GetString("query", "")
 
+	name, note, err := s.resolveName(requested)
+	if err != nil {
+		return nil, err
+	}
+
 	// Use query for reranking if provided, otherwise use memory name as query
 	if query == "" {
 		query = name // Use memory name as default query for reranking
 	}
 
 	// Get enhanced backlinks with reranking (set to 5 docs as requested)
-	result, err := s.enhancedStore.GetEnhancedBacklinks(name, query, 5)
+	backlinks, err := s.enhancedStore.GetEnhancedBacklinkResults(name, query, 5)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get enhanced backlinks: %w", err)
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: result,
-			},
-		},
-	}, nil
+	result := s.enhancedStore.FormatBacklinksMarkdown(name, backlinks, query)
+	return newFormattedResult(request, withNote(note, result), BacklinksOutput{
+		Name:         name,
+		Query:        query,
+		Backlinks:    newBacklinkHits(backlinks),
+		ResolvedFrom: resolvedFrom(requested, note),
+	})
 }
 
-func (s *Server) handleChangeTag(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	name := request.GetString("name", "")
+func (s *Server) handleFindSimilar(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	requested := request.GetString("name", "")
+	mode := request.GetString("mode", "centroid")
+	exclude := request.GetStringSlice("exclude", nil)
+	limit := request.GetInt("limit", 5)
 
-	// Get tags from arguments
-	args := request.GetArguments()
-	tagsArg, ok := args["tags"]
-	if !ok {
-		return nil, fmt.Errorf("tags parameter is required")
+	name, note, err := s.resolveName(requested)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 5
 	}
 
-	tagsMap, ok := tagsArg.(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("tags must be an object")
+	tags, requireAll := parseTagFilters(request.GetArguments())
+	namespace := memory.NormalizeNamespace(request.GetString("namespace", ""))
+
+	memories, similarities, err := s.enhancedStore.FindSimilar(name, mode, tags, requireAll, namespace, exclude, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find similar memories: %w", err)
 	}
 
-	if name == "" {
-		return nil, fmt.Errorf("memory name is required")
+	result := memory.FormatSimilarMarkdown(name, mode, tags, requireAll, namespace, memories, similarities)
+	return newFormattedResult(request, withNote(note, result), SimilarOutput{
+		Name:         name,
+		Mode:         mode,
+		Results:      newMemoryHits(memories, similarities),
+		ResolvedFrom: resolvedFrom(requested, note),
+	})
+}
+
+func (s *Server) handleCheckLinks(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	includeOrphans := request.GetBool("include_orphans", true)
+
+	report, err := s.enhancedStore.CheckLinks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check links: %w", err)
 	}
-	if len(tagsMap) == 0 {
-		return nil, fmt.Errorf("at least one tag must be specified")
+
+	output := LinkReportOutput{Dangling: report.Dangling}
+	if includeOrphans {
+		output.Orphans = report.Orphans
 	}
 
-	// Read the current memory
-	memInfo, err := s.store.ReadWithMetadata(name)
+	return newFormattedResult(request, memory.FormatLinkReportMarkdown(report, includeOrphans), output)
+}
+
+func (s *Server) handleReconcileMemories(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	report, err := s.enhancedStore.Reconcile(request.GetBool("dry_run", false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile: %w", err)
+	}
+
+	return newFormattedResult(request, memory.FormatReconcileReportMarkdown(report), ReconcileOutput{ReconcileReport: *report})
+}
+
+func (s *Server) handleQueryCacheStats(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	stats, enabled := s.enhancedStore.QueryCacheStats()
+	output := QueryCacheOutput{Enabled: enabled, CacheStats: stats, HitRate: stats.HitRate()}
+
+	message := "The query embedding cache is disabled; set [query_cache] size to enable it."
+	if enabled {
+		message = fmt.Sprintf("# Query cache\n\n- Hits: %d\n- Misses: %d\n- Hit rate: %.1f%%\n- Evictions: %d\n- Size: %d/%d\n",
+			stats.Hits, stats.Misses, stats.HitRate()*100, stats.Evictions, stats.Size, stats.Capacity)
+	}
+	return newFormattedResult(request, message, output)
+}
+
+func (s *Server) handleGraphQuery(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	hops := request.GetInt("hops", 2)
+	maxNodes := request.GetInt("max_nodes", 50)
+	minSimilarity := request.GetFloat("min_similarity", 0)
+
+	if hops < 1 {
+		hops = 1
+	}
+	if hops > 5 {
+		hops = 5
+	}
+	if maxNodes <= 0 {
+		maxNodes = 50
+	}
+
+	start, startNote, err := s.resolveName(request.GetString("name", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	tags, requireAll := parseTagFilters(request.GetArguments())
+
+	filter := memory.GraphFilter{
+		EdgeTypes:  request.GetStringSlice("edge_types", nil),
+		Direction:  request.GetString("direction", memory.DirectionBoth),
+		Tags:       tags,
+		RequireAll: requireAll,
+		MaxNodes:   maxNodes,
+	}
+
+	graph, err := s.enhancedStore.LoadGraph(float32(minSimilarity))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read memory '%s': %w", name, err)
+		return nil, fmt.Errorf("failed to load memory graph: %w", err)
+	}
+
+	if requestedTarget := request.GetString("target", ""); requestedTarget != "" {
+		target, targetNote, err := s.resolveName(requestedTarget)
+		if err != nil {
+			return nil, err
+		}
+
+		path, err := graph.ShortestPath(start, target, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		result := withNote(startNote, withNote(targetNote, memory.FormatPathMarkdown(start, target, path)))
+		return newFormattedResult(request, result, newPathOutput(start, target, graph, path))
 	}
 
-	// Parse the current document to get frontmatter and body
-	fm, body, err := memory.ParseDocument(memInfo.Content)
+	neighborhood, err := graph.Neighborhood(start, hops, filter)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse memory document: %w", err)
+		return nil, err
 	}
 
-	// Initialize tags map if it doesn't exist
-	if fm.Tags == nil {
-		fm.Tags = make(map[string]interface{})
+	result := withNote(startNote, memory.FormatNeighborhoodMarkdown(start, hops, neighborhood))
+	return newFormattedResult(request, result, newNeighborhoodOutput(start, neighborhood))
+}
+
+func (s *Server) handleExportGraph(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	graphFormat := request.GetString("graph_format", memory.ExportMermaid)
+
+	tags, requireAll := parseTagFilters(request.GetArguments())
+
+	rendered, graph, err := s.enhancedStore.ExportGraph(memory.ExportOptions{
+		Format:        graphFormat,
+		ClusterBy:     request.GetString("cluster_by", ""),
+		MinSimilarity: float32(request.GetFloat("min_similarity", 0.5)),
+		Filter: memory.GraphFilter{
+			EdgeTypes:  request.GetStringSlice("edge_types", nil),
+			Tags:       tags,
+			RequireAll: requireAll,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export graph: %w", err)
 	}
 
-	// Track changes for response message
-	var changes []string
+	// GraphML is XML; the other formats have matching code fence languages
+	fence := graphFormat
+	if graphFormat == memory.ExportGraphML {
+		fence = "xml"
+	}
+	result := fmt.Sprintf("# Memory graph (%s)\n\n%d memories, %d edges\n\n```%s\n%s```\n",
+		graphFormat, len(graph.Nodes), len(graph.Edges), fence, rendered)
+
+	return newFormattedResult(request, result, ExportGraphOutput{
+		GraphFormat: graphFormat,
+		Nodes:       len(graph.Nodes),
+		Edges:       len(graph.Edges),
+		Graph:       rendered,
+	})
+}
 
-	// Process each tag
-	for tagKey, tagValue := range tagsMap {
-		oldValue := fm.Tags[tagKey]
+func (s *Server) handleFindDuplicates(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	tags, requireAll := parseTagFilters(request.GetArguments())
 
-		if tagValue == nil {
-			// Remove the tag if value is null
-			if oldValue != nil {
-				delete(fm.Tags, tagKey)
-				changes = append(changes, fmt.Sprintf("'%s' removed (was: %v)", tagKey, oldValue))
-			} else {
-				changes = append(changes, fmt.Sprintf("'%s' already absent", tagKey))
-			}
-		} else {
-			// Set or update the tag
-			fm.Tags[tagKey] = tagValue
-			if oldValue != nil {
-				changes = append(changes, fmt.Sprintf("'%s' changed from %v to %v", tagKey, oldValue, tagValue))
-			} else {
-				changes = append(changes, fmt.Sprintf("'%s' set to %v", tagKey, tagValue))
+	pairs, err := s.enhancedStore.FindDuplicates(memory.DuplicateOptions{
+		Threshold:     float32(request.GetFloat("threshold", 0)),
+		TextThreshold: request.GetFloat("text_threshold", 0),
+		Tags:          tags,
+		RequireAll:    requireAll,
+		Namespace:     request.GetString("namespace", ""),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+
+	return newFormattedResult(request, memory.FormatDuplicatesMarkdown(pairs), DuplicatesOutput{Pairs: pairs})
+}
+
+func (s *Server) handleListNamespaces(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	namespace := memory.NormalizeNamespace(request.GetString("namespace", ""))
+
+	namespaces, err := s.store.Namespaces(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	return newFormattedResult(request, memory.FormatNamespacesMarkdown(namespaces), NamespacesOutput{Namespaces: namespaces})
+}
+
+func (s *Server) handleClusterMemories(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	apply := request.GetBool("apply", false)
+
+	tags, requireAll := parseTagFilters(request.GetArguments())
+
+	minShare := request.GetFloat("min_share", 0.5)
+	if minShare <= 0 || minShare > 1 {
+		return nil, fmt.Errorf("min_share must be between 0 and 1")
+	}
+
+	report, err := s.enhancedStore.ClusterMemories(memory.ClusterOptions{
+		K:          request.GetInt("k", 0),
+		Tags:       tags,
+		RequireAll: requireAll,
+		Namespace:  request.GetString("namespace", ""),
+		MinShare:   minShare,
+		Keywords:   request.GetInt("keywords", 5),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	message := memory.FormatClusterReportMarkdown(report)
+	output := ClusterOutput{ClusterReport: *report, Applied: []string{}}
+
+	if apply {
+		var failures []string
+		for _, cluster := range report.Clusters {
+			for _, suggestion := range cluster.Suggestions {
+				// Apply through the same path as change_tag
+				tagChanges, _, err := s.enhancedStore.ChangeTags(suggestion.Name, suggestion.Tags, "")
+				if err != nil {
+					failures = append(failures, fmt.Sprintf("%s: %v", suggestion.Name, err))
+					continue
+				}
+				s.enhancedStore.RecordChange("cluster_memories", suggestion.Name, describeTagChanges(tagChanges))
+				output.Applied = append(output.Applied, suggestion.Name)
 			}
 		}
+
+		message += fmt.Sprintf("\nApplied tag suggestions to %d memories.\n", len(output.Applied))
+		if len(failures) > 0 {
+			message += "Failed:\n- " + strings.Join(failures, "\n- ") + "\n"
+		}
+	} else if hasSuggestions(report) {
+		message += "Run with apply=true to set the suggested tags.\n"
+	}
+
+	return newFormattedResult(request, message, output)
+}
+
+// hasSuggestions reports whether any cluster suggests tags
+func hasSuggestions(report *memory.ClusterReport) bool {
+	for _, cluster := range report.Clusters {
+		if len(cluster.Suggestions) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) handleChangeTag(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := request.GetString("name", "")
+
+	tagsMap, err := parseTagChanges(request.GetArguments())
+	if err != nil {
+		return nil, err
 	}
 
-	// Format the updated document
-	updatedContent, err := memory.FormatDocument(fm, body)
+	requested := name
+	name, note, err := s.resolveName(requested)
 	if err != nil {
-		return nil, fmt.Errorf("failed to format updated document: %w", err)
+		return nil, err
 	}
 
-	// Update the memory using the enhanced store
-	if err := s.enhancedStore.Update(name, updatedContent); err != nil {
-		return nil, fmt.Errorf("failed to update memory: %w", err)
+	tagChanges, tags, err := s.enhancedStore.ChangeTags(name, tagsMap, request.GetString("if_match", ""))
+	if err != nil {
+		var conflict *memory.ConflictError
+		if errors.As(err, &conflict) {
+			return newConflictResult(request, conflict)
+		}
+		return nil, err
 	}
+	s.enhancedStore.RecordChange("change_tag", name, describeTagChanges(tagChanges))
 
 	// Build response message
-	message := fmt.Sprintf("Updated tags in memory '%s':\n- %s", name, strings.Join(changes, "\n- "))
+	changes := make([]string, len(tagChanges))
+	for i, change := range tagChanges {
+		changes[i] = change.String()
+	}
+	message := withNote(note, fmt.Sprintf("Updated tags in memory '%s':\n- %s", name, strings.Join(changes, "\n- ")))
+
+	return newFormattedResult(request, message, TagChangeOutput{
+		Name:         name,
+		Changes:      tagChanges,
+		Tags:         tags,
+		ResolvedFrom: resolvedFrom(requested, note),
+	})
+}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: message,
-			},
-		},
-	}, nil
+// describeTagChanges summarizes tag changes for a commit message, leaving out tags that did not change
+func describeTagChanges(changes []memory.TagChange) string {
+	var parts []string
+	for _, change := range changes {
+		if change.Action != memory.TagAbsent {
+			parts = append(parts, change.String())
+		}
+	}
+	return strings.Join(parts, ", ")
 }
 
 func (s *Server) Run() error {
@@ -1,7 +1,11 @@
 package mcp
 
 import (
+	"context"
+	"reflect"
 	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func TestStripMarkdown(t *testing.T) {
@@ -99,4 +103,27 @@ This is **bold text** and *italic text*.
 	if ratio < 0.3 || ratio > 0.8 {
 		t.Errorf("Expected compression ratio between 0.3-0.8, got %.2f", ratio)
 	}
-}
\ No newline at end of file
+}
+
+func TestParseTagFilters(t *testing.T) {
+	tags, requireAll := parseTagFilters(map[string]any{
+		"tags":        map[string]interface{}{"todo": true, "priority": "high"},
+		"require_all": true,
+	})
+	if want := map[string]string{"todo": "true", "priority": "high"}; !reflect.DeepEqual(tags, want) || !requireAll {
+		t.Errorf("parseTagFilters() = %v, %v, want %v, true", tags, requireAll, want)
+	}
+
+	tags, requireAll = parseTagFilters(map[string]any{"tags": "todo"})
+	if tags != nil || requireAll {
+		t.Errorf("parseTagFilters() with invalid arguments = %v, %v, want no filters", tags, requireAll)
+	}
+}
+
+func TestSearchMemoriesRequiresQuery(t *testing.T) {
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"query": "  ", "tags": map[string]interface{}{"todo": ""}}
+	if _, err := (&Server{}).handleSearchMemories(context.Background(), request); err == nil {
+		t.Error("expected a blank query to be rejected before anything is embedded")
+	}
+}
@@ -0,0 +1,346 @@
+package memory
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jcdickinson/simplemem/internal/rag"
+)
+
+// Defaults for clustering
+const (
+	defaultClusterMinShare = 0.5
+	defaultClusterKeywords = 5
+	clusterSeed            = 42 // Fixed so repeated runs produce the same clusters
+)
+
+var (
+	wordRegex = regexp.MustCompile(`[\p{L}\p{N}][\p{L}\p{N}_-]*`)
+
+	// Common English words that carry no topic
+	stopWords = map[string]bool{
+		"the": true, "and": true, "for": true, "are": true, "but": true, "not": true, "you": true,
+		"all": true, "any": true, "can": true, "had": true, "her": true, "was": true, "one": true,
+		"our": true, "out": true, "has": true, "have": true, "this": true, "that": true, "with": true,
+		"from": true, "they": true, "will": true, "would": true, "there": true, "their": true,
+		"what": true, "when": true, "which": true, "into": true, "than": true, "then": true,
+		"them": true, "these": true, "those": true, "some": true, "such": true, "only": true,
+		"also": true, "been": true, "being": true, "were": true, "does": true, "each": true,
+		"how": true, "its": true, "use": true, "used": true, "using": true, "may": true, "more": true,
+		"most": true, "other": true, "should": true, "could": true, "about": true, "after": true,
+		"before": true, "where": true, "while": true, "who": true, "why": true, "your": true,
+		"via": true, "per": true, "see": true, "like": true, "just": true, "need": true, "needs": true,
+	}
+)
+
+// ClusterOptions controls how memories are clustered
+type ClusterOptions struct {
+	K          int               // Number of clusters (0 picks one from the number of memories)
+	Tags       map[string]string // Tag filters restricting which memories are clustered
+	RequireAll bool              // Require all tag filters to match instead of any
+	Namespace  string            // Only cluster memories in this namespace and its sub-namespaces
+	MinShare   float64           // Share of a cluster's members that must carry a tag for it to be common
+	Keywords   int               // Keywords reported per cluster
+}
+
+// TagSuggestion proposes tags for a memory that lacks the common tags of its cluster
+type TagSuggestion struct {
+	Name string                 `json:"name"`
+	Tags map[string]interface{} `json:"tags"`
+}
+
+// MemoryCluster is a group of semantically similar memories
+type MemoryCluster struct {
+	ID          int                    `json:"id"`
+	Keywords    []string               `json:"keywords" jsonschema:"description=Most distinctive terms of the members by TF-IDF"`
+	Members     []string               `json:"members"`
+	CommonTags  map[string]interface{} `json:"common_tags,omitempty" jsonschema:"description=Tags carried by at least min_share of the members"`
+	Suggestions []TagSuggestion        `json:"suggestions,omitempty"`
+}
+
+// ClusterReport is the result of clustering the memory store
+type ClusterReport struct {
+	K          int             `json:"k"`
+	Clusters   []MemoryCluster `json:"clusters"`
+	Unembedded []string        `json:"unembedded,omitempty" jsonschema:"description=Memories without embeddings that could not be clustered"`
+}
+
+// clusterDoc is a memory prepared for clustering
+type clusterDoc struct {
+	name   string
+	body   string
+	tags   map[string]interface{}
+	vector []float32
+}
+
+// ClusterMemories groups memories by k-means over their memory-level embeddings, describes each
+// cluster by TF-IDF keywords and suggests the cluster's common tags to members missing them
+func (es *EnhancedStore) ClusterMemories(opts ClusterOptions) (*ClusterReport, error) {
+	vectorsByName, err := es.memoryVectorsByName()
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := es.Store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memories: %w", err)
+	}
+	sort.Strings(names)
+
+	report := &ClusterReport{Clusters: []MemoryCluster{}}
+	var docs []clusterDoc
+	for _, name := range names {
+		info, err := es.Store.ReadWithMetadata(name)
+		if err != nil {
+			continue
+		}
+		if !InNamespace(name, opts.Namespace) || !matchesTagFilters(info, opts.Tags, opts.RequireAll) {
+			continue
+		}
+
+		vector, ok := vectorsByName[name]
+		if !ok {
+			report.Unembedded = append(report.Unembedded, name)
+			continue
+		}
+
+		docs = append(docs, clusterDoc{name: name, body: info.Body, tags: info.Frontmatter.Tags, vector: vector})
+	}
+
+	if len(docs) == 0 {
+		return report, nil
+	}
+
+	k := opts.K
+	if k <= 0 {
+		k = defaultClusterCount(len(docs))
+	}
+	if k > len(docs) {
+		k = len(docs)
+	}
+	report.K = k
+
+	points := make([][]float32, len(docs))
+	for i, doc := range docs {
+		points[i] = doc.vector
+	}
+
+	report.Clusters = buildClusters(docs, rag.KMeans(points, k, clusterSeed), opts)
+	return report, nil
+}
+
+// defaultClusterCount picks a cluster count from the rule of thumb k = sqrt(n/2)
+func defaultClusterCount(n int) int {
+	k := int(math.Round(math.Sqrt(float64(n) / 2)))
+	if k < 1 {
+		return 1
+	}
+	return k
+}
+
+// buildClusters groups documents by assignment, largest cluster first, and fills in keywords,
+// common tags and tag suggestions
+func buildClusters(docs []clusterDoc, assignments []int, opts ClusterOptions) []MemoryCluster {
+	minShare := opts.MinShare
+	if minShare <= 0 {
+		minShare = defaultClusterMinShare
+	}
+	keywordCount := opts.Keywords
+	if keywordCount <= 0 {
+		keywordCount = defaultClusterKeywords
+	}
+
+	groups := make(map[int][]clusterDoc)
+	for i, doc := range docs {
+		groups[assignments[i]] = append(groups[assignments[i]], doc)
+	}
+
+	var ordered [][]clusterDoc
+	for _, group := range groups {
+		ordered = append(ordered, group)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if len(ordered[i]) != len(ordered[j]) {
+			return len(ordered[i]) > len(ordered[j])
+		}
+		return ordered[i][0].name < ordered[j][0].name
+	})
+
+	idf := inverseDocumentFrequencies(docs)
+
+	clusters := make([]MemoryCluster, len(ordered))
+	for i, group := range ordered {
+		cluster := MemoryCluster{ID: i + 1, Keywords: clusterKeywords(group, idf, keywordCount)}
+		for _, doc := range group {
+			cluster.Members = append(cluster.Members, doc.name)
+		}
+
+		cluster.CommonTags = commonTags(group, minShare)
+		for _, doc := range group {
+			missing := make(map[string]interface{})
+			for key, value := range cluster.CommonTags {
+				if _, ok := doc.tags[key]; !ok {
+					missing[key] = value
+				}
+			}
+			if len(missing) > 0 {
+				cluster.Suggestions = append(cluster.Suggestions, TagSuggestion{Name: doc.name, Tags: missing})
+			}
+		}
+
+		clusters[i] = cluster
+	}
+
+	return clusters
+}
+
+// commonTags returns the key/value pairs carried by at least minShare of a cluster's members.
+// Only clusters of two or more memories have common tags.
+func commonTags(group []clusterDoc, minShare float64) map[string]interface{} {
+	if len(group) < 2 {
+		return nil
+	}
+
+	type tagValue struct {
+		key, value string
+	}
+	counts := make(map[tagValue]int)
+	values := make(map[tagValue]interface{})
+	for _, doc := range group {
+		for key, value := range doc.tags {
+			tv := tagValue{key, fmt.Sprintf("%v", value)}
+			counts[tv]++
+			values[tv] = value
+		}
+	}
+
+	common := make(map[string]interface{})
+	best := make(map[string]int)
+	for tv, count := range counts {
+		if count < 2 || float64(count)/float64(len(group)) < minShare {
+			continue
+		}
+		// Prefer the most frequent value when several values of a key qualify
+		if count > best[tv.key] || (count == best[tv.key] && tv.value < fmt.Sprintf("%v", common[tv.key])) {
+			best[tv.key] = count
+			common[tv.key] = values[tv]
+		}
+	}
+
+	if len(common) == 0 {
+		return nil
+	}
+	return common
+}
+
+// tokenize splits text into lowercase terms, dropping short words, numbers and stop words
+func tokenize(text string) []string {
+	var terms []string
+	for _, word := range wordRegex.FindAllString(strings.ToLower(text), -1) {
+		word = strings.Trim(word, "-_")
+		if len([]rune(word)) < 3 || stopWords[word] || strings.Trim(word, "0123456789") == "" {
+			continue
+		}
+		terms = append(terms, word)
+	}
+	return terms
+}
+
+// inverseDocumentFrequencies computes the smoothed IDF of every term across the documents
+func inverseDocumentFrequencies(docs []clusterDoc) map[string]float64 {
+	documentFrequency := make(map[string]int)
+	for _, doc := range docs {
+		seen := make(map[string]bool)
+		for _, term := range tokenize(doc.body) {
+			if !seen[term] {
+				seen[term] = true
+				documentFrequency[term]++
+			}
+		}
+	}
+
+	idf := make(map[string]float64, len(documentFrequency))
+	for term, df := range documentFrequency {
+		idf[term] = math.Log(float64(1+len(docs))/float64(1+df)) + 1
+	}
+	return idf
+}
+
+// clusterKeywords ranks terms by their summed, length-normalized term frequency across the
+// cluster's members weighted by IDF
+func clusterKeywords(group []clusterDoc, idf map[string]float64, limit int) []string {
+	scores := make(map[string]float64)
+	for _, doc := range group {
+		terms := tokenize(doc.body)
+		for _, term := range terms {
+			scores[term] += idf[term] / float64(len(terms))
+		}
+	}
+
+	keywords := make([]string, 0, len(scores))
+	for term := range scores {
+		keywords = append(keywords, term)
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if scores[keywords[i]] != scores[keywords[j]] {
+			return scores[keywords[i]] > scores[keywords[j]]
+		}
+		return keywords[i] < keywords[j]
+	})
+
+	if len(keywords) > limit {
+		keywords = keywords[:limit]
+	}
+	return keywords
+}
+
+// FormatClusterReportMarkdown formats a cluster report as markdown
+func FormatClusterReportMarkdown(report *ClusterReport) string {
+	var md strings.Builder
+	md.WriteString(fmt.Sprintf("# Memory clusters (k=%d)\n", report.K))
+
+	if len(report.Clusters) == 0 {
+		md.WriteString("\nNo memories with embeddings to cluster.\n")
+	}
+
+	suggestions := 0
+	for _, cluster := range report.Clusters {
+		md.WriteString(fmt.Sprintf("\n## Cluster %d: %s\n", cluster.ID, strings.Join(cluster.Keywords, ", ")))
+		md.WriteString(fmt.Sprintf("**Members (%d):** %s\n", len(cluster.Members), strings.Join(cluster.Members, ", ")))
+
+		if len(cluster.CommonTags) > 0 {
+			md.WriteString(fmt.Sprintf("**Common tags:** %s\n", formatTagMap(cluster.CommonTags)))
+		}
+		for _, suggestion := range cluster.Suggestions {
+			md.WriteString(fmt.Sprintf("- Suggest for **%s**: %s\n", suggestion.Name, formatTagMap(suggestion.Tags)))
+			suggestions++
+		}
+	}
+
+	if len(report.Unembedded) > 0 {
+		md.WriteString(fmt.Sprintf("\n%d memories have no embeddings yet and were skipped: %s\n", len(report.Unembedded), strings.Join(report.Unembedded, ", ")))
+	}
+	if suggestions > 0 {
+		md.WriteString(fmt.Sprintf("\n%d tag suggestions.\n", suggestions))
+	}
+
+	return md.String()
+}
+
+// formatTagMap formats tags as "key: value" pairs in key order
+func formatTagMap(tags map[string]interface{}) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = fmt.Sprintf("%s: %v", key, tags[key])
+	}
+	return strings.Join(pairs, ", ")
+}
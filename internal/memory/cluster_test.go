@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildClusters(t *testing.T) {
+	docs := []clusterDoc{
+		{name: "duckdb-schema", body: "The DuckDB schema stores embeddings in a vector table.", tags: map[string]interface{}{"area": "database", "todo": true}},
+		{name: "duckdb-indexes", body: "DuckDB vector indexes speed up embeddings search.", tags: map[string]interface{}{"area": "database"}},
+		{name: "duckdb-migrations", body: "Schema migrations for DuckDB run at startup.", tags: map[string]interface{}{}},
+		{name: "release-process", body: "Release builds are tagged and published by CI.", tags: map[string]interface{}{"area": "ops"}},
+	}
+
+	clusters := buildClusters(docs, []int{1, 1, 1, 0}, ClusterOptions{Keywords: 2})
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %+v", clusters)
+	}
+
+	db := clusters[0]
+	if db.ID != 1 || !reflect.DeepEqual(db.Members, []string{"duckdb-schema", "duckdb-indexes", "duckdb-migrations"}) {
+		t.Errorf("expected the largest cluster first, got %+v", db)
+	}
+	if len(db.Keywords) != 2 || db.Keywords[0] != "duckdb" {
+		t.Errorf("unexpected keywords: %v", db.Keywords)
+	}
+	if !reflect.DeepEqual(db.CommonTags, map[string]interface{}{"area": "database"}) {
+		t.Errorf("unexpected common tags: %v", db.CommonTags)
+	}
+	want := []TagSuggestion{{Name: "duckdb-migrations", Tags: map[string]interface{}{"area": "database"}}}
+	if !reflect.DeepEqual(db.Suggestions, want) {
+		t.Errorf("unexpected suggestions: %+v", db.Suggestions)
+	}
+
+	if single := clusters[1]; single.CommonTags != nil || single.Suggestions != nil {
+		t.Errorf("expected no suggestions for a single-member cluster, got %+v", single)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("The [[DuckDB]] schema, v1.2 and 2024: it's the embeddings' table")
+	want := []string{"duckdb", "schema", "embeddings", "table"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenize() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,280 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jcdickinson/simplemem/internal/rag"
+)
+
+// Defaults for duplicate detection
+const (
+	defaultDuplicateThreshold     = 0.92
+	defaultDuplicateTextThreshold = 0.5
+	defaultShingleSize            = 3
+)
+
+// duplicateCandidates is the number of nearest memories DuplicatesOf compares a memory with
+const duplicateCandidates = 10
+
+// DuplicateOptions controls which memory pairs are reported as near-duplicates. A pair is a
+// duplicate when either its embedding similarity or its shingle overlap reaches its threshold.
+type DuplicateOptions struct {
+	Threshold     float32           // Minimum embedding similarity (0 uses the configured value)
+	TextThreshold float64           // Minimum Jaccard similarity of word shingles (0 uses the configured value)
+	Tags          map[string]string // Tag filters restricting which memories are compared
+	RequireAll    bool              // Require all tag filters to match instead of any
+	Namespace     string            // Only compare memories in this namespace and its sub-namespaces
+}
+
+// DuplicatePair is a pair of memories that look like near-duplicates
+type DuplicatePair struct {
+	Name       string  `json:"name"`
+	Duplicate  string  `json:"duplicate"`
+	Similarity float32 `json:"similarity" jsonschema:"description=Cosine similarity of the memories' embeddings (0 if either has none)"`
+	Overlap    float64 `json:"overlap" jsonschema:"description=Jaccard similarity of the memories' word shingles"`
+}
+
+// duplicateDoc is a memory prepared for duplicate detection
+type duplicateDoc struct {
+	name     string
+	tags     map[string]interface{}
+	shingles map[string]bool
+	vector   []float32
+}
+
+// FindDuplicates compares every pair of memories and returns the near-duplicates, most similar first
+func (es *EnhancedStore) FindDuplicates(opts DuplicateOptions) ([]DuplicatePair, error) {
+	opts = es.duplicateOptions(opts)
+
+	vectors, err := es.memoryVectorsByName()
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := es.Store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memories: %w", err)
+	}
+	sort.Strings(names)
+
+	var docs []duplicateDoc
+	for _, name := range names {
+		info, err := es.Store.ReadWithMetadata(name)
+		if err != nil {
+			continue
+		}
+		if !InNamespace(name, opts.Namespace) || !matchesTagFilters(info, opts.Tags, opts.RequireAll) {
+			continue
+		}
+		docs = append(docs, es.duplicateDoc(info, vectors[name]))
+	}
+
+	pairs := []DuplicatePair{}
+	for i := range docs {
+		for j := i + 1; j < len(docs); j++ {
+			a, b := docs[i], docs[j]
+			var similarity float32
+			if a.vector != nil && b.vector != nil {
+				similarity = rag.CosineSimilarity(a.vector, b.vector)
+			}
+			if pair, ok := duplicatePair(a, b, similarity, opts); ok {
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+	sortDuplicatePairs(pairs)
+
+	return pairs, nil
+}
+
+// DuplicatesOf returns the memories that look like near-duplicates of the named memory. Only
+// its nearest memories by embedding, looked up in the database, are compared, so the check is
+// cheap enough to run on every create; a memory without embeddings has no duplicates.
+func (es *EnhancedStore) DuplicatesOf(name string, opts DuplicateOptions) ([]DuplicatePair, error) {
+	opts = es.duplicateOptions(opts)
+	name = strings.TrimSuffix(name, ".md")
+
+	info, err := es.Store.ReadWithMetadata(name)
+	if err != nil {
+		return nil, err
+	}
+	memory, err := es.db.GetMemory(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memory: %w", err)
+	}
+	pairs := []DuplicatePair{}
+	if memory == nil {
+		return pairs, nil
+	}
+
+	neighbours, err := es.db.SimilarByCentroid(memory.ID, 0, duplicateCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := es.duplicateDoc(info, nil)
+	for id, similarity := range neighbours {
+		other, err := es.db.GetMemoryByID(id)
+		if err != nil || other == nil || !InNamespace(other.Name, opts.Namespace) {
+			continue
+		}
+		otherInfo, err := es.Store.ReadWithMetadata(other.Name)
+		if err != nil || !matchesTagFilters(otherInfo, opts.Tags, opts.RequireAll) {
+			continue
+		}
+
+		if pair, ok := duplicatePair(doc, es.duplicateDoc(otherInfo, nil), similarity, opts); ok {
+			pairs = append(pairs, pair)
+		}
+	}
+	sortDuplicatePairs(pairs)
+
+	return pairs, nil
+}
+
+// duplicateDoc prepares a memory for duplicate detection
+func (es *EnhancedStore) duplicateDoc(info *MemoryInfo, vector []float32) duplicateDoc {
+	return duplicateDoc{
+		name:     info.Name,
+		tags:     info.Frontmatter.Tags,
+		shingles: shingles(info.Body, es.duplicates.ShingleSize),
+		vector:   vector,
+	}
+}
+
+// duplicatePair compares two memories whose embeddings have the given similarity, reporting
+// whether they look like near-duplicates
+func duplicatePair(a, b duplicateDoc, similarity float32, opts DuplicateOptions) (DuplicatePair, bool) {
+	// Redirect stubs point at their target by design
+	if isRedirect(a.tags) || isRedirect(b.tags) {
+		return DuplicatePair{}, false
+	}
+
+	pair := DuplicatePair{Name: a.name, Duplicate: b.name, Similarity: similarity, Overlap: jaccard(a.shingles, b.shingles)}
+	return pair, pair.Similarity >= opts.Threshold || pair.Overlap >= opts.TextThreshold
+}
+
+// sortDuplicatePairs orders duplicate pairs most similar first
+func sortDuplicatePairs(pairs []DuplicatePair) {
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].Similarity != pairs[j].Similarity {
+			return pairs[i].Similarity > pairs[j].Similarity
+		}
+		return pairs[i].Overlap > pairs[j].Overlap
+	})
+}
+
+// duplicateOptions fills unset thresholds from the configuration
+func (es *EnhancedStore) duplicateOptions(opts DuplicateOptions) DuplicateOptions {
+	if opts.Threshold <= 0 {
+		opts.Threshold = es.duplicates.Threshold
+	}
+	if opts.TextThreshold <= 0 {
+		opts.TextThreshold = es.duplicates.TextThreshold
+	}
+	return opts
+}
+
+// memoryVectorsByName returns the memory-level embedding of every embedded memory, keyed by name
+func (es *EnhancedStore) memoryVectorsByName() (map[string][]float32, error) {
+	chunks, err := es.db.GetAllChunkVectors()
+	if err != nil {
+		return nil, err
+	}
+	vectors := rag.MemoryVectors(chunks)
+
+	ids, err := es.db.GetMemoryNames()
+	if err != nil {
+		return nil, err
+	}
+
+	vectorsByName := make(map[string][]float32, len(vectors))
+	for id, vector := range vectors {
+		vectorsByName[ids[id]] = vector
+	}
+	return vectorsByName, nil
+}
+
+// isRedirect reports whether tags mark a redirect stub left by rename_memory or merge_memories
+func isRedirect(tags map[string]interface{}) bool {
+	redirect, _ := tags["redirect"].(bool)
+	return redirect
+}
+
+// shingles returns the set of size-word shingles of a text, ignoring case and punctuation.
+// Texts shorter than one shingle form a single shingle.
+func shingles(text string, size int) map[string]bool {
+	if size <= 0 {
+		size = defaultShingleSize
+	}
+
+	words := wordRegex.FindAllString(strings.ToLower(text), -1)
+	set := make(map[string]bool)
+	if len(words) == 0 {
+		return set
+	}
+	if len(words) < size {
+		set[strings.Join(words, " ")] = true
+		return set
+	}
+
+	for i := 0; i+size <= len(words); i++ {
+		set[strings.Join(words[i:i+size], " ")] = true
+	}
+	return set
+}
+
+// jaccard returns the Jaccard similarity of two sets, or 0 if both are empty
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+
+	intersection := 0
+	for item := range a {
+		if b[item] {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+// FormatDuplicatesMarkdown formats near-duplicate pairs as markdown
+func FormatDuplicatesMarkdown(pairs []DuplicatePair) string {
+	if len(pairs) == 0 {
+		return "No near-duplicate memories found."
+	}
+
+	var md strings.Builder
+	md.WriteString(fmt.Sprintf("# Near-duplicate memories (%d pairs)\n\n", len(pairs)))
+	for _, pair := range pairs {
+		md.WriteString(fmt.Sprintf("- **%s** ~ **%s** (%s)\n", pair.Name, pair.Duplicate, formatDuplicateScores(pair)))
+	}
+	md.WriteString("\nUse merge_memories to combine a duplicate into the memory it repeats.\n")
+
+	return md.String()
+}
+
+// FormatDuplicateWarning formats the duplicates of a newly created memory as a warning
+func FormatDuplicateWarning(pairs []DuplicatePair) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	lines := make([]string, len(pairs))
+	for i, pair := range pairs {
+		lines[i] = fmt.Sprintf("- %s (%s)", pair.Duplicate, formatDuplicateScores(pair))
+	}
+	return fmt.Sprintf("Warning: '%s' looks like a near-duplicate of existing memories:\n%s\nConsider merging with merge_memories.",
+		pairs[0].Name, strings.Join(lines, "\n"))
+}
+
+// formatDuplicateScores describes the similarity scores of a duplicate pair
+func formatDuplicateScores(pair DuplicatePair) string {
+	if pair.Similarity == 0 {
+		return fmt.Sprintf("text overlap %.2f", pair.Overlap)
+	}
+	return fmt.Sprintf("similarity %.3f, text overlap %.2f", pair.Similarity, pair.Overlap)
+}
@@ -0,0 +1,282 @@
+package memory
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// EditOperation is a partial edit of a memory body
+type EditOperation string
+
+const (
+	EditAppend             EditOperation = "append"
+	EditPrepend            EditOperation = "prepend"
+	EditReplaceSection     EditOperation = "replace_section"
+	EditStrReplace         EditOperation = "str_replace"
+	EditInsertAfterHeading EditOperation = "insert_after_heading"
+)
+
+// EditOperations lists the supported edit operations
+var EditOperations = []EditOperation{EditAppend, EditPrepend, EditReplaceSection, EditStrReplace, EditInsertAfterHeading}
+
+// Edit describes a partial edit of a memory body. Heading selects the section for
+// replace_section and insert_after_heading, and Old the text replaced by str_replace. A non-empty
+// IfMatch fails the edit with a ConflictError unless the memory matches that version.
+type Edit struct {
+	Operation EditOperation
+	Content   string
+	Heading   string
+	Old       string
+	IfMatch   string
+}
+
+// String describes the edit, e.g. for a commit message
+func (e Edit) String() string {
+	switch e.Operation {
+	case EditReplaceSection, EditInsertAfterHeading:
+		return fmt.Sprintf("%s '%s'", e.Operation, normalizeHeading(e.Heading))
+	default:
+		return string(e.Operation)
+	}
+}
+
+// ApplyEdit applies an edit to a memory body, without frontmatter
+func ApplyEdit(body string, edit Edit) (string, error) {
+	switch edit.Operation {
+	case EditAppend:
+		trimmed := strings.TrimRight(body, "\n")
+		if trimmed == "" {
+			return ensureNewline(edit.Content), nil
+		}
+		return trimmed + "\n\n" + ensureNewline(edit.Content), nil
+	case EditPrepend:
+		if strings.TrimSpace(body) == "" {
+			return ensureNewline(edit.Content), nil
+		}
+		return strings.TrimRight(edit.Content, "\n") + "\n\n" + body, nil
+	case EditStrReplace:
+		if edit.Old == "" {
+			return "", fmt.Errorf("old_string is required for str_replace")
+		}
+		switch count := strings.Count(body, edit.Old); count {
+		case 0:
+			return "", fmt.Errorf("old_string was not found in the memory")
+		case 1:
+			return strings.Replace(body, edit.Old, edit.Content, 1), nil
+		default:
+			return "", fmt.Errorf("old_string occurs %d times in the memory; include more surrounding text to make it unique", count)
+		}
+	case EditReplaceSection, EditInsertAfterHeading:
+		lines := strings.SplitAfter(body, "\n")
+		section, err := findSection(body, lines, edit.Heading)
+		if err != nil {
+			return "", err
+		}
+
+		head := strings.Join(lines[:section.bodyStart], "")
+		var tail string
+		if edit.Operation == EditReplaceSection {
+			tail = strings.Join(lines[section.end:], "")
+		} else {
+			tail = strings.TrimLeft(strings.Join(lines[section.bodyStart:], ""), "\n")
+		}
+		result := ensureNewline(head)
+		if content := strings.Trim(edit.Content, "\n"); content != "" {
+			result += "\n" + content + "\n"
+		}
+		if tail != "" {
+			result += "\n" + tail
+		}
+		return result, nil
+	default:
+		return "", fmt.Errorf("unknown edit operation '%s'", edit.Operation)
+	}
+}
+
+// Edit applies a partial edit to a memory, keeping its frontmatter. The validate callback checks
+// the complete document before it is written.
+func (es *EnhancedStore) Edit(name string, edit Edit, validate func(content string) error) error {
+	return es.Modify(name, func(content string) (string, error) {
+		if err := es.checkVersion(name, content, edit.IfMatch); err != nil {
+			return "", err
+		}
+
+		fm, body, err := ParseDocument(content)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse memory document: %w", err)
+		}
+
+		body, err = ApplyEdit(body, edit)
+		if err != nil {
+			return "", err
+		}
+
+		updated, err := FormatDocument(fm, body)
+		if err != nil {
+			return "", fmt.Errorf("failed to format document: %w", err)
+		}
+		if validate != nil {
+			if err := validate(updated); err != nil {
+				return "", err
+			}
+		}
+		return updated, nil
+	})
+}
+
+// section is a heading of a memory body and the lines it spans: from the heading to the next
+// heading of the same or a higher level
+type section struct {
+	title     string
+	level     int
+	start     int // Line of the heading
+	bodyStart int // Line after the heading
+	end       int // Line after the section
+}
+
+// findSection finds the section with the given heading, ignoring case and leading #s
+func findSection(body string, lines []string, heading string) (section, error) {
+	heading = normalizeHeading(heading)
+	if heading == "" {
+		return section{}, fmt.Errorf("heading is required")
+	}
+
+	sections := bodySections(body, lines)
+	var matches []section
+	for _, s := range sections {
+		if strings.EqualFold(s.title, heading) {
+			matches = append(matches, s)
+		}
+	}
+
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		titles := make([]string, len(sections))
+		for i, s := range sections {
+			titles[i] = strings.Repeat("#", s.level) + " " + s.title
+		}
+		if len(titles) == 0 {
+			return section{}, fmt.Errorf("heading '%s' not found; the memory has no headings", heading)
+		}
+		return section{}, fmt.Errorf("heading '%s' not found; available headings: %s", heading, strings.Join(titles, ", "))
+	default:
+		return section{}, fmt.Errorf("heading '%s' occurs %d times in the memory; use str_replace instead", heading, len(matches))
+	}
+}
+
+// bodySections returns the sections of a body. The headings come from the markdown AST; they are
+// located in the source by scanning its lines outside fenced code blocks.
+func bodySections(body string, lines []string) []section {
+	var headings []section
+	for _, node := range parser.New().Parse([]byte(body)).GetChildren() {
+		if heading, ok := node.(*ast.Heading); ok {
+			headings = append(headings, section{title: headingText(heading), level: heading.Level})
+		}
+	}
+
+	var sections []section
+	next := 0
+	for _, candidate := range headingLines(lines) {
+		if next < len(headings) && candidate.level == headings[next].level && candidate.title == headings[next].title {
+			sections = append(sections, candidate)
+			next++
+		}
+	}
+
+	for i := range sections {
+		sections[i].end = len(lines)
+		for _, later := range sections[i+1:] {
+			if later.level <= sections[i].level {
+				sections[i].end = later.start
+				break
+			}
+		}
+	}
+	return sections
+}
+
+// headingLines finds the lines that look like ATX or setext headings outside fenced code blocks
+func headingLines(lines []string) []section {
+	var candidates []section
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if indent > 3 {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		if level := len(trimmed) - len(strings.TrimLeft(trimmed, "#")); level >= 1 && level <= 6 &&
+			(len(trimmed) == level || trimmed[level] == ' ' || trimmed[level] == '\t') {
+			candidates = append(candidates, section{title: parseHeading(trimmed), level: level, start: i, bodyStart: i + 1})
+			continue
+		}
+
+		if i == 0 || trimmed == "" || (strings.Trim(trimmed, "=") != "" && strings.Trim(trimmed, "-") != "") {
+			continue
+		}
+		if previous := strings.TrimSpace(lines[i-1]); previous != "" {
+			level := 1
+			if trimmed[0] == '-' {
+				level = 2
+			}
+			candidates = append(candidates, section{title: parseHeading(previous + "\n" + trimmed), level: level, start: i - 1, bodyStart: i + 1})
+		}
+	}
+	return candidates
+}
+
+// parseHeading returns the text of the heading in a markdown snippet
+func parseHeading(source string) string {
+	for _, node := range parser.New().Parse([]byte(source)).GetChildren() {
+		if heading, ok := node.(*ast.Heading); ok {
+			return headingText(heading)
+		}
+	}
+	return ""
+}
+
+// headingText returns the plain text of a heading
+func headingText(heading *ast.Heading) string {
+	var text strings.Builder
+	ast.WalkFunc(heading, func(node ast.Node, entering bool) ast.WalkStatus {
+		if entering {
+			switch leaf := node.(type) {
+			case *ast.Text:
+				text.Write(leaf.Literal)
+			case *ast.Code:
+				text.Write(leaf.Literal)
+			}
+		}
+		return ast.GoToNext
+	})
+	return strings.TrimSpace(text.String())
+}
+
+// normalizeHeading strips the leading #s of a heading given with its markdown syntax
+func normalizeHeading(heading string) string {
+	return strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(heading), "#"))
+}
+
+// ensureNewline makes sure non-empty text ends with a newline
+func ensureNewline(text string) string {
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		return text + "\n"
+	}
+	return text
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyEdit(t *testing.T) {
+	body := "Intro.\n\n## Setup\n\nInstall it.\n\n### Details\n\nFlags.\n\n```sh\n# Usage\n```\n\n## Usage\n\nRun it.\n"
+
+	tests := []struct {
+		name string
+		edit Edit
+		want string
+		err  string
+	}{
+		{
+			name: "append",
+			edit: Edit{Operation: EditAppend, Content: "Outro."},
+			want: body + "\nOutro.\n",
+		},
+		{
+			name: "prepend",
+			edit: Edit{Operation: EditPrepend, Content: "# Title\n"},
+			want: "# Title\n\n" + body,
+		},
+		{
+			name: "replace section with subsections",
+			edit: Edit{Operation: EditReplaceSection, Heading: "## setup", Content: "Use the installer."},
+			want: "Intro.\n\n## Setup\n\nUse the installer.\n\n## Usage\n\nRun it.\n",
+		},
+		{
+			name: "replace last section ignoring code blocks",
+			edit: Edit{Operation: EditReplaceSection, Heading: "Usage", Content: "Run `make`.\n"},
+			want: strings.TrimSuffix(body, "Run it.\n") + "Run `make`.\n",
+		},
+		{
+			name: "insert after heading",
+			edit: Edit{Operation: EditInsertAfterHeading, Heading: "Details", Content: "New flag."},
+			want: strings.Replace(body, "### Details\n\nFlags.", "### Details\n\nNew flag.\n\nFlags.", 1),
+		},
+		{
+			name: "unknown heading",
+			edit: Edit{Operation: EditReplaceSection, Heading: "Missing"},
+			err:  "available headings: ## Setup, ### Details, ## Usage",
+		},
+		{
+			name: "unique string",
+			edit: Edit{Operation: EditStrReplace, Old: "Install it.", Content: "Install it with go install."},
+			want: strings.Replace(body, "Install it.", "Install it with go install.", 1),
+		},
+		{
+			name: "ambiguous string",
+			edit: Edit{Operation: EditStrReplace, Old: "it.", Content: "x"},
+			err:  "occurs 2 times",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyEdit(body, tt.edit)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("ApplyEdit() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyEdit() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ApplyEdit() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
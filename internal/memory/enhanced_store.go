@@ -1,7 +1,6 @@
 package memory
 
 import (
-	"crypto/sha256"
 	"fmt"
 	"log"
 	"path/filepath"
@@ -9,6 +8,7 @@ import (
 
 	"github.com/jcdickinson/simplemem/internal/config"
 	"github.com/jcdickinson/simplemem/internal/db"
+	"github.com/jcdickinson/simplemem/internal/embeddings"
 	"github.com/jcdickinson/simplemem/internal/rag"
 )
 
@@ -18,6 +18,14 @@ type EnhancedStore struct {
 	db          *db.DB
 	ragProcessor *rag.Processor
 	dbPath      string
+	relations   *RelationSchema
+	duplicates  config.DuplicatesConfig
+	gitConfig   config.GitConfig
+	git         *GitCommitter // nil unless git integration is enabled
+	watcher     *Watcher      // nil unless watching for external changes
+	syncLocks   nameLocks     // Serializes database changes per memory
+	reconcile   bool          // Remove memories whose files are gone when initialized
+	trash       config.TrashConfig
 }
 
 // NewEnhancedStore creates a new enhanced store with RAG capabilities
@@ -42,11 +50,39 @@ func NewEnhancedStoreWithDBPath(basePath string, cfg *config.Config, dbPath stri
 		return nil, fmt.Errorf("failed to initialize RAG processor: %w", err)
 	}
 
+	names, err := NewNameRules(cfg.Naming)
+	if err != nil {
+		return nil, fmt.Errorf("invalid naming configuration: %w", err)
+	}
+	basicStore.names = names
+	basicStore.history = newHistoryPolicy(cfg.History)
+
+	relations, err := NewRelationSchema(cfg.Relations)
+	if err != nil {
+		return nil, fmt.Errorf("invalid relations configuration: %w", err)
+	}
+
+	duplicates := cfg.Duplicates
+	if duplicates.Threshold <= 0 {
+		duplicates.Threshold = defaultDuplicateThreshold
+	}
+	if duplicates.TextThreshold <= 0 {
+		duplicates.TextThreshold = defaultDuplicateTextThreshold
+	}
+	if duplicates.ShingleSize <= 0 {
+		duplicates.ShingleSize = defaultShingleSize
+	}
+
 	return &EnhancedStore{
 		Store:        basicStore,
 		db:           database,
 		ragProcessor: ragProcessor,
 		dbPath:       dbPath,
+		relations:    relations,
+		duplicates:   duplicates,
+		gitConfig:    cfg.Git,
+		reconcile:    cfg.Reconcile.OnStartup,
+		trash:        cfg.Trash,
 	}, nil
 }
 
@@ -56,6 +92,15 @@ func (es *EnhancedStore) Initialize() error {
 		return fmt.Errorf("failed to initialize file store: %w", err)
 	}
 
+	if es.gitConfig.Enabled {
+		committer, err := NewGitCommitter(es.basePath, es.gitConfig)
+		if err != nil {
+			log.Printf("Warning: git integration disabled: %v", err)
+		} else {
+			es.git = committer
+		}
+	}
+
 	// Validate RAG configuration
 	if err := es.ragProcessor.ValidateConfiguration(); err != nil {
 		log.Printf("Warning: RAG configuration validation failed: %v", err)
@@ -67,6 +112,17 @@ func (es *EnhancedStore) Initialize() error {
 		log.Printf("Warning: failed to sync files to database: %v", err)
 	}
 
+	// Remove memories whose files were deleted while the store was closed
+	if es.reconcile {
+		if report, err := es.Reconcile(false); err != nil {
+			log.Printf("Warning: failed to reconcile database: %v", err)
+		} else if len(report.Removed) > 0 {
+			log.Printf("[RECONCILE] Removed %d memories whose files were deleted: %s", len(report.Removed), strings.Join(report.Removed, ", "))
+		}
+	}
+
+	es.autoPurgeTrash()
+
 	// Process any pending memories
 	if err := es.ragProcessor.ProcessAllPendingMemories(); err != nil {
 		log.Printf("Warning: failed to process pending memories: %v", err)
@@ -105,10 +161,36 @@ func (es *EnhancedStore) Update(name, content string) error {
 	return nil
 }
 
-// Delete deletes a memory from both file system and database
+// Modify changes a memory's content under the write lock and reprocesses it with RAG
+func (es *EnhancedStore) Modify(name string, modify func(current string) (string, error)) error {
+	if err := es.Store.Modify(name, modify); err != nil {
+		return err
+	}
+
+	if err := es.syncMemoryToDatabase(name); err != nil {
+		log.Printf("Warning: failed to sync memory to database: %v", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a memory from both file system and database, moving it to the trash when the
+// trash is enabled
 func (es *EnhancedStore) Delete(name string) error {
+	return es.DeleteIfMatch(name, "")
+}
+
+// DeleteIfMatch deletes a memory like Delete, failing with a ConflictError unless its current
+// content matches the expected version
+func (es *EnhancedStore) DeleteIfMatch(name, ifMatch string) error {
+	if es.trash.Enabled {
+		return es.moveToTrash(name, ifMatch)
+	}
+
+	defer es.syncLocks.lock(name)()
+
 	// Delete from file system first
-	if err := es.Store.Delete(name); err != nil {
+	if err := es.Store.DeleteIfMatch(name, ifMatch); err != nil {
 		return err
 	}
 
@@ -121,49 +203,54 @@ func (es *EnhancedStore) Delete(name string) error {
 	return nil
 }
 
-// SearchSemantic performs semantic search using embeddings
-func (es *EnhancedStore) SearchSemantic(query string, limit int) ([]MemoryInfo, []float32, error) {
-	return es.SearchSemanticWithTags(query, nil, false, limit)
-}
-
-// SearchSemanticWithTags performs semantic search using embeddings with tag filtering
-func (es *EnhancedStore) SearchSemanticWithTags(query string, tagFilters map[string]string, requireAll bool, limit int) ([]MemoryInfo, []float32, error) {
-	// Convert tag filters to db format
-	var dbTagFilters []db.TagFilter
-	for key, value := range tagFilters {
-		filter := db.TagFilter{
-			Key:        key,
-			Value:      value,
-			CheckValue: value != "", // If value is empty, only check presence
-		}
-		dbTagFilters = append(dbTagFilters, filter)
+// ResolveName resolves a memory name like Store.ResolveName, additionally suggesting the
+// semantically nearest memories when nothing matches
+func (es *EnhancedStore) ResolveName(name string) (string, string, error) {
+	resolved, method, err := es.Store.ResolveName(name)
+	notFound, ok := err.(*NotFoundError)
+	if !ok {
+		return resolved, method, err
 	}
 
-	memories, similarities, err := es.ragProcessor.SearchSimilarMemoriesWithTags(query, dbTagFilters, requireAll, limit)
-	if err != nil {
-		return nil, nil, fmt.Errorf("semantic search failed: %w", err)
+	// Names are usually slugs, so search with the separators turned back into words
+	query := strings.NewReplacer("-", " ", "_", " ").Replace(notFound.Name)
+	memories, _, searchErr := es.SearchSemantic(query, maxSuggestions)
+	if searchErr != nil {
+		log.Printf("[RESOLVE] Semantic suggestions unavailable for '%s': %v", notFound.Name, searchErr)
+		return "", "", notFound
 	}
 
-	// Convert db.Memory to MemoryInfo
-	var results []MemoryInfo
+	seen := make(map[string]bool)
+	for _, suggestion := range notFound.Suggestions {
+		seen[suggestion] = true
+	}
 	for _, memory := range memories {
-		// Parse frontmatter from content
-		fm, body, err := ParseDocument(memory.Content)
-		if err != nil {
-			log.Printf("Warning: failed to parse document %s: %v", memory.Name, err)
-			fm = &Frontmatter{}
-			body = memory.Content
+		if len(notFound.Suggestions) >= maxSuggestions {
+			break
+		}
+		if !seen[memory.Name] {
+			seen[memory.Name] = true
+			notFound.Suggestions = append(notFound.Suggestions, memory.Name)
 		}
+	}
 
-		results = append(results, MemoryInfo{
-			Name:        memory.Name,
-			Content:     memory.Content,
-			Body:        body,
-			Frontmatter: fm,
-		})
+	return "", "", notFound
+}
+
+// SearchSemantic performs semantic search using embeddings
+func (es *EnhancedStore) SearchSemantic(query string, limit int) ([]MemoryInfo, []float32, error) {
+	return es.SearchSemanticWithTags(query, nil, false, "", limit)
+}
+
+// SearchSemanticWithTags performs semantic search using embeddings with tag filtering, restricted
+// to a namespace when one is given
+func (es *EnhancedStore) SearchSemanticWithTags(query string, tagFilters map[string]string, requireAll bool, namespace string, limit int) ([]MemoryInfo, []float32, error) {
+	memories, similarities, err := es.ragProcessor.SearchSimilarMemoriesWithTags(query, toDBTagFilters(tagFilters), requireAll, NormalizeNamespace(namespace), limit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("semantic search failed: %w", err)
 	}
 
-	return results, similarities, nil
+	return toMemoryInfos(memories), similarities, nil
 }
 
 // GetSemanticBacklinks returns memories that are semantically similar to the given memory
@@ -173,31 +260,12 @@ func (es *EnhancedStore) GetSemanticBacklinks(name string, minSimilarity float32
 		return nil, nil, fmt.Errorf("failed to get semantic backlinks: %w", err)
 	}
 
-	// Convert db.Memory to MemoryInfo
-	var results []MemoryInfo
-	for _, memory := range memories {
-		// Parse frontmatter from content
-		fm, body, err := ParseDocument(memory.Content)
-		if err != nil {
-			log.Printf("Warning: failed to parse document %s: %v", memory.Name, err)
-			fm = &Frontmatter{}
-			body = memory.Content
-		}
-
-		results = append(results, MemoryInfo{
-			Name:        memory.Name,
-			Content:     memory.Content,
-			Body:        body,
-			Frontmatter: fm,
-		})
-	}
-
-	return results, similarities, nil
+	return toMemoryInfos(memories), similarities, nil
 }
 
 
 // matchesTagFilters checks if a memory matches the given tag filters
-func (es *EnhancedStore) matchesTagFilters(memory *MemoryInfo, tagFilters map[string]string, requireAll bool) bool {
+func matchesTagFilters(memory *MemoryInfo, tagFilters map[string]string, requireAll bool) bool {
 	if len(tagFilters) == 0 {
 		return true
 	}
@@ -225,65 +293,98 @@ func (es *EnhancedStore) matchesTagFilters(memory *MemoryInfo, tagFilters map[st
 
 // GetEnhancedBacklinks retrieves and reranks both explicit and semantic backlinks as markdown
 func (es *EnhancedStore) GetEnhancedBacklinks(memoryName string, query string, limit int) (string, error) {
+	backlinks, err := es.GetEnhancedBacklinkResults(memoryName, query, limit)
+	if err != nil {
+		return "", err
+	}
+
+	return es.FormatBacklinksMarkdown(memoryName, backlinks, query), nil
+}
+
+// GetEnhancedBacklinkResults retrieves and reranks both explicit and semantic backlinks
+func (es *EnhancedStore) GetEnhancedBacklinkResults(memoryName string, query string, limit int) ([]rag.BacklinkResult, error) {
 	backlinks, err := es.ragProcessor.GetEnhancedBacklinks(memoryName, query, limit)
 	if err != nil {
-		return "", fmt.Errorf("failed to get enhanced backlinks: %w", err)
+		return nil, fmt.Errorf("failed to get enhanced backlinks: %w", err)
 	}
 
-	return es.ragProcessor.FormatBacklinksAsMarkdown(memoryName, backlinks, query), nil
+	return backlinks, nil
+}
+
+// FormatBacklinksMarkdown formats backlink results as markdown
+func (es *EnhancedStore) FormatBacklinksMarkdown(memoryName string, backlinks []rag.BacklinkResult, query string) string {
+	return es.ragProcessor.FormatBacklinksAsMarkdown(memoryName, backlinks, query)
 }
 
 // SearchSemanticMarkdown performs semantic search and returns results as markdown
 func (es *EnhancedStore) SearchSemanticMarkdown(query string, limit int) (string, error) {
-	return es.SearchSemanticMarkdownWithTags(query, nil, false, limit)
+	return es.SearchSemanticMarkdownWithTags(query, nil, false, "", limit)
 }
 
 // SearchSemanticMarkdownWithTags performs semantic search with tag filtering and returns results as markdown
-func (es *EnhancedStore) SearchSemanticMarkdownWithTags(query string, tagFilters map[string]string, requireAll bool, limit int) (string, error) {
-	memories, similarities, err := es.SearchSemanticWithTags(query, tagFilters, requireAll, limit)
+func (es *EnhancedStore) SearchSemanticMarkdownWithTags(query string, tagFilters map[string]string, requireAll bool, namespace string, limit int) (string, error) {
+	memories, similarities, err := es.SearchSemanticWithTags(query, tagFilters, requireAll, namespace, limit)
 	if err != nil {
 		return "", err
 	}
 
+	return FormatSemanticSearchMarkdown(query, tagFilters, requireAll, namespace, memories, similarities), nil
+}
+
+// FormatSemanticSearchMarkdown formats semantic search results as markdown
+func FormatSemanticSearchMarkdown(query string, tagFilters map[string]string, requireAll bool, namespace string, memories []MemoryInfo, similarities []float32) string {
+	searchDesc := fmt.Sprintf("'%s'", query) + describeTagFilters(tagFilters, requireAll) + describeNamespace(namespace)
 	if len(memories) == 0 {
-		searchDesc := fmt.Sprintf("'%s'", query)
-		if len(tagFilters) > 0 {
-			var tagDesc []string
-			for key, value := range tagFilters {
-				if value == "" {
-					tagDesc = append(tagDesc, key)
-				} else {
-					tagDesc = append(tagDesc, fmt.Sprintf("%s:%s", key, value))
-				}
-			}
-			connector := "any of"
-			if requireAll {
-				connector = "all of"
-			}
-			searchDesc += fmt.Sprintf(" with %s tags [%s]", connector, strings.Join(tagDesc, ", "))
-		}
-		return fmt.Sprintf("No memories found for semantic search: %s", searchDesc), nil
+		return fmt.Sprintf("No memories found for semantic search: %s", searchDesc)
 	}
 
 	var md strings.Builder
-	if len(tagFilters) > 0 {
-		var tagDesc []string
-		for key, value := range tagFilters {
-			if value == "" {
-				tagDesc = append(tagDesc, key)
-			} else {
-				tagDesc = append(tagDesc, fmt.Sprintf("%s:%s", key, value))
-			}
-		}
-		connector := "any of"
-		if requireAll {
-			connector = "all of"
-		}
-		md.WriteString(fmt.Sprintf("# Semantic search results for '%s' with %s tags [%s]\n\n", query, connector, strings.Join(tagDesc, ", ")))
-	} else {
-		md.WriteString(fmt.Sprintf("# Semantic search results for '%s'\n\n", query))
+	md.WriteString(fmt.Sprintf("# Semantic search results for %s\n\n", searchDesc))
+	writeSimilarityResults(&md, memories, similarities)
+
+	return md.String()
+}
+
+// FindSimilar finds memories similar to an existing memory using its stored embeddings (no API calls)
+func (es *EnhancedStore) FindSimilar(name string, mode string, tagFilters map[string]string, requireAll bool, namespace string, exclude []string, limit int) ([]MemoryInfo, []float32, error) {
+	memories, similarities, err := es.ragProcessor.FindSimilarToMemory(name, mode, toDBTagFilters(tagFilters), requireAll, NormalizeNamespace(namespace), exclude, limit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("similarity search failed: %w", err)
+	}
+
+	return toMemoryInfos(memories), similarities, nil
+}
+
+// FindSimilarMarkdown finds memories similar to an existing memory and returns results as markdown
+func (es *EnhancedStore) FindSimilarMarkdown(name string, mode string, tagFilters map[string]string, requireAll bool, namespace string, exclude []string, limit int) (string, error) {
+	memories, similarities, err := es.FindSimilar(name, mode, tagFilters, requireAll, namespace, exclude, limit)
+	if err != nil {
+		return "", err
+	}
+
+	return FormatSimilarMarkdown(name, mode, tagFilters, requireAll, namespace, memories, similarities), nil
+}
+
+// FormatSimilarMarkdown formats find-similar results as markdown
+func FormatSimilarMarkdown(name string, mode string, tagFilters map[string]string, requireAll bool, namespace string, memories []MemoryInfo, similarities []float32) string {
+	if mode == "" {
+		mode = "centroid"
 	}
+	searchDesc := fmt.Sprintf("'%s' (%s)", name, mode) + describeTagFilters(tagFilters, requireAll) + describeNamespace(namespace)
 
+	if len(memories) == 0 {
+		return fmt.Sprintf("No similar memories found for %s", searchDesc)
+	}
+
+	var md strings.Builder
+	md.WriteString(fmt.Sprintf("# Memories similar to %s\n\n", searchDesc))
+	writeSimilarityResults(&md, memories, similarities)
+
+	return md.String()
+}
+
+// writeSimilarityResults renders ranked memories with their similarity scores as markdown sections
+func writeSimilarityResults(md *strings.Builder, memories []MemoryInfo, similarities []float32) {
 	for i, memory := range memories {
 		md.WriteString(fmt.Sprintf("## %d. %s\n", i+1, memory.Name))
 		
@@ -292,11 +393,7 @@ func (es *EnhancedStore) SearchSemanticMarkdownWithTags(query string, tagFilters
 		}
 		
 		// Create snippet from body (first 300 chars)
-		snippet := memory.Body
-		if len(snippet) > 300 {
-			snippet = snippet[:300] + "..."
-		}
-		md.WriteString(fmt.Sprintf("**Snippet:**\n%s\n\n", snippet))
+		md.WriteString(fmt.Sprintf("**Snippet:**\n%s\n\n", Snippet(memory.Body, 300)))
 		
 		md.WriteString(fmt.Sprintf("**Similarity:** %.3f\n\n", similarities[i]))
 		
@@ -305,26 +402,211 @@ func (es *EnhancedStore) SearchSemanticMarkdownWithTags(query string, tagFilters
 		}
 		
 		if len(memory.Frontmatter.Tags) > 0 {
-			var tagsList []string
-			for tag, value := range memory.Frontmatter.Tags {
-				if value == true {
-					tagsList = append(tagsList, tag)
-				} else {
-					tagsList = append(tagsList, fmt.Sprintf("%s: %v", tag, value))
-				}
-			}
-			md.WriteString(fmt.Sprintf("**Tags:** %s\n\n", strings.Join(tagsList, ", ")))
+			md.WriteString(fmt.Sprintf("**Tags:** %s\n\n", formatTagList(memory.Frontmatter.Tags)))
 		}
 		
 		md.WriteString("---\n\n")
 	}
+}
+
+// Snippet truncates a memory body to at most maxLen bytes, marking truncation with an ellipsis
+func Snippet(body string, maxLen int) string {
+	if len(body) > maxLen {
+		return body[:maxLen] + "..."
+	}
+	return body
+}
+
+// toMemoryInfos converts database memories to MemoryInfo, parsing their frontmatter
+func toMemoryInfos(memories []db.Memory) []MemoryInfo {
+	var results []MemoryInfo
+	for _, memory := range memories {
+		results = append(results, toMemoryInfo(memory))
+	}
+	return results
+}
+
+// toMemoryInfo converts a database memory to MemoryInfo, parsing its frontmatter
+func toMemoryInfo(memory db.Memory) MemoryInfo {
+	fm, body, err := ParseDocument(memory.Content)
+	if err != nil {
+		log.Printf("Warning: failed to parse document %s: %v", memory.Name, err)
+		fm = &Frontmatter{}
+		body = memory.Content
+	}
+
+	return MemoryInfo{
+		Name:        memory.Name,
+		Content:     memory.Content,
+		Body:        body,
+		Frontmatter: fm,
+	}
+}
+
+// FusedMemoryInfo is a multi-query search hit annotated with the queries that matched it
+type FusedMemoryInfo struct {
+	MemoryInfo
+	Score          float32
+	MatchedQueries []string
+	Similarities   map[string]float32
+}
+
+// SearchSemanticMultiWithTags runs several semantic queries at once and fuses the results
+func (es *EnhancedStore) SearchSemanticMultiWithTags(queries []string, tagFilters map[string]string, requireAll bool, namespace string, limit int) ([]FusedMemoryInfo, error) {
+	fused, err := es.ragProcessor.SearchMultipleQueriesWithTags(queries, toDBTagFilters(tagFilters), requireAll, NormalizeNamespace(namespace), limit)
+	if err != nil {
+		return nil, fmt.Errorf("multi-query search failed: %w", err)
+	}
+
+	var results []FusedMemoryInfo
+	for _, result := range fused {
+		results = append(results, FusedMemoryInfo{
+			MemoryInfo:     toMemoryInfo(result.Memory),
+			Score:          result.Score,
+			MatchedQueries: result.MatchedQueries,
+			Similarities:   result.Similarities,
+		})
+	}
+
+	return results, nil
+}
+
+// SearchSemanticMultiMarkdownWithTags runs a multi-query search and returns the fused results as markdown
+func (es *EnhancedStore) SearchSemanticMultiMarkdownWithTags(queries []string, tagFilters map[string]string, requireAll bool, namespace string, limit int) (string, error) {
+	results, err := es.SearchSemanticMultiWithTags(queries, tagFilters, requireAll, namespace, limit)
+	if err != nil {
+		return "", err
+	}
+
+	return FormatMultiSearchMarkdown(queries, tagFilters, requireAll, namespace, results), nil
+}
+
+// FormatMultiSearchMarkdown formats fused multi-query search results as markdown
+func FormatMultiSearchMarkdown(queries []string, tagFilters map[string]string, requireAll bool, namespace string, results []FusedMemoryInfo) string {
+	quoted := make([]string, len(queries))
+	for i, q := range queries {
+		quoted[i] = fmt.Sprintf("'%s'", q)
+	}
+	searchDesc := strings.Join(quoted, ", ") + describeTagFilters(tagFilters, requireAll) + describeNamespace(namespace)
+
+	if len(results) == 0 {
+		return fmt.Sprintf("No memories found for semantic search: %s", searchDesc)
+	}
+
+	var md strings.Builder
+	md.WriteString(fmt.Sprintf("# Semantic search results for %s\n\n", searchDesc))
+
+	for i, result := range results {
+		md.WriteString(fmt.Sprintf("## %d. %s\n", i+1, result.Name))
+
+		if result.Frontmatter.Title != "" && result.Frontmatter.Title != result.Name {
+			md.WriteString(fmt.Sprintf("**Title:** %s\n\n", result.Frontmatter.Title))
+		}
+
+		// Create snippet from body (first 300 chars)
+		md.WriteString(fmt.Sprintf("**Snippet:**\n%s\n\n", Snippet(result.Body, 300)))
+
+		var matched []string
+		for _, q := range result.MatchedQueries {
+			matched = append(matched, fmt.Sprintf("'%s' (%.3f)", q, result.Similarities[q]))
+		}
+		md.WriteString(fmt.Sprintf("**Matched queries:** %s\n\n", strings.Join(matched, ", ")))
+		md.WriteString(fmt.Sprintf("**Fusion score:** %.4f\n\n", result.Score))
+
+		if result.Frontmatter.Description != "" {
+			md.WriteString(fmt.Sprintf("**Description:** %s\n\n", result.Frontmatter.Description))
+		}
+
+		if len(result.Frontmatter.Tags) > 0 {
+			md.WriteString(fmt.Sprintf("**Tags:** %s\n\n", formatTagList(result.Frontmatter.Tags)))
+		}
+
+		md.WriteString("---\n\n")
+	}
+
+	return md.String()
+}
 
-	return md.String(), nil
+// toDBTagFilters converts key:value tag filters to db format (an empty value only checks presence)
+func toDBTagFilters(tagFilters map[string]string) []db.TagFilter {
+	var dbTagFilters []db.TagFilter
+	for key, value := range tagFilters {
+		filter := db.TagFilter{
+			Key:        key,
+			Value:      value,
+			CheckValue: value != "", // If value is empty, only check presence
+		}
+		dbTagFilters = append(dbTagFilters, filter)
+	}
+	return dbTagFilters
 }
 
+// describeTagFilters renders tag filters for result headings, e.g. " with any of tags [todo, status:open]"
+func describeTagFilters(tagFilters map[string]string, requireAll bool) string {
+	if len(tagFilters) == 0 {
+		return ""
+	}
 
-// Close closes database connections
+	var tagDesc []string
+	for key, value := range tagFilters {
+		if value == "" {
+			tagDesc = append(tagDesc, key)
+		} else {
+			tagDesc = append(tagDesc, fmt.Sprintf("%s:%s", key, value))
+		}
+	}
+	connector := "any of"
+	if requireAll {
+		connector = "all of"
+	}
+	return fmt.Sprintf(" with %s tags [%s]", connector, strings.Join(tagDesc, ", "))
+}
+
+// describeNamespace renders a namespace filter for result headings, e.g. " in namespace 'project'"
+func describeNamespace(namespace string) string {
+	if namespace = NormalizeNamespace(namespace); namespace == "" {
+		return ""
+	}
+	return fmt.Sprintf(" in namespace '%s'", namespace)
+}
+
+// formatTagList renders tags as a comma-separated list, showing values for non-boolean tags
+func formatTagList(tags map[string]interface{}) string {
+	var tagsList []string
+	for tag, value := range tags {
+		if value == true {
+			tagsList = append(tagsList, tag)
+		} else {
+			tagsList = append(tagsList, fmt.Sprintf("%s: %v", tag, value))
+		}
+	}
+	return strings.Join(tagsList, ", ")
+}
+
+
+// QueryCacheStats returns the query embedding cache metrics of this session, and false if the
+// cache is disabled
+func (es *EnhancedStore) QueryCacheStats() (embeddings.CacheStats, bool) {
+	return es.ragProcessor.QueryCacheStats()
+}
+
+// Close persists caches and closes database connections
 func (es *EnhancedStore) Close() error {
+	if es.watcher != nil {
+		if err := es.watcher.Close(); err != nil {
+			log.Printf("Warning: failed to stop file watcher: %v", err)
+		}
+	}
+	if es.git != nil {
+		if err := es.git.Flush(); err != nil {
+			log.Printf("Warning: failed to commit pending memory changes: %v", err)
+		}
+	}
+	if es.ragProcessor != nil {
+		if err := es.ragProcessor.Close(); err != nil {
+			log.Printf("Warning: failed to save query cache: %v", err)
+		}
+	}
 	return es.db.Close()
 }
 
@@ -346,13 +628,21 @@ func (es *EnhancedStore) syncFilesToDatabase() error {
 
 // syncMemoryToDatabase syncs a single memory to the database
 func (es *EnhancedStore) syncMemoryToDatabase(name string) error {
+	return es.syncMemoryToDatabaseReusing(name, nil)
+}
+
+// syncMemoryToDatabaseReusing syncs a single memory to the database, reusing saved embeddings
+// where they still match its content
+func (es *EnhancedStore) syncMemoryToDatabaseReusing(name string, saved []db.Embedding) error {
+	defer es.syncLocks.lock(name)()
+
 	memInfo, err := es.Store.ReadWithMetadata(name)
 	if err != nil {
 		return fmt.Errorf("failed to read memory: %w", err)
 	}
 
 	// Calculate file hash for change detection
-	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(memInfo.Content)))
+	hash := ContentVersion(memInfo.Content)
 
 	// Check if memory exists in database and if it has changed
 	existing, err := es.db.GetMemory(name)
@@ -361,7 +651,11 @@ func (es *EnhancedStore) syncMemoryToDatabase(name string) error {
 	}
 
 	if existing != nil && existing.FileHash == hash {
-		// Memory hasn't changed, no need to update
+		// Memory hasn't changed, but links are still checked so databases created
+		// before links were tracked get backfilled (no write happens if they match)
+		if err := es.syncLinks(existing.ID, memInfo); err != nil {
+			log.Printf("Warning: failed to sync links for memory %s: %v", name, err)
+		}
 		return nil
 	}
 
@@ -390,8 +684,13 @@ func (es *EnhancedStore) syncMemoryToDatabase(name string) error {
 		log.Printf("Warning: failed to sync tags for memory %s: %v", name, err)
 	}
 
+	// Sync explicit links to database
+	if err := es.syncLinks(dbMemory.ID, memInfo); err != nil {
+		log.Printf("Warning: failed to sync links for memory %s: %v", name, err)
+	}
+
 	// Process with RAG if content changed
-	if err := es.ragProcessor.ProcessMemory(dbMemory); err != nil {
+	if err := es.ragProcessor.ProcessMemoryReusing(dbMemory, saved); err != nil {
 		log.Printf("Warning: failed to process memory %s with RAG: %v", name, err)
 	}
 
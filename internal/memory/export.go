@@ -0,0 +1,384 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jcdickinson/simplemem/internal/db"
+)
+
+// Graph export formats
+const (
+	ExportDOT     = "dot"
+	ExportGraphML = "graphml"
+	ExportMermaid = "mermaid"
+	ExportJSON    = "json"
+)
+
+// ExportFormats lists every graph export format
+var ExportFormats = []string{ExportDOT, ExportGraphML, ExportMermaid, ExportJSON}
+
+// Subgraph returns the nodes passing the filter's tag filters and the allowed edges between them
+func (g *Graph) Subgraph(filter GraphFilter) (*Graph, error) {
+	if err := filter.validate(g.EdgeTypes); err != nil {
+		return nil, err
+	}
+
+	var nodes []db.GraphNode
+	for _, node := range g.Nodes {
+		if filter.allowsNode(node) {
+			nodes = append(nodes, node)
+		}
+	}
+
+	var edges []db.GraphEdge
+	for _, edge := range g.Edges {
+		if filter.allowsEdge(edge) {
+			edges = append(edges, edge)
+		}
+	}
+
+	// NewGraph drops edges whose endpoints were filtered out
+	subgraph := NewGraph(nodes, edges)
+	subgraph.addEdgeTypes(g.EdgeTypes)
+	return subgraph, nil
+}
+
+// sortedNodes returns the graph's nodes ordered by name
+func (g *Graph) sortedNodes() []db.GraphNode {
+	nodes := make([]db.GraphNode, 0, len(g.Nodes))
+	for _, node := range g.Nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	return nodes
+}
+
+// clusters groups node names by the value of a tag key; nodes without the tag are left out
+func (g *Graph) clusters(key string) ([]string, map[string][]string) {
+	members := make(map[string][]string)
+	if key == "" {
+		return nil, members
+	}
+
+	for _, node := range g.sortedNodes() {
+		if value, ok := node.Tags[key]; ok {
+			members[value] = append(members[value], node.Name)
+		}
+	}
+
+	values := make([]string, 0, len(members))
+	for value := range members {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values, members
+}
+
+// nodeLabel returns the display label of a node
+func nodeLabel(node db.GraphNode) string {
+	if node.Title != "" {
+		return node.Title
+	}
+	return node.Name
+}
+
+// formatTags renders node tags as a stable "key=value, ..." string
+func formatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key+"="+tags[key])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ExportOptions controls a graph export
+type ExportOptions struct {
+	Format        string      // One of ExportFormats
+	ClusterBy     string      // Optional tag key to group nodes by
+	MinSimilarity float32     // Minimum similarity for semantic edges
+	Filter        GraphFilter // Tag and edge type filters
+}
+
+// ExportGraph loads the memory graph, applies the filters and renders it, returning the rendered
+// graph together with the exported subgraph
+func (es *EnhancedStore) ExportGraph(opts ExportOptions) (string, *Graph, error) {
+	graph, err := es.LoadGraph(opts.MinSimilarity)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load memory graph: %w", err)
+	}
+
+	subgraph, err := graph.Subgraph(opts.Filter)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rendered, err := RenderGraph(subgraph, opts.Format, opts.ClusterBy)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return rendered, subgraph, nil
+}
+
+// RenderGraph renders a graph in one of the export formats, grouping nodes by the value of
+// clusterBy when it is set
+func RenderGraph(g *Graph, format string, clusterBy string) (string, error) {
+	switch format {
+	case ExportDOT:
+		return exportDOT(g, clusterBy), nil
+	case ExportGraphML:
+		return exportGraphML(g, clusterBy)
+	case ExportMermaid:
+		return exportMermaid(g, clusterBy), nil
+	case ExportJSON:
+		return exportJSON(g, clusterBy)
+	default:
+		return "", fmt.Errorf("unknown export format '%s' (expected one of: %s)", format, strings.Join(ExportFormats, ", "))
+	}
+}
+
+// dotQuote quotes a string for Graphviz
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// exportDOT renders the graph in Graphviz DOT format
+func exportDOT(g *Graph, clusterBy string) string {
+	var out strings.Builder
+	out.WriteString("digraph memories {\n")
+	out.WriteString("  rankdir=LR;\n")
+	out.WriteString("  node [shape=box, style=rounded];\n")
+
+	writeNode := func(indent string, node db.GraphNode) {
+		out.WriteString(fmt.Sprintf("%s%s [label=%s", indent, dotQuote(node.Name), dotQuote(nodeLabel(node))))
+		if len(node.Tags) > 0 {
+			out.WriteString(fmt.Sprintf(", tooltip=%s", dotQuote(formatTags(node.Tags))))
+		}
+		out.WriteString("];\n")
+	}
+
+	values, members := g.clusters(clusterBy)
+	clustered := make(map[string]bool)
+	for i, value := range values {
+		out.WriteString(fmt.Sprintf("  subgraph cluster_%d {\n", i))
+		out.WriteString(fmt.Sprintf("    label=%s;\n", dotQuote(clusterBy+": "+value)))
+		for _, name := range members[value] {
+			writeNode("    ", g.Nodes[name])
+			clustered[name] = true
+		}
+		out.WriteString("  }\n")
+	}
+	for _, node := range g.sortedNodes() {
+		if !clustered[node.Name] {
+			writeNode("  ", node)
+		}
+	}
+
+	for _, edge := range g.Edges {
+		if edge.Type == EdgeSemantic {
+			out.WriteString(fmt.Sprintf("  %s -> %s [dir=none, style=dashed, label=\"%.2f\", weight=%.2f];\n",
+				dotQuote(edge.From), dotQuote(edge.To), edge.Weight, edge.Weight))
+		} else {
+			out.WriteString(fmt.Sprintf("  %s -> %s [label=%s];\n", dotQuote(edge.From), dotQuote(edge.To), dotQuote(edge.Type)))
+		}
+	}
+
+	out.WriteString("}\n")
+	return out.String()
+}
+
+// xmlEscape escapes text for use in XML content and attributes
+func xmlEscape(s string) (string, error) {
+	var buf bytes.Buffer
+	if err := xml.EscapeText(&buf, []byte(s)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// exportGraphML renders the graph in GraphML format
+func exportGraphML(g *Graph, clusterBy string) (string, error) {
+	var out strings.Builder
+	out.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	out.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	out.WriteString(`  <key id="title" for="node" attr.name="title" attr.type="string"/>` + "\n")
+	out.WriteString(`  <key id="tags" for="node" attr.name="tags" attr.type="string"/>` + "\n")
+	out.WriteString(`  <key id="cluster" for="node" attr.name="cluster" attr.type="string"/>` + "\n")
+	out.WriteString(`  <key id="type" for="edge" attr.name="type" attr.type="string"/>` + "\n")
+	out.WriteString(`  <key id="weight" for="edge" attr.name="weight" attr.type="double"/>` + "\n")
+	out.WriteString(`  <graph id="memories" edgedefault="directed">` + "\n")
+
+	data := func(key, value string) error {
+		escaped, err := xmlEscape(value)
+		if err != nil {
+			return err
+		}
+		out.WriteString(fmt.Sprintf("      <data key=\"%s\">%s</data>\n", key, escaped))
+		return nil
+	}
+
+	for _, node := range g.sortedNodes() {
+		id, err := xmlEscape(node.Name)
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(fmt.Sprintf("    <node id=\"%s\">\n", id))
+		if err := data("title", node.Title); err != nil {
+			return "", err
+		}
+		if len(node.Tags) > 0 {
+			if err := data("tags", formatTags(node.Tags)); err != nil {
+				return "", err
+			}
+		}
+		if value, ok := node.Tags[clusterBy]; ok && clusterBy != "" {
+			if err := data("cluster", value); err != nil {
+				return "", err
+			}
+		}
+		out.WriteString("    </node>\n")
+	}
+
+	for i, edge := range g.Edges {
+		from, err := xmlEscape(edge.From)
+		if err != nil {
+			return "", err
+		}
+		to, err := xmlEscape(edge.To)
+		if err != nil {
+			return "", err
+		}
+
+		directed := ""
+		if edge.Type == EdgeSemantic {
+			directed = ` directed="false"`
+		}
+		out.WriteString(fmt.Sprintf("    <edge id=\"e%d\" source=\"%s\" target=\"%s\"%s>\n", i, from, to, directed))
+		if err := data("type", edge.Type); err != nil {
+			return "", err
+		}
+		if err := data("weight", fmt.Sprintf("%.4f", edge.Weight)); err != nil {
+			return "", err
+		}
+		out.WriteString("    </edge>\n")
+	}
+
+	out.WriteString("  </graph>\n")
+	out.WriteString("</graphml>\n")
+	return out.String(), nil
+}
+
+// mermaidLabel escapes a label for a quoted Mermaid string
+func mermaidLabel(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s)
+}
+
+// exportMermaid renders the graph as a Mermaid flowchart. Memory names are not valid Mermaid
+// identifiers in general, so nodes get generated IDs and are labeled with their titles.
+func exportMermaid(g *Graph, clusterBy string) string {
+	nodes := g.sortedNodes()
+	ids := make(map[string]string, len(nodes))
+	for i, node := range nodes {
+		ids[node.Name] = fmt.Sprintf("n%d", i)
+	}
+
+	var out strings.Builder
+	out.WriteString("graph LR\n")
+
+	writeNode := func(indent string, node db.GraphNode) {
+		out.WriteString(fmt.Sprintf("%s%s[\"%s\"]\n", indent, ids[node.Name], mermaidLabel(nodeLabel(node))))
+	}
+
+	values, members := g.clusters(clusterBy)
+	clustered := make(map[string]bool)
+	for i, value := range values {
+		out.WriteString(fmt.Sprintf("  subgraph c%d[\"%s\"]\n", i, mermaidLabel(clusterBy+": "+value)))
+		for _, name := range members[value] {
+			writeNode("    ", g.Nodes[name])
+			clustered[name] = true
+		}
+		out.WriteString("  end\n")
+	}
+	for _, node := range nodes {
+		if !clustered[node.Name] {
+			writeNode("  ", node)
+		}
+	}
+
+	for _, edge := range g.Edges {
+		if edge.Type == EdgeSemantic {
+			out.WriteString(fmt.Sprintf("  %s -.-|%.2f| %s\n", ids[edge.From], edge.Weight, ids[edge.To]))
+		} else {
+			out.WriteString(fmt.Sprintf("  %s -->|%s| %s\n", ids[edge.From], edge.Type, ids[edge.To]))
+		}
+	}
+
+	return out.String()
+}
+
+// nodeLinkGraph is the JSON node-link format (compatible with networkx node_link_graph)
+type nodeLinkGraph struct {
+	Directed   bool           `json:"directed"`
+	Multigraph bool           `json:"multigraph"`
+	Graph      map[string]any `json:"graph"`
+	Nodes      []nodeLinkNode `json:"nodes"`
+	Links      []nodeLinkEdge `json:"links"`
+}
+
+type nodeLinkNode struct {
+	ID      string            `json:"id"`
+	Title   string            `json:"title,omitempty"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	Cluster string            `json:"cluster,omitempty"`
+}
+
+type nodeLinkEdge struct {
+	Source string  `json:"source"`
+	Target string  `json:"target"`
+	Type   string  `json:"type"`
+	Weight float32 `json:"weight"`
+}
+
+// exportJSON renders the graph in JSON node-link format
+func exportJSON(g *Graph, clusterBy string) (string, error) {
+	graph := nodeLinkGraph{
+		Directed:   true,
+		Multigraph: true,
+		Graph:      map[string]any{"name": "memories"},
+		Nodes:      []nodeLinkNode{},
+		Links:      []nodeLinkEdge{},
+	}
+	if clusterBy != "" {
+		graph.Graph["cluster_by"] = clusterBy
+	}
+
+	for _, node := range g.sortedNodes() {
+		entry := nodeLinkNode{ID: node.Name, Title: node.Title, Tags: node.Tags}
+		if clusterBy != "" {
+			entry.Cluster = node.Tags[clusterBy]
+		}
+		graph.Nodes = append(graph.Nodes, entry)
+	}
+	for _, edge := range g.Edges {
+		graph.Links = append(graph.Links, nodeLinkEdge{Source: edge.From, Target: edge.To, Type: edge.Type, Weight: edge.Weight})
+	}
+
+	data, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode graph: %w", err)
+	}
+	return string(data) + "\n", nil
+}
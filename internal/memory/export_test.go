@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestRenderGraph(t *testing.T) {
+	g, err := newTestGraph().Subgraph(GraphFilter{Tags: map[string]string{"project": ""}})
+	if err != nil {
+		t.Fatalf("Subgraph() error = %v", err)
+	}
+	if _, ok := g.Nodes["e"]; ok || len(g.Nodes) != 4 {
+		t.Fatalf("expected untagged node to be filtered out, got %v", g.Nodes)
+	}
+
+	dot, err := RenderGraph(g, ExportDOT, "project")
+	if err != nil {
+		t.Fatalf("RenderGraph(dot) error = %v", err)
+	}
+	for _, want := range []string{`subgraph cluster_0`, `label="project: x"`, `"a" -> "b" [label="wiki"]`, `"a" -> "d" [dir=none, style=dashed, label="0.80"`} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT output missing %q:\n%s", want, dot)
+		}
+	}
+
+	mermaid, err := RenderGraph(g, ExportMermaid, "project")
+	if err != nil {
+		t.Fatalf("RenderGraph(mermaid) error = %v", err)
+	}
+	for _, want := range []string{"graph LR", `subgraph c1["project: y"]`, "n0 -->|wiki| n1", "n0 -.-|0.80| n3"} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Mermaid output missing %q:\n%s", want, mermaid)
+		}
+	}
+
+	graphml, err := RenderGraph(g, ExportGraphML, "project")
+	if err != nil {
+		t.Fatalf("RenderGraph(graphml) error = %v", err)
+	}
+	var parsed struct {
+		Graph struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal([]byte(graphml), &parsed); err != nil {
+		t.Fatalf("GraphML output is not valid XML: %v", err)
+	}
+	if len(parsed.Graph.Nodes) != 4 || len(parsed.Graph.Edges) != 4 {
+		t.Errorf("expected 4 nodes and 4 edges in GraphML, got %d and %d", len(parsed.Graph.Nodes), len(parsed.Graph.Edges))
+	}
+
+	data, err := RenderGraph(g, ExportJSON, "project")
+	if err != nil {
+		t.Fatalf("RenderGraph(json) error = %v", err)
+	}
+	var nodeLink nodeLinkGraph
+	if err := json.Unmarshal([]byte(data), &nodeLink); err != nil {
+		t.Fatalf("JSON output is invalid: %v", err)
+	}
+	if len(nodeLink.Nodes) != 4 || nodeLink.Nodes[2].Cluster != "y" || len(nodeLink.Links) != 4 {
+		t.Errorf("unexpected node-link graph: %+v", nodeLink)
+	}
+
+	if _, err := RenderGraph(g, "svg", ""); err == nil {
+		t.Errorf("expected error for unknown format")
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package memory
+
+import "os"
+
+// lockFile is a no-op where advisory file locks are unavailable; writes are then only serialized
+// within one process
+func lockFile(file *os.File) error {
+	return nil
+}
+
+// syncDir is a no-op where directories cannot be synced; renames are durable on their own there
+func syncDir(dir string) error {
+	return nil
+}
@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/notes.md"
+
+	for _, content := range []string{"first\n", "second\n"} {
+		if err := writeFileAtomic(path, []byte(content), 0644); err != nil {
+			t.Fatalf("writeFileAtomic() error = %v", err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != content {
+			t.Errorf("content = %q, want %q", data, content)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected no temporary files to be left behind, got %d entries", len(entries))
+	}
+}
+
+func TestModifySerializesStores(t *testing.T) {
+	dir := t.TempDir()
+
+	// Separate stores have separate mutexes, like separate processes, so only the directory lock
+	// keeps their read-modify-write cycles from interleaving
+	first, second := NewStore(dir), NewStore(dir)
+	if err := first.Create("counter", "---\ntitle: Counter\n---\n0\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	increment := func(content string) (string, error) {
+		_, body, err := ParseDocument(content)
+		if err != nil {
+			return "", err
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(body))
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("---\ntitle: Counter\n---\n%d\n", n+1), nil
+	}
+
+	const rounds = 25
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*rounds)
+	for _, store := range []*Store{first, second} {
+		wg.Add(1)
+		go func(store *Store) {
+			defer wg.Done()
+			for range rounds {
+				errs <- store.Modify("counter", increment)
+			}
+		}(store)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Modify() error = %v", err)
+		}
+	}
+
+	info, err := first.ReadWithMetadata("counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(info.Body); got != strconv.Itoa(2*rounds) {
+		t.Errorf("counter = %s, want %d", got, 2*rounds)
+	}
+}
@@ -19,6 +19,7 @@ type Frontmatter struct {
 	Created     time.Time              `yaml:"created,omitempty"`
 	Modified    time.Time              `yaml:"modified,omitempty"`
 	Links       []string               `yaml:"links,omitempty"`
+	Relations   map[string][]string    `yaml:"relations,omitempty"` // Typed links, keyed by relation type
 	Metadata    map[string]interface{} `yaml:",inline"`
 }
 
@@ -102,6 +103,14 @@ func ParseDocument(content string) (*Frontmatter, string, error) {
 		if len(fm.Links) > 0 {
 			mergedFrontmatter.Links = append(mergedFrontmatter.Links, fm.Links...)
 		}
+		if len(fm.Relations) > 0 {
+			if mergedFrontmatter.Relations == nil {
+				mergedFrontmatter.Relations = make(map[string][]string)
+			}
+			for relation, targets := range fm.Relations {
+				mergedFrontmatter.Relations[relation] = append(mergedFrontmatter.Relations[relation], targets...)
+			}
+		}
 		if len(fm.Metadata) > 0 {
 			if mergedFrontmatter.Metadata == nil {
 				mergedFrontmatter.Metadata = make(map[string]interface{})
@@ -125,7 +134,7 @@ func ParseDocument(content string) (*Frontmatter, string, error) {
 // FormatDocument combines frontmatter and content into a complete document
 func FormatDocument(fm *Frontmatter, content string) (string, error) {
 	if fm == nil || (fm.Title == "" && fm.Description == "" && len(fm.Tags) == 0 && 
-		fm.Created.IsZero() && fm.Modified.IsZero() && len(fm.Links) == 0 && len(fm.Metadata) == 0) {
+		fm.Created.IsZero() && fm.Modified.IsZero() && len(fm.Links) == 0 && len(fm.Relations) == 0 && len(fm.Metadata) == 0) {
 		// No frontmatter to add
 		return content, nil
 	}
@@ -201,3 +210,57 @@ func (fm *Frontmatter) UpdateTimestamps(isNew bool) {
 	fm.Modified = now
 }
 
+
+// SetMetadata sets a metadata property from a decoded JSON value. Properties backed by dedicated
+// fields (links, relations) are validated and stored there; anything else goes into Metadata.
+func (fm *Frontmatter) SetMetadata(key string, value interface{}) error {
+	switch key {
+	case "links":
+		links, err := stringList(value)
+		if err != nil {
+			return fmt.Errorf("metadata.links %w", err)
+		}
+		fm.Links = links
+	case "relations":
+		relationsMap, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("metadata.relations must be an object mapping relation types to memory names")
+		}
+		fm.Relations = make(map[string][]string, len(relationsMap))
+		for relation, targets := range relationsMap {
+			names, err := stringList(targets)
+			if err != nil {
+				return fmt.Errorf("metadata.relations.%s %w", relation, err)
+			}
+			fm.Relations[relation] = names
+		}
+	default:
+		if fm.Metadata == nil {
+			fm.Metadata = make(map[string]interface{})
+		}
+		fm.Metadata[key] = value
+	}
+	return nil
+}
+
+// stringList converts a string or an array of strings to a slice
+func stringList(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []string:
+		return v, nil
+	case []interface{}:
+		list := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("must contain only strings")
+			}
+			list[i] = s
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("must be a string or an array of strings")
+	}
+}
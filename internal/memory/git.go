@@ -0,0 +1,296 @@
+package memory
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jcdickinson/simplemem/internal/config"
+)
+
+// GitChange describes a change to a memory, recorded for the next automatic commit
+type GitChange struct {
+	Tool   string // Tool that made the change, e.g. update_memory
+	Name   string // Memory that changed
+	Detail string // Optional summary such as the changed tags
+}
+
+// String formats the change as a line of a commit message
+func (c GitChange) String() string {
+	line := c.Tool + ": " + c.Name
+	if c.Detail != "" {
+		line += " (" + c.Detail + ")"
+	}
+	return line
+}
+
+// GitCommit is a commit that touched the memory directory
+type GitCommit struct {
+	Hash    string    `json:"hash"`
+	Time    time.Time `json:"time"`
+	Author  string    `json:"author"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body,omitempty" jsonschema:"description=Rest of the commit message; batched commits list each change"`
+}
+
+// GitCommitter commits changes to the memory directory with git. Changes are batched until none
+// have arrived for the quiet window, so a burst of edits becomes a single commit.
+type GitCommitter struct {
+	dir   string
+	quiet time.Duration
+	env   []string
+
+	mu      sync.Mutex // Guards pending and timer
+	pending []GitChange
+	timer   *time.Timer
+
+	commitMu sync.Mutex // Serializes commits
+}
+
+// gitPathspec limits git to the memory directory, leaving out the lock file, the trash and the
+// revision history, which git supersedes
+var gitPathspec = []string{"--", ".", ":(exclude)" + historyDir, ":(exclude)" + trashDir, ":(exclude)" + lockFileName}
+
+// NewGitCommitter creates a committer for a memory directory, initializing a repository there if
+// the directory is not already inside one
+func NewGitCommitter(dir string, cfg config.GitConfig) (*GitCommitter, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("failed to find git: %w", err)
+	}
+
+	g := &GitCommitter{
+		dir:   dir,
+		quiet: time.Duration(cfg.QuietSeconds) * time.Second,
+	}
+	if cfg.AuthorName != "" {
+		g.env = append(g.env, "GIT_AUTHOR_NAME="+cfg.AuthorName, "GIT_COMMITTER_NAME="+cfg.AuthorName)
+	}
+	if cfg.AuthorEmail != "" {
+		g.env = append(g.env, "GIT_AUTHOR_EMAIL="+cfg.AuthorEmail, "GIT_COMMITTER_EMAIL="+cfg.AuthorEmail)
+	}
+
+	if out, err := g.git("rev-parse", "--is-inside-work-tree"); err != nil || strings.TrimSpace(out) != "true" {
+		if _, err := g.git("init"); err != nil {
+			return nil, fmt.Errorf("failed to initialize git repository: %w", err)
+		}
+		log.Printf("[GIT] Initialized repository in %s", dir)
+	}
+
+	return g, nil
+}
+
+// Record queues a change and restarts the quiet window. Without a quiet window the change is
+// committed immediately.
+func (g *GitCommitter) Record(change GitChange) {
+	g.mu.Lock()
+	g.pending = append(g.pending, change)
+	if g.quiet <= 0 {
+		g.mu.Unlock()
+		g.commitPending()
+		return
+	}
+	if g.timer != nil {
+		g.timer.Stop()
+	}
+	g.timer = time.AfterFunc(g.quiet, g.commitPending)
+	g.mu.Unlock()
+}
+
+// Flush commits any queued changes without waiting for the quiet window
+func (g *GitCommitter) Flush() error {
+	g.mu.Lock()
+	if g.timer != nil {
+		g.timer.Stop()
+		g.timer = nil
+	}
+	g.mu.Unlock()
+
+	return g.commit()
+}
+
+// commitPending commits queued changes, logging failures since there is no caller to report them to
+func (g *GitCommitter) commitPending() {
+	if err := g.commit(); err != nil {
+		log.Printf("[GIT] Failed to commit memory changes: %v", err)
+	}
+}
+
+// commit stages and commits everything that changed in the memory directory, describing the
+// queued changes in the message
+func (g *GitCommitter) commit() error {
+	g.commitMu.Lock()
+	defer g.commitMu.Unlock()
+
+	g.mu.Lock()
+	changes := g.pending
+	g.pending = nil
+	g.mu.Unlock()
+
+	if _, err := g.git(append([]string{"add", "--all"}, gitPathspec...)...); err != nil {
+		return fmt.Errorf("failed to stage memory changes: %w", err)
+	}
+	status, err := g.git(append([]string{"status", "--porcelain"}, gitPathspec...)...)
+	if err != nil {
+		return fmt.Errorf("failed to check for memory changes: %w", err)
+	}
+	if strings.TrimSpace(status) == "" {
+		return nil
+	}
+
+	// Only the memory directory is committed, so anything else staged in a project repository stays staged
+	message := gitCommitMessage(changes)
+	if _, err := g.git(append([]string{"commit", "--quiet", "--message", message}, gitPathspec...)...); err != nil {
+		return fmt.Errorf("failed to commit memory changes: %w", err)
+	}
+
+	subject, _, _ := strings.Cut(message, "\n")
+	log.Printf("[GIT] Committed: %s", subject)
+	return nil
+}
+
+// gitCommitMessage describes a batch of changes. A single change becomes the subject; a batch is
+// summarized in the subject and listed in the body.
+func gitCommitMessage(changes []GitChange) string {
+	switch len(changes) {
+	case 0:
+		return "Update memories"
+	case 1:
+		return changes[0].String()
+	}
+
+	var tools []string
+	seen := make(map[string]bool)
+	for _, change := range changes {
+		if !seen[change.Tool] {
+			seen[change.Tool] = true
+			tools = append(tools, change.Tool)
+		}
+	}
+	sort.Strings(tools)
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("Update memories: %d changes (%s)\n\n", len(changes), strings.Join(tools, ", ")))
+	for _, change := range changes {
+		message.WriteString("- " + change.String() + "\n")
+	}
+	return message.String()
+}
+
+// Log returns up to limit commits that touched a memory, newest first, following renames. An empty
+// name returns the commits of the whole memory directory.
+func (g *GitCommitter) Log(name string, limit int) ([]GitCommit, error) {
+	args := []string{"log", "--format=%H%x1f%aI%x1f%an%x1f%s%x1f%b%x1e"}
+	if limit > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", limit))
+	}
+	if name != "" {
+		if err := checkName(name); err != nil {
+			return nil, err
+		}
+		args = append(args, "--follow", "--", strings.TrimSuffix(name, ".md")+".md")
+	} else {
+		args = append(args, gitPathspec...)
+	}
+
+	out, err := g.git(args...)
+	if err != nil {
+		// A repository without commits has no history yet
+		if strings.Contains(err.Error(), "does not have any commits") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read git log: %w", err)
+	}
+
+	var commits []GitCommit
+	for record := range strings.SplitSeq(out, "\x1e") {
+		fields := strings.Split(strings.TrimLeft(record, "\n"), "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+		commit := GitCommit{Hash: fields[0], Author: fields[2], Subject: fields[3], Body: strings.TrimSpace(fields[4])}
+		if t, err := time.Parse(time.RFC3339, fields[1]); err == nil {
+			commit.Time = t
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// git runs a git command in the memory directory and returns its output
+func (g *GitCommitter) git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = g.dir
+	cmd.Env = append(os.Environ(), g.env...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+// FormatGitLogMarkdown formats the commits of a memory, or of all memories when name is empty
+func FormatGitLogMarkdown(name string, commits []GitCommit) string {
+	subject := "all memories"
+	if name != "" {
+		subject = name
+	}
+
+	var md strings.Builder
+	md.WriteString(fmt.Sprintf("# Git history of %s\n\n", subject))
+	if len(commits) == 0 {
+		md.WriteString("No commits yet.\n")
+		return md.String()
+	}
+
+	for _, commit := range commits {
+		hash := commit.Hash
+		if len(hash) > 10 {
+			hash = hash[:10]
+		}
+		md.WriteString(fmt.Sprintf("- **%s** %s by %s: %s\n", hash, commit.Time.Local().Format("2006-01-02 15:04:05"), commit.Author, commit.Subject))
+		for line := range strings.SplitSeq(commit.Body, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				md.WriteString("  " + line + "\n")
+			}
+		}
+	}
+
+	return md.String()
+}
+
+// GitEnabled reports whether changes are committed to git
+func (es *EnhancedStore) GitEnabled() bool {
+	return es.git != nil
+}
+
+// RecordChange queues a change made by a tool for the next automatic commit, if git integration
+// is enabled
+func (es *EnhancedStore) RecordChange(tool, name, detail string) {
+	if es.git != nil {
+		es.git.Record(GitChange{Tool: tool, Name: name, Detail: detail})
+	}
+}
+
+// GitLog returns the commits that touched a memory, or all memories when name is empty. Queued
+// changes are committed first so the log is current.
+func (es *EnhancedStore) GitLog(name string, limit int) ([]GitCommit, error) {
+	if es.git == nil {
+		return nil, fmt.Errorf("git integration is not enabled")
+	}
+	if err := es.git.Flush(); err != nil {
+		log.Printf("[GIT] Failed to commit memory changes: %v", err)
+	}
+	return es.git.Log(name, limit)
+}
@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jcdickinson/simplemem/internal/config"
+)
+
+func TestGitCommitter(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// The memory directory lives inside a project repository with unrelated staged work
+	project := t.TempDir()
+	if out, err := exec.Command("git", "-C", project, "init", "--quiet").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(project, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "-C", project, "add", "main.go").CombinedOutput(); err != nil {
+		t.Fatalf("git add: %v: %s", err, out)
+	}
+
+	store := NewStore(filepath.Join(project, ".memories"))
+	store.history = &historyPolicy{}
+	if err := store.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGitCommitter(store.basePath, config.GitConfig{AuthorName: "Test", AuthorEmail: "test@example.com"})
+	if err != nil {
+		t.Fatalf("NewGitCommitter() error = %v", err)
+	}
+
+	// Changes within the quiet window are batched into one commit
+	g.quiet = time.Hour
+	if err := store.Create("notes", "---\ntitle: Notes\n---\nFirst draft.\n"); err != nil {
+		t.Fatal(err)
+	}
+	g.Record(GitChange{Tool: "create_memory", Name: "notes"})
+	if err := store.Create("todo", "---\ntitle: Todo\n---\nShip it.\n"); err != nil {
+		t.Fatal(err)
+	}
+	g.Record(GitChange{Tool: "change_tag", Name: "todo", Detail: "'status' set to done"})
+	if commits, _ := g.Log("", 0); len(commits) != 0 {
+		t.Fatalf("expected no commits before the quiet window ends, got %d", len(commits))
+	}
+	if err := g.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	// Without a quiet window each change is committed immediately
+	g.quiet = 0
+	if err := store.Delete("notes"); err != nil {
+		t.Fatal(err)
+	}
+	g.Record(GitChange{Tool: "delete_memory", Name: "notes"})
+
+	commits, err := g.Log("", 0)
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("expected 2 commits, got %+v", commits)
+	}
+	if commits[0].Subject != "delete_memory: notes" || commits[0].Author != "Test" {
+		t.Errorf("unexpected latest commit %+v", commits[0])
+	}
+	if commits[1].Subject != "Update memories: 2 changes (change_tag, create_memory)" ||
+		!strings.Contains(commits[1].Body, "- change_tag: todo ('status' set to done)") {
+		t.Errorf("unexpected batched commit %+v", commits[1])
+	}
+
+	// Deleted memories keep their log
+	if commits, err := g.Log("notes", 0); err != nil || len(commits) != 2 {
+		t.Errorf("Log(notes) = %+v, %v", commits, err)
+	}
+
+	// The revision history and unrelated staged files are left out of the commits
+	files, err := exec.Command("git", "-C", project, "ls-files").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(files)); got != ".memories/todo.md\nmain.go" {
+		t.Errorf("tracked files = %q", got)
+	}
+	staged, err := exec.Command("git", "-C", project, "diff", "--cached", "--name-only").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(staged)) != "main.go" {
+		t.Errorf("expected main.go to stay staged, got %q", staged)
+	}
+}
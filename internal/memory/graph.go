@@ -0,0 +1,391 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jcdickinson/simplemem/internal/db"
+)
+
+// Edge types of the memory graph
+const (
+	EdgeWiki        = "wiki"
+	EdgeMarkdown    = "markdown"
+	EdgeFrontmatter = "frontmatter"
+	EdgeSemantic    = "semantic"
+)
+
+// EdgeTypes lists the link edge types, in display order. Typed relations add their own edge types.
+var EdgeTypes = []string{EdgeWiki, EdgeMarkdown, EdgeFrontmatter, EdgeSemantic}
+
+// Traversal directions for explicit links; semantic edges are always undirected
+const (
+	DirectionBoth     = "both"
+	DirectionOutbound = "outbound"
+	DirectionInbound  = "inbound"
+)
+
+// Graph is an in-memory view of the memory link graph
+type Graph struct {
+	Nodes     map[string]db.GraphNode
+	Edges     []db.GraphEdge
+	EdgeTypes []string         // Edge types that filters may name
+	adjacency map[string][]int // Indices into Edges touching each node
+}
+
+// GraphFilter restricts which nodes and edges a traversal may use
+type GraphFilter struct {
+	EdgeTypes  []string          // Edge types to follow (default: all)
+	Direction  string            // Direction to follow explicit links (default: both)
+	Tags       map[string]string // Tag filters for nodes; empty values only check for presence
+	RequireAll bool              // Require all tag filters to match instead of any
+	MaxNodes   int               // Maximum number of nodes to return (0 means unlimited)
+}
+
+// GraphNeighbor is a node reached by a traversal
+type GraphNeighbor struct {
+	db.GraphNode
+	Depth int
+}
+
+// Neighborhood is the result of an N-hop traversal
+type Neighborhood struct {
+	Nodes     []GraphNeighbor
+	Edges     []db.GraphEdge // Edges between returned nodes that pass the filter
+	Truncated bool           // True if MaxNodes stopped the traversal early
+}
+
+// GraphPath is a path between two memories
+type GraphPath struct {
+	Nodes []string
+	Edges []db.GraphEdge // Edges[i] connects Nodes[i] and Nodes[i+1]
+}
+
+// NewGraph builds a graph, dropping duplicate edges and edges to unknown nodes
+func NewGraph(nodes []db.GraphNode, edges []db.GraphEdge) *Graph {
+	g := &Graph{
+		Nodes:     make(map[string]db.GraphNode, len(nodes)),
+		adjacency: make(map[string][]int),
+	}
+	for _, node := range nodes {
+		g.Nodes[node.Name] = node
+	}
+
+	type edgeKey struct{ from, to, edgeType string }
+	seen := make(map[edgeKey]bool)
+	for _, edge := range edges {
+		if _, ok := g.Nodes[edge.From]; !ok {
+			continue
+		}
+		if _, ok := g.Nodes[edge.To]; !ok {
+			continue
+		}
+
+		key := edgeKey{edge.From, edge.To, edge.Type}
+		if edge.Type == EdgeSemantic && edge.To < edge.From {
+			key = edgeKey{edge.To, edge.From, edge.Type}
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		g.Edges = append(g.Edges, edge)
+	}
+
+	// Sort for deterministic traversal order
+	sort.SliceStable(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Type < b.Type
+	})
+
+	for i, edge := range g.Edges {
+		g.adjacency[edge.From] = append(g.adjacency[edge.From], i)
+		g.adjacency[edge.To] = append(g.adjacency[edge.To], i)
+	}
+
+	g.addEdgeTypes(EdgeTypes)
+	for _, edge := range g.Edges {
+		g.addEdgeTypes([]string{edge.Type})
+	}
+
+	return g
+}
+
+// addEdgeTypes registers edge types that filters may name
+func (g *Graph) addEdgeTypes(edgeTypes []string) {
+	for _, edgeType := range edgeTypes {
+		if !containsString(g.EdgeTypes, edgeType) {
+			g.EdgeTypes = append(g.EdgeTypes, edgeType)
+		}
+	}
+}
+
+// LoadGraph builds the memory graph from the database, including semantic edges with at least
+// minSimilarity
+func (es *EnhancedStore) LoadGraph(minSimilarity float32) (*Graph, error) {
+	nodes, err := es.db.GetGraphNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	edges, err := es.db.GetGraphEdges(minSimilarity)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := NewGraph(nodes, edges)
+	graph.addEdgeTypes(es.relations.Types())
+	return graph, nil
+}
+
+// validate checks the filter's edge types against the known types, and its direction
+func (f GraphFilter) validate(edgeTypes []string) error {
+	for _, edgeType := range f.EdgeTypes {
+		if !containsString(edgeTypes, edgeType) {
+			return fmt.Errorf("unknown edge type '%s' (expected one of: %s)", edgeType, strings.Join(edgeTypes, ", "))
+		}
+	}
+
+	switch f.Direction {
+	case "", DirectionBoth, DirectionOutbound, DirectionInbound:
+		return nil
+	default:
+		return fmt.Errorf("unknown direction '%s' (expected %s, %s or %s)", f.Direction, DirectionBoth, DirectionOutbound, DirectionInbound)
+	}
+}
+
+// allowsEdge reports whether an edge type may be followed
+func (f GraphFilter) allowsEdge(edge db.GraphEdge) bool {
+	return len(f.EdgeTypes) == 0 || containsString(f.EdgeTypes, edge.Type)
+}
+
+// allowsNode reports whether a node matches the tag filters
+func (f GraphFilter) allowsNode(node db.GraphNode) bool {
+	if len(f.Tags) == 0 {
+		return true
+	}
+
+	matchCount := 0
+	for key, value := range f.Tags {
+		if nodeValue, exists := node.Tags[key]; exists && (value == "" || nodeValue == value) {
+			matchCount++
+		}
+	}
+
+	if f.RequireAll {
+		return matchCount == len(f.Tags)
+	}
+	return matchCount > 0
+}
+
+// neighbors returns the edges that can be followed from a node, with the node at the other end
+func (g *Graph) neighbors(name string, filter GraphFilter) ([]string, []db.GraphEdge) {
+	var names []string
+	var edges []db.GraphEdge
+
+	for _, i := range g.adjacency[name] {
+		edge := g.Edges[i]
+		if !filter.allowsEdge(edge) {
+			continue
+		}
+
+		var other string
+		switch {
+		case edge.From == name && (edge.Type == EdgeSemantic || filter.Direction != DirectionInbound):
+			other = edge.To
+		case edge.To == name && (edge.Type == EdgeSemantic || filter.Direction != DirectionOutbound):
+			other = edge.From
+		default:
+			continue
+		}
+
+		names = append(names, other)
+		edges = append(edges, edge)
+	}
+
+	return names, edges
+}
+
+// Neighborhood returns the memories within hops edges of start. Nodes that fail the tag filter
+// are neither returned nor traversed through; the start node is always included.
+func (g *Graph) Neighborhood(start string, hops int, filter GraphFilter) (*Neighborhood, error) {
+	if err := filter.validate(g.EdgeTypes); err != nil {
+		return nil, err
+	}
+	startNode, ok := g.Nodes[start]
+	if !ok {
+		return nil, fmt.Errorf("memory %s not found in graph", start)
+	}
+
+	result := &Neighborhood{Nodes: []GraphNeighbor{{GraphNode: startNode, Depth: 0}}}
+	depths := map[string]int{start: 0}
+	frontier := []string{start}
+
+	for depth := 1; depth <= hops && len(frontier) > 0 && !result.Truncated; depth++ {
+		var next []string
+		for _, name := range frontier {
+			others, _ := g.neighbors(name, filter)
+			for _, other := range others {
+				if _, visited := depths[other]; visited || !filter.allowsNode(g.Nodes[other]) {
+					continue
+				}
+				if filter.MaxNodes > 0 && len(result.Nodes) >= filter.MaxNodes {
+					result.Truncated = true
+					break
+				}
+				depths[other] = depth
+				result.Nodes = append(result.Nodes, GraphNeighbor{GraphNode: g.Nodes[other], Depth: depth})
+				next = append(next, other)
+			}
+			if result.Truncated {
+				break
+			}
+		}
+		frontier = next
+	}
+
+	result.Edges = []db.GraphEdge{}
+	for _, edge := range g.Edges {
+		_, fromIncluded := depths[edge.From]
+		_, toIncluded := depths[edge.To]
+		if fromIncluded && toIncluded && filter.allowsEdge(edge) {
+			result.Edges = append(result.Edges, edge)
+		}
+	}
+
+	return result, nil
+}
+
+// ShortestPath finds a path with the fewest edges between two memories, or nil if none exists.
+// Intermediate nodes must pass the tag filter; the endpoints always qualify.
+func (g *Graph) ShortestPath(from, to string, filter GraphFilter) (*GraphPath, error) {
+	if err := filter.validate(g.EdgeTypes); err != nil {
+		return nil, err
+	}
+	for _, name := range []string{from, to} {
+		if _, ok := g.Nodes[name]; !ok {
+			return nil, fmt.Errorf("memory %s not found in graph", name)
+		}
+	}
+
+	if from == to {
+		return &GraphPath{Nodes: []string{from}, Edges: []db.GraphEdge{}}, nil
+	}
+
+	visited := map[string]*pathStep{from: nil}
+	frontier := []string{from}
+
+	for len(frontier) > 0 {
+		var next []string
+		for _, name := range frontier {
+			others, edges := g.neighbors(name, filter)
+			for i, other := range others {
+				if _, seen := visited[other]; seen {
+					continue
+				}
+				if other != to && !filter.allowsNode(g.Nodes[other]) {
+					continue
+				}
+				visited[other] = &pathStep{prev: name, edge: edges[i]}
+				if other == to {
+					return buildPath(visited, from, to), nil
+				}
+				next = append(next, other)
+			}
+		}
+		frontier = next
+	}
+
+	return nil, nil
+}
+
+// pathStep records how a node was first reached during a breadth-first search
+type pathStep struct {
+	prev string
+	edge db.GraphEdge
+}
+
+// buildPath walks back from the target through the BFS predecessors
+func buildPath(visited map[string]*pathStep, from, to string) *GraphPath {
+	path := &GraphPath{Nodes: []string{to}, Edges: []db.GraphEdge{}}
+	for current := to; current != from; current = visited[current].prev {
+		path.Nodes = append([]string{visited[current].prev}, path.Nodes...)
+		path.Edges = append([]db.GraphEdge{visited[current].edge}, path.Edges...)
+	}
+	return path
+}
+
+// containsString reports whether a slice contains a value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// describeEdge formats an edge from the point of view of a path or listing
+func describeEdge(edge db.GraphEdge) string {
+	if edge.Type == EdgeSemantic {
+		return fmt.Sprintf("%s ↔ %s (semantic, %.2f)", edge.From, edge.To, edge.Weight)
+	}
+	return fmt.Sprintf("%s → %s (%s)", edge.From, edge.To, edge.Type)
+}
+
+// FormatNeighborhoodMarkdown formats a neighborhood traversal as markdown
+func FormatNeighborhoodMarkdown(start string, hops int, neighborhood *Neighborhood) string {
+	var md strings.Builder
+	md.WriteString(fmt.Sprintf("# Graph neighborhood of '%s' (%d hops)\n\n", start, hops))
+	md.WriteString(fmt.Sprintf("%d memories, %d edges", len(neighborhood.Nodes), len(neighborhood.Edges)))
+	if neighborhood.Truncated {
+		md.WriteString(" (truncated at node limit)")
+	}
+	md.WriteString("\n")
+
+	for depth, current := 0, 0; current < len(neighborhood.Nodes); depth++ {
+		md.WriteString(fmt.Sprintf("\n## Depth %d\n", depth))
+		for ; current < len(neighborhood.Nodes) && neighborhood.Nodes[current].Depth == depth; current++ {
+			node := neighborhood.Nodes[current]
+			md.WriteString(fmt.Sprintf("- **%s**", node.Name))
+			if node.Title != "" && node.Title != node.Name {
+				md.WriteString(fmt.Sprintf(" - %s", node.Title))
+			}
+			md.WriteString("\n")
+		}
+	}
+
+	if len(neighborhood.Edges) > 0 {
+		md.WriteString("\n## Edges\n")
+		for _, edge := range neighborhood.Edges {
+			md.WriteString(fmt.Sprintf("- %s\n", describeEdge(edge)))
+		}
+	}
+
+	return md.String()
+}
+
+// FormatPathMarkdown formats a shortest path as markdown
+func FormatPathMarkdown(from, to string, path *GraphPath) string {
+	var md strings.Builder
+	md.WriteString(fmt.Sprintf("# Shortest path from '%s' to '%s'\n\n", from, to))
+
+	if path == nil {
+		md.WriteString("No path found with the given filters.\n")
+		return md.String()
+	}
+
+	md.WriteString(fmt.Sprintf("%d hops: %s\n\n", len(path.Edges), strings.Join(path.Nodes, " → ")))
+	for _, edge := range path.Edges {
+		md.WriteString(fmt.Sprintf("- %s\n", describeEdge(edge)))
+	}
+
+	return md.String()
+}
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jcdickinson/simplemem/internal/db"
+)
+
+func newTestGraph() *Graph {
+	nodes := []db.GraphNode{
+		{Name: "a", Tags: map[string]string{"project": "x"}},
+		{Name: "b", Tags: map[string]string{"project": "x"}},
+		{Name: "c", Tags: map[string]string{"project": "y"}},
+		{Name: "d", Tags: map[string]string{"project": "x"}},
+		{Name: "e", Tags: map[string]string{}},
+	}
+	edges := []db.GraphEdge{
+		{From: "a", To: "b", Type: EdgeWiki, Weight: 1},
+		{From: "a", To: "b", Type: EdgeWiki, Weight: 1}, // Duplicate link
+		{From: "b", To: "c", Type: EdgeMarkdown, Weight: 1},
+		{From: "d", To: "c", Type: EdgeWiki, Weight: 1},
+		{From: "a", To: "d", Type: EdgeSemantic, Weight: 0.8},
+		{From: "a", To: "missing", Type: EdgeWiki, Weight: 1},
+	}
+	return NewGraph(nodes, edges)
+}
+
+func neighborNames(n *Neighborhood) []string {
+	var names []string
+	for _, node := range n.Nodes {
+		names = append(names, node.Name)
+	}
+	return names
+}
+
+func TestNeighborhood(t *testing.T) {
+	g := newTestGraph()
+	if len(g.Edges) != 4 {
+		t.Fatalf("expected duplicate and dangling edges to be dropped, got %+v", g.Edges)
+	}
+
+	tests := []struct {
+		name   string
+		hops   int
+		filter GraphFilter
+		want   []string
+	}{
+		{name: "one hop", hops: 1, filter: GraphFilter{}, want: []string{"a", "b", "d"}},
+		{name: "two hops", hops: 2, filter: GraphFilter{}, want: []string{"a", "b", "d", "c"}},
+		{name: "explicit only", hops: 3, filter: GraphFilter{EdgeTypes: []string{EdgeWiki, EdgeMarkdown}}, want: []string{"a", "b", "c", "d"}},
+		{name: "wiki only", hops: 3, filter: GraphFilter{EdgeTypes: []string{EdgeWiki}}, want: []string{"a", "b"}},
+		{name: "outbound", hops: 3, filter: GraphFilter{Direction: DirectionOutbound, EdgeTypes: []string{EdgeWiki, EdgeMarkdown}}, want: []string{"a", "b", "c"}},
+		{name: "tag filter", hops: 3, filter: GraphFilter{Tags: map[string]string{"project": "x"}}, want: []string{"a", "b", "d"}},
+		{name: "node cap", hops: 3, filter: GraphFilter{MaxNodes: 2}, want: []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := g.Neighborhood("a", tt.hops, tt.filter)
+			if err != nil {
+				t.Fatalf("Neighborhood() error = %v", err)
+			}
+			if got := neighborNames(n); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := g.Neighborhood("a", 1, GraphFilter{EdgeTypes: []string{"bogus"}}); err == nil {
+		t.Errorf("expected error for unknown edge type")
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	g := newTestGraph()
+
+	path, err := g.ShortestPath("a", "c", GraphFilter{})
+	if err != nil {
+		t.Fatalf("ShortestPath() error = %v", err)
+	}
+	if !reflect.DeepEqual(path.Nodes, []string{"a", "b", "c"}) || len(path.Edges) != 2 {
+		t.Errorf("unexpected path: %+v", path)
+	}
+
+	// Avoiding b forces the route through the semantic edge to d
+	path, err = g.ShortestPath("a", "c", GraphFilter{Tags: map[string]string{"project": "x"}, EdgeTypes: []string{EdgeSemantic, EdgeWiki}})
+	if err != nil {
+		t.Fatalf("ShortestPath() error = %v", err)
+	}
+	if path == nil || !reflect.DeepEqual(path.Nodes, []string{"a", "d", "c"}) {
+		t.Errorf("unexpected path: %+v", path)
+	}
+
+	path, err = g.ShortestPath("a", "e", GraphFilter{})
+	if err != nil || path != nil {
+		t.Errorf("expected no path to e, got %+v (err: %v)", path, err)
+	}
+}
@@ -0,0 +1,408 @@
+package memory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jcdickinson/simplemem/internal/config"
+)
+
+// historyDir is the directory, relative to the memory directory, that revisions are kept in
+const historyDir = ".history"
+
+// Revision actions
+const (
+	RevisionCreated    = "created"
+	RevisionUpdated    = "updated"
+	RevisionRelinked   = "relinked"    // Links rewritten by rename_memory or merge_memories
+	RevisionMerged     = "merged"      // Another memory merged in by merge_memories
+	RevisionRestored   = "restored"    // Restored from an earlier revision
+	RevisionRolledBack = "rolled_back" // Put back after a failed multi-file change
+	RevisionExternal   = "external"    // Content simplemem did not write, e.g. edited by hand or predating history
+)
+
+// revisionTimeFormat is the timestamp format of revision file names
+const revisionTimeFormat = "20060102T150405.000Z"
+
+// revisionFileRegex matches revision file names: number, timestamp and action
+var revisionFileRegex = regexp.MustCompile(`^(\d+)-(\d{8}T\d{6}\.\d{3}Z)-([a-z_]+)\.md$`)
+
+// historyPolicy controls how many revisions are kept
+type historyPolicy struct {
+	maxRevisions int           // 0 keeps all
+	maxAge       time.Duration // 0 keeps revisions regardless of age
+}
+
+// newHistoryPolicy creates a history policy from the configuration, or nil if history is disabled
+func newHistoryPolicy(cfg config.HistoryConfig) *historyPolicy {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &historyPolicy{
+		maxRevisions: cfg.MaxRevisions,
+		maxAge:       time.Duration(cfg.MaxAgeDays) * 24 * time.Hour,
+	}
+}
+
+// Revision is a stored version of a memory
+type Revision struct {
+	Number int       `json:"revision"`
+	Time   time.Time `json:"time"`
+	Action string    `json:"action" jsonschema:"description=How the revision came about: created, updated, relinked, merged, restored, rolled_back or external (written outside simplemem)"`
+	Hash   string    `json:"hash" jsonschema:"description=First 12 hex digits of the SHA-256 of the revision's content"`
+	Size   int       `json:"size"`
+	file   string
+}
+
+// ErrHistoryDisabled is returned by history operations when history is not kept
+var ErrHistoryDisabled = errors.New("revision history is disabled")
+
+// History returns the revisions of a memory, newest first. It also works for deleted memories.
+func (s *Store) History(name string) ([]Revision, error) {
+	if s.history == nil {
+		return nil, ErrHistoryDisabled
+	}
+	name = strings.TrimSuffix(name, ".md")
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revisions, err := s.revisions(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, fmt.Errorf("memory %s has no revision history", name)
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Number > revisions[j].Number
+	})
+	return revisions, nil
+}
+
+// HasHistory reports whether revisions are kept for a memory name, whether or not it still exists
+func (s *Store) HasHistory(name string) bool {
+	revisions, err := s.History(name)
+	return err == nil && len(revisions) > 0
+}
+
+// ReadRevision returns the content of a revision of a memory
+func (s *Store) ReadRevision(name string, number int) (string, error) {
+	revisions, err := s.History(name)
+	if err != nil {
+		return "", err
+	}
+
+	for _, revision := range revisions {
+		if revision.Number == number {
+			data, err := os.ReadFile(revision.file)
+			if err != nil {
+				return "", fmt.Errorf("failed to read revision %d of memory %s: %w", number, name, err)
+			}
+			return string(data), nil
+		}
+	}
+	return "", fmt.Errorf("memory %s has no revision %d (see memory_history)", strings.TrimSuffix(name, ".md"), number)
+}
+
+// historyPath returns the directory holding the revisions of a memory. Names are escaped so the
+// revisions of a memory and of memories in its namespace never share a directory.
+func (s *Store) historyPath(name string) string {
+	return filepath.Join(s.basePath, historyDir, url.PathEscape(strings.TrimSuffix(name, ".md")))
+}
+
+// revisions lists the revisions of a memory in no particular order. The caller must hold the lock.
+func (s *Store) revisions(name string) ([]Revision, error) {
+	dir := s.historyPath(name)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read revision history of %s: %w", name, err)
+	}
+
+	var revisions []Revision
+	for _, entry := range entries {
+		match := revisionFileRegex.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		number, _ := strconv.Atoi(match[1])
+		timestamp, err := time.Parse(revisionTimeFormat, match[2])
+		if err != nil {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		revisions = append(revisions, Revision{
+			Number: number,
+			Time:   timestamp,
+			Action: match[3],
+			Hash:   contentHash(data),
+			Size:   len(data),
+			file:   file,
+		})
+	}
+	return revisions, nil
+}
+
+// recordRevision keeps a revision of a memory after a change, when history is enabled. original is
+// the content before the change (nil for new memories) and content the content after it (nil for
+// deletions). Content that was never recorded, because it was written outside simplemem or before
+// history was enabled, is kept first as an external revision. Failures are logged rather than
+// failing the change. The change is noted as the store's own write for the watcher whether or
+// not history is enabled. The caller must hold the lock.
+func (s *Store) recordRevision(name string, original, content []byte, action string) {
+	s.written.note(name, content)
+	if s.history == nil {
+		return
+	}
+	name = strings.TrimSuffix(name, ".md")
+
+	revisions, err := s.revisions(name)
+	if err != nil {
+		log.Printf("[HISTORY] Warning: %v", err)
+		return
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Number < revisions[j].Number
+	})
+
+	latest := ""
+	next := 1
+	if len(revisions) > 0 {
+		latest = revisions[len(revisions)-1].Hash
+		next = revisions[len(revisions)-1].Number + 1
+	}
+
+	write := func(data []byte, action string) {
+		if err := s.writeRevision(name, next, data, action); err != nil {
+			log.Printf("[HISTORY] Warning: failed to keep revision %d of %s: %v", next, name, err)
+			return
+		}
+		latest = contentHash(data)
+		next++
+	}
+
+	if original != nil && contentHash(original) != latest {
+		write(original, RevisionExternal)
+	}
+	if content != nil && contentHash(content) != latest {
+		write(content, action)
+	}
+
+	s.pruneRevisions(name)
+}
+
+// writeRevision writes a revision file
+func (s *Store) writeRevision(name string, number int, content []byte, action string) error {
+	dir := s.historyPath(name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	file := fmt.Sprintf("%06d-%s-%s.md", number, time.Now().UTC().Format(revisionTimeFormat), action)
+	return writeFileAtomic(filepath.Join(dir, file), content, 0644)
+}
+
+// pruneRevisions applies the retention policy to the revisions of a memory. The newest revision
+// is always kept. The caller must hold the lock.
+func (s *Store) pruneRevisions(name string) {
+	revisions, err := s.revisions(name)
+	if err != nil || len(revisions) <= 1 {
+		return
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Number > revisions[j].Number
+	})
+
+	cutoff := time.Time{}
+	if s.history.maxAge > 0 {
+		cutoff = time.Now().Add(-s.history.maxAge)
+	}
+
+	for i, revision := range revisions[1:] {
+		tooMany := s.history.maxRevisions > 0 && i+1 >= s.history.maxRevisions
+		tooOld := !cutoff.IsZero() && revision.Time.Before(cutoff)
+		if tooMany || tooOld {
+			if err := os.Remove(revision.file); err != nil {
+				log.Printf("[HISTORY] Warning: failed to prune revision %d of %s: %v", revision.Number, name, err)
+			}
+		}
+	}
+}
+
+// moveHistory moves the revisions of a renamed memory to its new name. The caller must hold the lock.
+func (s *Store) moveHistory(oldName, newName string) {
+	if s.history == nil {
+		return
+	}
+
+	source, destination := s.historyPath(oldName), s.historyPath(newName)
+	if _, err := os.Stat(source); err != nil {
+		return
+	}
+	if _, err := os.Stat(destination); err == nil {
+		log.Printf("[HISTORY] Warning: %s already has revision history; the history of %s stays under its old name", newName, oldName)
+		return
+	}
+	if err := os.Rename(source, destination); err != nil {
+		log.Printf("[HISTORY] Warning: failed to move revision history of %s to %s: %v", oldName, newName, err)
+	}
+}
+
+// contentHash returns the short content hash shown for revisions
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// RestoreRevision makes an earlier revision of a memory its current content, recreating the
+// memory if it was deleted. The restore is itself recorded as a new revision.
+func (es *EnhancedStore) RestoreRevision(name string, number int) error {
+	name = strings.TrimSuffix(name, ".md")
+
+	content, err := es.Store.ReadRevision(name, number)
+	if err != nil {
+		return err
+	}
+
+	fm, body, err := ParseDocument(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse revision %d of memory %s: %w", number, name, err)
+	}
+	fm.UpdateTimestamps(false)
+	restored, err := FormatDocument(fm, body)
+	if err != nil {
+		return fmt.Errorf("failed to format document: %w", err)
+	}
+
+	if err := es.Store.restoreRevision(name, restored); err != nil {
+		return fmt.Errorf("failed to restore memory %s: %w", name, err)
+	}
+
+	if err := es.syncMemoryToDatabase(name); err != nil {
+		log.Printf("Warning: failed to sync memory to database: %v", err)
+	}
+	return nil
+}
+
+// restoreRevision writes restored content to a memory file, creating it if needed
+func (s *Store) restoreRevision(name, content string) error {
+	defer s.lock()()
+
+	path := s.path(name)
+	original, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if original == nil {
+		if existing, err := s.nameCollision(name, ""); err != nil {
+			return err
+		} else if existing != "" {
+			return &NameCollisionError{Name: name, Existing: existing}
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+	}
+
+	if err := writeFileAtomic(path, []byte(content), 0644); err != nil {
+		return err
+	}
+	s.recordRevision(name, original, []byte(content), RevisionRestored)
+	return nil
+}
+
+// DiffRevisions returns a unified diff between two revisions of a memory. A revision number of 0
+// for to means the current content (or the newest revision if the memory was deleted); 0 for from
+// means the newest revision before to whose content differs from it. It also returns the labels
+// of the compared versions.
+func (es *EnhancedStore) DiffRevisions(name string, from, to, context int) (string, string, string, error) {
+	name = strings.TrimSuffix(name, ".md")
+
+	revisions, err := es.Store.History(name)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	// Resolve the newer side
+	var toContent, toLabel string
+	if to == 0 {
+		if current, err := es.Store.Read(name); err == nil {
+			toContent, toLabel = current, "current"
+		} else {
+			to = revisions[0].Number
+		}
+	}
+	if to != 0 {
+		if toContent, err = es.Store.ReadRevision(name, to); err != nil {
+			return "", "", "", err
+		}
+		toLabel = fmt.Sprintf("revision %d", to)
+	}
+
+	// Resolve the older side
+	var fromContent string
+	if from == 0 {
+		toHash := contentHash([]byte(toContent))
+		for _, revision := range revisions {
+			if (to == 0 || revision.Number < to) && revision.Hash != toHash {
+				from = revision.Number
+				break
+			}
+		}
+		if from == 0 {
+			return "", "", "", fmt.Errorf("memory %s has no earlier revision to compare %s with", name, toLabel)
+		}
+	}
+	if fromContent, err = es.Store.ReadRevision(name, from); err != nil {
+		return "", "", "", err
+	}
+	fromLabel := fmt.Sprintf("revision %d", from)
+
+	diff := UnifiedDiff(fmt.Sprintf("%s (%s)", name, fromLabel), fmt.Sprintf("%s (%s)", name, toLabel), fromContent, toContent, context)
+	return diff, fromLabel, toLabel, nil
+}
+
+// FormatHistoryMarkdown formats the revisions of a memory as markdown
+func FormatHistoryMarkdown(name string, exists bool, revisions []Revision) string {
+	var md strings.Builder
+	md.WriteString(fmt.Sprintf("# Revision history of %s\n\n", name))
+	if !exists {
+		md.WriteString("The memory has been deleted; restore_memory can bring back any revision.\n\n")
+	}
+
+	for i, revision := range revisions {
+		current := ""
+		if i == 0 && exists {
+			current = " (latest)"
+		}
+		md.WriteString(fmt.Sprintf("- **Revision %d**%s: %s, %s, %d bytes, hash %s\n",
+			revision.Number, current, revision.Action, revision.Time.Local().Format("2006-01-02 15:04:05"), revision.Size, revision.Hash))
+	}
+	md.WriteString("\nUse diff_memory to compare revisions and restore_memory to bring one back.\n")
+
+	return md.String()
+}
@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRevisionHistory(t *testing.T) {
+	store := NewStore(t.TempDir())
+	store.history = &historyPolicy{maxRevisions: 3}
+
+	if err := store.Create("notes", "---\ntitle: Notes\n---\nFirst draft.\n"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := store.Update("notes", "---\ntitle: Notes\n---\nSecond draft.\n"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	// A hand edit is picked up as an external revision before the next change
+	if err := os.WriteFile(store.path("notes"), []byte("---\ntitle: Notes\n---\nEdited by hand.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Update("notes", "---\ntitle: Notes\n---\nThird draft.\n"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	revisions, err := store.History("notes")
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	var actions []string
+	for _, revision := range revisions {
+		actions = append(actions, revision.Action)
+	}
+	// Revision 1 (created) is pruned by the three-revision limit
+	if got, want := strings.Join(actions, ","), "updated,external,updated"; got != want {
+		t.Fatalf("revision actions = %s, want %s", got, want)
+	}
+	if revisions[0].Number != 4 {
+		t.Errorf("expected newest revision 4, got %d", revisions[0].Number)
+	}
+
+	old, err := store.ReadRevision("notes", 2)
+	if err != nil || !strings.Contains(old, "Second draft.") {
+		t.Fatalf("ReadRevision(2) = %q, %v", old, err)
+	}
+	if _, err := store.ReadRevision("notes", 1); err == nil {
+		t.Error("expected pruned revision 1 to be gone")
+	}
+
+	// Deleted memories keep their history and can be brought back
+	if err := store.Delete("notes"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if !store.HasHistory("notes") {
+		t.Fatal("expected history to survive deletion")
+	}
+	if err := store.restoreRevision("notes", old); err != nil {
+		t.Fatalf("restoreRevision() error = %v", err)
+	}
+	if content, _ := store.Read("notes"); content != old {
+		t.Errorf("restored content = %q, want %q", content, old)
+	}
+	if revisions, _ := store.History("notes"); revisions[0].Action != RevisionRestored {
+		t.Errorf("expected a restored revision, got %+v", revisions[0])
+	}
+}
+
+func TestRevisionHistoryFollowsRename(t *testing.T) {
+	store := NewStore(t.TempDir())
+	store.history = &historyPolicy{}
+
+	for _, name := range []string{"project", "project/schema"} {
+		if err := store.Create(name, "Body of "+name+"\n"); err != nil {
+			t.Fatalf("Create(%s) error = %v", name, err)
+		}
+	}
+	if err := store.moveFile("project", "overview"); err != nil {
+		t.Fatalf("moveFile() error = %v", err)
+	}
+
+	if !store.HasHistory("overview") || store.HasHistory("project") {
+		t.Error("expected the history of project to move to overview")
+	}
+	if !store.HasHistory("project/schema") {
+		t.Error("expected the history of project/schema to stay in place")
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\n"
+	b := "one\ntwo\n3\nfour\nfive\nsix\nseven\neight\nnine\n"
+
+	want := `--- a
++++ b
+@@ -2,3 +2,3 @@
+ two
+-three
++3
+ four
+@@ -8 +8,2 @@
+ eight
++nine
+`
+	if got := UnifiedDiff("a", "b", a, b, 1); got != want {
+		t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := UnifiedDiff("a", "b", a, a, 3); got != "" {
+		t.Errorf("expected no diff for equal texts, got:\n%s", got)
+	}
+
+	if got := UnifiedDiff("a", "b", "", "new\n", 3); got != "--- a\n+++ b\n@@ -0,0 +1 @@\n+new\n" {
+		t.Errorf("unexpected diff from empty text:\n%s", got)
+	}
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LinkSource is a memory containing a link to a missing target
+type LinkSource struct {
+	Name     string `json:"name"`
+	LinkType string `json:"link_type" jsonschema:"description=wiki, markdown or frontmatter"`
+	Context  string `json:"context,omitempty" jsonschema:"description=Sentence surrounding the link"`
+}
+
+// DanglingLink is a link target with no matching memory
+type DanglingLink struct {
+	Target     string       `json:"target"`
+	Sources    []LinkSource `json:"sources"`
+	Suggestion string       `json:"suggestion,omitempty" jsonschema:"description=Closest existing memory name"`
+}
+
+// LinkReport lists broken links and unlinked memories
+type LinkReport struct {
+	Dangling []DanglingLink `json:"dangling"`
+	Orphans  []string       `json:"orphans"`
+}
+
+// CheckLinks reports dangling link targets and orphan memories using the link table
+func (es *EnhancedStore) CheckLinks() (*LinkReport, error) {
+	links, err := es.db.GetDanglingLinks()
+	if err != nil {
+		return nil, err
+	}
+
+	orphans, err := es.db.GetOrphanMemories()
+	if err != nil {
+		return nil, err
+	}
+
+	titles, err := es.db.GetMemoryTitles()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LinkReport{Dangling: []DanglingLink{}, Orphans: orphans}
+	if report.Orphans == nil {
+		report.Orphans = []string{}
+	}
+
+	// Links arrive ordered by target, so consecutive rows share a target
+	for _, link := range links {
+		n := len(report.Dangling)
+		if n == 0 || report.Dangling[n-1].Target != link.Link.ToMemoryName {
+			report.Dangling = append(report.Dangling, DanglingLink{
+				Target:     link.Link.ToMemoryName,
+				Suggestion: suggestName(link.Link.ToMemoryName, titles),
+			})
+			n++
+		}
+		report.Dangling[n-1].Sources = append(report.Dangling[n-1].Sources, LinkSource{
+			Name:     link.Source.Name,
+			LinkType: link.Link.LinkType,
+			Context:  link.Link.Context,
+		})
+	}
+
+	return report, nil
+}
+
+// FormatLinkReportMarkdown formats a link report as markdown
+func FormatLinkReportMarkdown(report *LinkReport, includeOrphans bool) string {
+	var md strings.Builder
+	md.WriteString("# Link Check\n\n")
+
+	if len(report.Dangling) == 0 {
+		md.WriteString("No dangling links found.\n")
+	} else {
+		md.WriteString(fmt.Sprintf("## Dangling links (%d targets)\n\n", len(report.Dangling)))
+		for _, dangling := range report.Dangling {
+			md.WriteString(fmt.Sprintf("### %s\n", dangling.Target))
+			if dangling.Suggestion != "" {
+				md.WriteString(fmt.Sprintf("**Suggested fix:** link to `%s` instead\n", dangling.Suggestion))
+			} else {
+				md.WriteString("**Suggested fix:** create the memory or remove the link\n")
+			}
+			md.WriteString("**Linked from:**\n")
+			for _, source := range dangling.Sources {
+				md.WriteString(fmt.Sprintf("- %s (%s link)", source.Name, source.LinkType))
+				if source.Context != "" {
+					md.WriteString(fmt.Sprintf(": %s", source.Context))
+				}
+				md.WriteString("\n")
+			}
+			md.WriteString("\n")
+		}
+	}
+
+	if includeOrphans {
+		md.WriteString("\n")
+		if len(report.Orphans) == 0 {
+			md.WriteString("No orphan memories found.\n")
+		} else {
+			md.WriteString(fmt.Sprintf("## Orphan memories (%d)\n\n", len(report.Orphans)))
+			md.WriteString("These memories have no links to or from other memories:\n")
+			for _, name := range report.Orphans {
+				md.WriteString(fmt.Sprintf("- %s\n", name))
+			}
+		}
+	}
+
+	return md.String()
+}
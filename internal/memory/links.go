@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jcdickinson/simplemem/internal/db"
+)
+
+// maxLinkContext caps the length of the sentence stored alongside a link
+const maxLinkContext = 300
+
+// LinkTargetName normalizes a link target to the name of the memory it refers to.
+// It returns an empty string for links that do not point at a memory (e.g. URLs).
+func LinkTargetName(target string) string {
+	target = strings.TrimSpace(target)
+	target = strings.TrimPrefix(target, "[[")
+	target = strings.TrimSuffix(target, "]]")
+
+	if strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") {
+		return ""
+	}
+
+	// Drop wiki aliases ([[name|alias]]) and heading anchors (name#section)
+	if i := strings.Index(target, "|"); i >= 0 {
+		target = target[:i]
+	}
+	if i := strings.Index(target, "#"); i >= 0 {
+		target = target[:i]
+	}
+
+	target = strings.TrimSuffix(strings.TrimSpace(target), ".md")
+
+	// "./" only matters for namespaced targets ("./db/schema" is relative, "db/schema" is a full name)
+	if rest := strings.TrimPrefix(target, "./"); !strings.Contains(rest, "/") {
+		target = rest
+	}
+	return target
+}
+
+// collectLinks gathers the body links, frontmatter links and relations of a memory for the
+// memory_links table
+func collectLinks(info *MemoryInfo) []db.MemoryLink {
+	var links []db.MemoryLink
+
+	// Track where the last occurrence of each link was found, so repeated links get their own context
+	searchFrom := make(map[string]int)
+
+	for _, link := range ExtractLinks(info.Body) {
+		name := LinkTargetName(link.Target)
+		if name == "" {
+			continue
+		}
+
+		pattern := "](" + link.Target
+		if link.Type == "wiki" {
+			pattern = "[[" + link.Text + "]]"
+		}
+
+		context := ""
+		if offset := strings.Index(info.Body[searchFrom[pattern]:], pattern); offset >= 0 {
+			start := searchFrom[pattern] + offset
+			searchFrom[pattern] = start + len(pattern)
+			context = sentenceAround(info.Body, start, start+len(pattern))
+		}
+
+		links = append(links, db.MemoryLink{
+			ToMemoryName: name,
+			LinkText:     link.Text,
+			LinkType:     link.Type,
+			Context:      context,
+		})
+	}
+
+	if info.Frontmatter != nil {
+		for _, target := range info.Frontmatter.Links {
+			name := LinkTargetName(target)
+			if name == "" {
+				continue
+			}
+			links = append(links, db.MemoryLink{
+				ToMemoryName: name,
+				LinkText:     target,
+				LinkType:     "frontmatter",
+			})
+		}
+
+		// Typed relations are stored with their relation type as the link type
+		relationTypes := make([]string, 0, len(info.Frontmatter.Relations))
+		for relation := range info.Frontmatter.Relations {
+			relationTypes = append(relationTypes, relation)
+		}
+		sort.Strings(relationTypes)
+
+		for _, relation := range relationTypes {
+			for _, target := range info.Frontmatter.Relations[relation] {
+				name := LinkTargetName(target)
+				if name == "" {
+					continue
+				}
+				links = append(links, db.MemoryLink{
+					ToMemoryName: name,
+					LinkText:     target,
+					LinkType:     relation,
+				})
+			}
+		}
+	}
+
+	return links
+}
+
+// sentenceAround returns the sentence containing body[start:end], limited to its line
+func sentenceAround(body string, start, end int) string {
+	lineStart := strings.LastIndex(body[:start], "\n") + 1
+	lineEnd := len(body)
+	if i := strings.Index(body[end:], "\n"); i >= 0 {
+		lineEnd = end + i
+	}
+
+	sentenceStart := lineStart
+	for _, terminator := range []string{". ", "! ", "? "} {
+		if i := strings.LastIndex(body[lineStart:start], terminator); i >= 0 && lineStart+i+len(terminator) > sentenceStart {
+			sentenceStart = lineStart + i + len(terminator)
+		}
+	}
+
+	sentenceEnd := lineEnd
+	for _, terminator := range []string{". ", "! ", "? "} {
+		if i := strings.Index(body[end:lineEnd], terminator); i >= 0 && end+i+1 < sentenceEnd {
+			sentenceEnd = end + i + 1
+		}
+	}
+
+	// Drop list, heading and quote markers
+	sentence := strings.TrimLeft(body[sentenceStart:sentenceEnd], " \t-*+#>")
+	sentence = strings.TrimSpace(sentence)
+
+	if runes := []rune(sentence); len(runes) > maxLinkContext {
+		sentence = string(runes[:maxLinkContext]) + "..."
+	}
+	return sentence
+}
+
+// sameLinks reports whether two link lists are equal, ignoring database IDs
+func sameLinks(a, b []db.MemoryLink) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ToMemoryName != b[i].ToMemoryName || a[i].LinkText != b[i].LinkText ||
+			a[i].LinkType != b[i].LinkType || a[i].Context != b[i].Context {
+			return false
+		}
+	}
+	return true
+}
+
+// syncLinks stores the links of a memory, skipping the write when nothing changed
+func (es *EnhancedStore) syncLinks(memoryID int, info *MemoryInfo) error {
+	links := collectLinks(info)
+	for i := range links {
+		links[i].ToMemoryName = es.Store.ResolveLink(info.Name, links[i].ToMemoryName)
+	}
+
+	existing, err := es.db.GetOutboundLinks(memoryID)
+	if err != nil {
+		return err
+	}
+	if sameLinks(existing, links) {
+		return nil
+	}
+
+	return es.db.ReplaceMemoryLinks(memoryID, links)
+}
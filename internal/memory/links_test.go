@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"testing"
+)
+
+func TestLinkTargetName(t *testing.T) {
+	tests := map[string]string{
+		"other.md":                    "other",
+		"./other.md":                  "other",
+		"other|Alias":                 "other",
+		"other.md#section":            "other",
+		"[[other]]":                   "other",
+		"https://example.com/READ.md": "",
+	}
+
+	for input, want := range tests {
+		if got := LinkTargetName(input); got != want {
+			t.Errorf("LinkTargetName(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestCollectLinks(t *testing.T) {
+	info := &MemoryInfo{
+		Body: "# Notes\n\nIntro sentence. The schema lives in [[duckdb-schema]] for now. Trailing.\n" +
+			"- See [the guide](guide.md) and [docs](https://example.com/docs.md)\n",
+		Frontmatter: &Frontmatter{Links: []string{"related.md"}, Relations: map[string][]string{"depends_on": {"base"}}},
+	}
+
+	links := collectLinks(info)
+	if len(links) != 4 {
+		t.Fatalf("expected 4 links, got %+v", links)
+	}
+	if relation := links[3]; relation.ToMemoryName != "base" || relation.LinkType != "depends_on" {
+		t.Errorf("expected depends_on relation last, got %+v", relation)
+	}
+
+	want := []struct {
+		name, linkType, context string
+	}{
+		{"duckdb-schema", "wiki", "The schema lives in [[duckdb-schema]] for now."},
+		{"guide", "markdown", "See [the guide](guide.md) and [docs](https://example.com/docs.md)"},
+		{"related", "frontmatter", ""},
+	}
+	for i, w := range want {
+		if links[i].ToMemoryName != w.name || links[i].LinkType != w.linkType || links[i].Context != w.context {
+			t.Errorf("link %d = %+v, want %+v", i, links[i], w)
+		}
+	}
+}
+
+func TestRewriteDocumentLinks(t *testing.T) {
+	content := "---\ntitle: Notes\nlinks:\n  - old-name.md\n  - keep.md\nrelations:\n  blocks: [old-name]\n---\n" +
+		"See [[old-name]], [[old-name#setup|the setup]] and [[old-name-two]].\n" +
+		"Also [guide](./old-name.md#intro \"Guide\"), [other](keep.md) and [web](https://example.com/old-name.md).\n"
+
+	store := NewStore(t.TempDir())
+	updated, count, err := store.rewriteLinksTo("notes", content, "old-name", "new-name")
+	if err != nil {
+		t.Fatalf("rewriteLinksTo() error = %v", err)
+	}
+	if count != 5 {
+		t.Errorf("expected 5 rewritten links, got %d", count)
+	}
+
+	fm, body, err := ParseDocument(updated)
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+	if len(fm.Links) != 2 || fm.Links[0] != "new-name.md" || fm.Links[1] != "keep.md" {
+		t.Errorf("unexpected frontmatter links: %v", fm.Links)
+	}
+	if blocks := fm.Relations["blocks"]; len(blocks) != 1 || blocks[0] != "new-name" {
+		t.Errorf("unexpected relations: %v", fm.Relations)
+	}
+
+	want := "See [[new-name]], [[new-name#setup|the setup]] and [[old-name-two]].\n" +
+		"Also [guide](./new-name.md#intro \"Guide\"), [other](keep.md) and [web](https://example.com/old-name.md).\n"
+	if body != want {
+		t.Errorf("unexpected body:\n%s\nwant:\n%s", body, want)
+	}
+
+	unchanged, count, err := store.rewriteLinksTo("notes", content, "missing", "other")
+	if err != nil || count != 0 || unchanged != content {
+		t.Errorf("expected document without matching links to be unchanged (count %d, err %v)", count, err)
+	}
+}
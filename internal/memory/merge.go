@@ -0,0 +1,324 @@
+package memory
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// archiveDir is the directory, relative to the memory directory, that merged sources are archived to
+const archiveDir = ".archive"
+
+// TagConflict is a tag both merged memories carry with different values
+type TagConflict struct {
+	Tag     string `json:"tag"`
+	Kept    any    `json:"kept" jsonschema:"description=Value kept from the target memory"`
+	Dropped any    `json:"dropped" jsonschema:"description=Value of the source memory that was dropped"`
+}
+
+// MergeResult describes a completed merge
+type MergeResult struct {
+	Source          string
+	Target          string
+	TagConflicts    []TagConflict
+	UpdatedMemories []string // Memories whose links were redirected to the target
+	LinksRewritten  int
+	ArchivedTo      string // Archive path of the source relative to the memory directory; empty if deleted
+	TrashID         string // Trash ID of the deleted source; empty if archived or the trash is disabled
+}
+
+// Merge merges the source memory into the target: the source body is appended to the target's,
+// tags, links, relations and metadata are unioned (the target wins tag conflicts, which are
+// reported), links to the source are redirected to the target across the store, and the source
+// is archived or deleted (into the trash when it is enabled). The validate callback checks the
+// merged document before anything is written. File changes are rolled back if any step fails.
+func (es *EnhancedStore) Merge(source, target string, archive bool, validate func(content string) error) (*MergeResult, error) {
+	source = strings.TrimSuffix(source, ".md")
+	target = strings.TrimSuffix(target, ".md")
+
+	if source == target {
+		return nil, fmt.Errorf("cannot merge a memory into itself")
+	}
+
+	unlock := es.syncLocks.lock(source, target)
+	result, err := es.mergeLocked(source, target, archive, validate)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+
+	if result.TrashID != "" {
+		existing, err := es.db.GetMemory(source)
+		if err != nil {
+			log.Printf("Warning: failed to look up memory %s in database: %v", source, err)
+		}
+		es.trashIndexed(source, existing, result.TrashID)
+	} else if err := es.db.DeleteMemory(source); err != nil {
+		log.Printf("Warning: failed to delete memory from database: %v", err)
+	}
+	unlock()
+
+	for _, name := range append([]string{target}, result.UpdatedMemories...) {
+		if err := es.syncMemoryToDatabase(name); err != nil {
+			log.Printf("Warning: failed to sync memory %s to database: %v", name, err)
+		}
+	}
+
+	log.Printf("[MERGE] Merged %s into %s, redirected %d links in %d memories", source, target, result.LinksRewritten, len(result.UpdatedMemories))
+	return result, nil
+}
+
+// mergeLocked plans and applies the file changes of a merge, holding the store lock throughout
+// so no other write can slip in between reading the memories and writing the merged result
+func (es *EnhancedStore) mergeLocked(source, target string, archive bool, validate func(content string) error) (*MergeResult, error) {
+	defer es.Store.lock()()
+
+	sourceContent, err := es.Store.readFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory %s: %w", source, err)
+	}
+	targetContent, err := es.Store.readFile(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory %s: %w", target, err)
+	}
+
+	merged, conflicts, err := es.Store.mergeDocuments(targetContent, sourceContent, target, source)
+	if err != nil {
+		return nil, err
+	}
+	if validate != nil {
+		if err := validate(merged); err != nil {
+			return nil, err
+		}
+	}
+
+	// Plan every link redirect before touching any file
+	names, err := es.Store.listNames()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MergeResult{Source: source, Target: target, TagConflicts: conflicts, UpdatedMemories: []string{}}
+	rewrites := map[string]string{target: merged}
+	for _, name := range names {
+		if name == source || name == target {
+			continue
+		}
+
+		content, err := es.Store.readFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read memory %s: %w", name, err)
+		}
+
+		updated, count, err := es.Store.rewriteLinksTo(name, content, source, target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewrite links in memory %s: %w", name, err)
+		}
+		if count > 0 {
+			rewrites[name] = updated
+			result.LinksRewritten += count
+			result.UpdatedMemories = append(result.UpdatedMemories, name)
+		}
+	}
+	sort.Strings(result.UpdatedMemories)
+
+	// Apply file changes, undoing them all on the first failure
+	var changes []fileChange
+	rollback := func(cause error) (*MergeResult, error) {
+		for i := len(changes) - 1; i >= 0; i-- {
+			if err := es.Store.restoreFile(changes[i].name, changes[i].original); err != nil {
+				log.Printf("[MERGE] ERROR: Failed to roll back %s: %v", changes[i].name, err)
+			}
+		}
+		return nil, cause
+	}
+
+	for name, content := range rewrites {
+		action := RevisionRelinked
+		if name == target {
+			action = RevisionMerged
+		}
+		original, err := es.Store.writeFile(name, content, action)
+		if err != nil {
+			return rollback(fmt.Errorf("failed to write memory %s: %w", name, err))
+		}
+		changes = append(changes, fileChange{name: name, original: original})
+	}
+
+	if archive {
+		archived, err := es.Store.archiveFile(source)
+		if err != nil {
+			return rollback(fmt.Errorf("failed to archive memory %s: %w", source, err))
+		}
+		result.ArchivedTo = archived
+	} else if es.trash.Enabled {
+		entry, err := es.Store.moveFileToTrash(source, "")
+		if err != nil {
+			return rollback(fmt.Errorf("failed to move memory %s to the trash: %w", source, err))
+		}
+		result.TrashID = entry.ID
+	} else if err := es.Store.deleteFile(source, ""); err != nil {
+		return rollback(fmt.Errorf("failed to delete memory %s: %w", source, err))
+	}
+
+	return result, nil
+}
+
+// mergeDocuments merges the source document into the target document, returning the merged
+// document and the tag conflicts
+func (s *Store) mergeDocuments(targetContent, sourceContent, target, source string) (string, []TagConflict, error) {
+	// Links to the source become links to the target, and the source's relative links are
+	// rewritten to work from the target's namespace
+	targetContent, _, err := s.rewriteLinksTo(target, targetContent, source, target)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to rewrite links in memory %s: %w", target, err)
+	}
+	sourceContent, _, err = s.relocateLinks(sourceContent, source, target)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to rewrite links in memory %s: %w", source, err)
+	}
+
+	fm, body, err := ParseDocument(targetContent)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse memory %s: %w", target, err)
+	}
+	sourceFm, sourceBody, err := ParseDocument(sourceContent)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse memory %s: %w", source, err)
+	}
+
+	if fm.Title == "" {
+		fm.Title = sourceFm.Title
+	}
+	if fm.Description == "" {
+		fm.Description = sourceFm.Description
+	}
+	if !sourceFm.Created.IsZero() && (fm.Created.IsZero() || sourceFm.Created.Before(fm.Created)) {
+		fm.Created = sourceFm.Created
+	}
+
+	conflicts := mergeTags(fm, sourceFm.Tags)
+
+	for key, value := range sourceFm.Metadata {
+		if _, ok := fm.Metadata[key]; !ok {
+			if fm.Metadata == nil {
+				fm.Metadata = make(map[string]interface{})
+			}
+			fm.Metadata[key] = value
+		}
+	}
+
+	// Links between the two memories would become self-links, so they are dropped
+	resolve := func(link string) string {
+		return s.ResolveLink(target, LinkTargetName(link))
+	}
+	fm.Links = mergeLinkTargets(fm.Links, sourceFm.Links, target, resolve)
+	for relation, targets := range sourceFm.Relations {
+		if fm.Relations == nil {
+			fm.Relations = make(map[string][]string)
+		}
+		fm.Relations[relation] = append(fm.Relations[relation], targets...)
+	}
+	for relation, targets := range fm.Relations {
+		if targets = mergeLinkTargets(targets, nil, target, resolve); len(targets) == 0 {
+			delete(fm.Relations, relation)
+		} else {
+			fm.Relations[relation] = targets
+		}
+	}
+
+	heading := sourceFm.Title
+	if heading == "" {
+		heading = source
+	}
+	body = strings.TrimRight(body, "\n")
+	if body != "" {
+		body += "\n\n"
+	}
+	body += fmt.Sprintf("## Merged from %s\n\n%s\n", heading, strings.Trim(sourceBody, "\n"))
+
+	fm.UpdateTimestamps(false)
+	merged, err := FormatDocument(fm, body)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to format merged document: %w", err)
+	}
+	return merged, conflicts, nil
+}
+
+// mergeTags adds the source tags to the frontmatter, keeping the existing value of a tag on
+// conflict. Conflicts are returned in tag order.
+func mergeTags(fm *Frontmatter, sourceTags map[string]interface{}) []TagConflict {
+	keys := make([]string, 0, len(sourceTags))
+	for key := range sourceTags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	conflicts := []TagConflict{}
+	for _, key := range keys {
+		value := sourceTags[key]
+		existing, ok := fm.Tags[key]
+		if !ok {
+			if fm.Tags == nil {
+				fm.Tags = make(map[string]interface{})
+			}
+			fm.Tags[key] = value
+			continue
+		}
+		if fmt.Sprintf("%v", existing) != fmt.Sprintf("%v", value) {
+			conflicts = append(conflicts, TagConflict{Tag: key, Kept: existing, Dropped: value})
+		}
+	}
+	return conflicts
+}
+
+// mergeLinkTargets unions two lists of link targets by the memory they resolve to, dropping
+// links to the merge target
+func mergeLinkTargets(targets, extra []string, target string, resolve func(link string) string) []string {
+	var merged []string
+	seen := make(map[string]bool)
+	for _, link := range append(append([]string{}, targets...), extra...) {
+		name := resolve(link)
+		if name == target {
+			continue
+		}
+
+		key := name
+		if key == "" {
+			key = link // External links have no memory name
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		merged = append(merged, link)
+	}
+	return merged
+}
+
+// archiveFile moves a memory file into the archive directory, where it is no longer listed,
+// returning its archive path relative to the memory directory. The caller must hold the lock.
+func (s *Store) archiveFile(name string) (string, error) {
+	archived := path.Join(archiveDir, name+".md")
+	if _, err := os.Stat(filepath.Join(s.basePath, filepath.FromSlash(archived))); err == nil {
+		// Keep earlier archives of the same name
+		archived = path.Join(archiveDir, fmt.Sprintf("%s-%s.md", name, time.Now().Format("20060102-150405")))
+	}
+
+	destination := filepath.Join(s.basePath, filepath.FromSlash(archived))
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(s.path(name), destination); err != nil {
+		return "", err
+	}
+	s.written.note(name, nil)
+
+	s.pruneEmptyDirs(name)
+	return archived, nil
+}
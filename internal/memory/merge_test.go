@@ -0,0 +1,190 @@
+package memory
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jcdickinson/simplemem/internal/db"
+)
+
+func TestShingleOverlap(t *testing.T) {
+	a := shingles("DuckDB stores the embeddings, the VSS extension indexes them.", 3)
+	b := shingles("duckdb stores the embeddings; the vss extension indexes them", 3)
+	if got := jaccard(a, b); got != 1 {
+		t.Errorf("expected identical shingles ignoring case and punctuation, got overlap %v", got)
+	}
+
+	c := shingles("Release builds run in CI on every tag", 3)
+	if got := jaccard(a, c); got != 0 {
+		t.Errorf("expected no overlap, got %v", got)
+	}
+
+	if got := shingles("two words", 3); !reflect.DeepEqual(got, map[string]bool{"two words": true}) {
+		t.Errorf("expected short text to form one shingle, got %v", got)
+	}
+	if got := jaccard(shingles("", 3), shingles("", 3)); got != 0 {
+		t.Errorf("expected empty texts not to overlap, got %v", got)
+	}
+
+	d := shingles("one two three four", 2)  // {one two, two three, three four}
+	e := shingles("two three four five", 2) // {two three, three four, four five}
+	if got := jaccard(d, e); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("expected overlap 0.5, got %v", got)
+	}
+}
+
+func TestMergeDocuments(t *testing.T) {
+	target := `---
+title: RAG pipeline
+tags:
+  area: rag
+  status: active
+links:
+  - "[[duckdb]]"
+  - "[[rag-notes]]"
+---
+Chunks are embedded with VoyageAI. See [[rag-notes]].
+`
+	source := `---
+title: RAG notes
+description: Loose notes on retrieval
+tags:
+  area: rag
+  status: draft
+  topic: embeddings
+links:
+  - "[[duckdb]]"
+  - "[[voyage]]"
+relations:
+  see_also:
+    - rag-pipeline
+    - chunking
+---
+Embeddings are stored in DuckDB.
+`
+
+	merged, conflicts, err := NewStore(t.TempDir()).mergeDocuments(target, source, "rag-pipeline", "rag-notes")
+	if err != nil {
+		t.Fatalf("mergeDocuments() error = %v", err)
+	}
+
+	wantConflicts := []TagConflict{{Tag: "status", Kept: "active", Dropped: "draft"}}
+	if !reflect.DeepEqual(conflicts, wantConflicts) {
+		t.Errorf("conflicts = %+v, want %+v", conflicts, wantConflicts)
+	}
+
+	fm, body, err := ParseDocument(merged)
+	if err != nil {
+		t.Fatalf("failed to parse merged document: %v", err)
+	}
+
+	if fm.Title != "RAG pipeline" || fm.Description != "Loose notes on retrieval" {
+		t.Errorf("expected target title and source description, got %q / %q", fm.Title, fm.Description)
+	}
+	wantTags := map[string]interface{}{"area": "rag", "status": "active", "topic": "embeddings"}
+	if !reflect.DeepEqual(fm.Tags, wantTags) {
+		t.Errorf("tags = %v, want %v", fm.Tags, wantTags)
+	}
+	if want := []string{"[[duckdb]]", "[[voyage]]"}; !reflect.DeepEqual(fm.Links, want) {
+		t.Errorf("links = %v, want %v", fm.Links, want)
+	}
+	if want := map[string][]string{"see_also": {"chunking"}}; !reflect.DeepEqual(fm.Relations, want) {
+		t.Errorf("relations = %v, want %v", fm.Relations, want)
+	}
+
+	if !strings.Contains(body, "## Merged from RAG notes\n\nEmbeddings are stored in DuckDB.") {
+		t.Errorf("expected source body under a merge heading, got:\n%s", body)
+	}
+	if strings.Contains(body, "[[rag-notes]]") {
+		t.Errorf("expected links to the source to be redirected, got:\n%s", body)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	es := newTestEnhancedStore(t, map[string]string{
+		"target": "---\ntitle: Target\n---\nTarget body.\n",
+		"source": "---\ntitle: Source\n---\nSource body.\n",
+		"linker": "---\ntitle: Linker\n---\nSee [[source]].\n",
+	})
+
+	tooLong := errors.New("too long")
+	if _, err := es.Merge("source", "target", true, func(string) error { return tooLong }); !errors.Is(err, tooLong) {
+		t.Fatalf("Merge() error = %v, want the validation error", err)
+	}
+	if content, _ := es.Store.Read("target"); strings.Contains(content, "Source body.") {
+		t.Error("expected a rejected merge to leave the target unchanged")
+	}
+
+	result, err := es.Merge("source", "target", true, nil)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if result.ArchivedTo != ".archive/source.md" || strings.Join(result.UpdatedMemories, ",") != "linker" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if content, _ := es.Store.Read("target"); !strings.Contains(content, "Source body.") {
+		t.Errorf("expected the source body in the target, got:\n%s", content)
+	}
+	if content, _ := es.Store.Read("linker"); !strings.Contains(content, "See [[target]].") {
+		t.Errorf("expected the link to be redirected, got:\n%s", content)
+	}
+	if _, err := es.Store.Read("source"); err == nil {
+		t.Error("expected the source to be archived")
+	}
+
+	// Without archive the source goes to the trash like delete_memory
+	if err := es.Store.Create("other", "---\ntitle: Other\n---\nOther body.\n"); err != nil {
+		t.Fatal(err)
+	}
+	es.syncMemoryToDatabase("other")
+	es.trash.Enabled = true
+	result, err = es.Merge("other", "target", false, nil)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	entries, err := es.Store.Trash()
+	if err != nil || len(entries) != 1 || entries[0].ID != result.TrashID || entries[0].Name != "other" {
+		t.Errorf("Trash() = %+v, %v, want the source under %q", entries, err, result.TrashID)
+	}
+	if memory, _ := es.db.GetMemory("other"); memory != nil {
+		t.Error("expected the source to be removed from the database")
+	}
+}
+
+func TestDuplicatesOf(t *testing.T) {
+	es := newTestEnhancedStore(t, map[string]string{
+		"new":     "---\ntitle: New\n---\nEmbeddings are stored in DuckDB.\n",
+		"similar": "---\ntitle: Similar\n---\nVectors live in the database.\n",
+		"copy":    "---\ntitle: Copy\n---\nEmbeddings are stored in DuckDB.\n",
+		"other":   "---\ntitle: Other\n---\nRelease builds run in CI.\n",
+	})
+
+	// Embeddings as the API would have produced them: copy has none
+	vectors := map[string][]float32{"new": {1, 0}, "similar": {0.99, 0.1}, "other": {0, 1}}
+	for name, values := range vectors {
+		memory, err := es.db.GetMemory(name)
+		if err != nil || memory == nil {
+			t.Fatalf("GetMemory(%s) = %v, %v", name, memory, err)
+		}
+		vector := make([]float32, 1024)
+		copy(vector, values)
+		if err := es.db.InsertEmbedding(&db.Embedding{MemoryID: memory.ID, ChunkText: name, Embedding: vector}); err != nil {
+			t.Fatal(err)
+		}
+		if err := es.db.UpdateMemoryCentroid(memory.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pairs, err := es.DuplicatesOf("new", DuplicateOptions{})
+	if err != nil {
+		t.Fatalf("DuplicatesOf() error = %v", err)
+	}
+	// Only nearest memories by embedding are compared, so the unembedded copy is not found
+	if len(pairs) != 1 || pairs[0].Name != "new" || pairs[0].Duplicate != "similar" {
+		t.Errorf("DuplicatesOf() = %+v, want only similar", pairs)
+	}
+}
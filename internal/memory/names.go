@@ -0,0 +1,267 @@
+package memory
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jcdickinson/simplemem/internal/config"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Name character sets
+const (
+	CharsetUnicode = "unicode"
+	CharsetASCII   = "ascii"
+)
+
+// Limits on memory names
+const (
+	defaultMaxNameLength = 200
+	maxSegmentLength     = 250 // Leaves room for the .md extension within common filesystem limits
+)
+
+// InvalidNameError reports a memory name that cannot be used, and why
+type InvalidNameError struct {
+	Name   string
+	Reason string
+}
+
+func (e *InvalidNameError) Error() string {
+	return fmt.Sprintf("invalid memory name '%s': %s", e.Name, e.Reason)
+}
+
+// NameCollisionError reports a new memory name that differs from an existing name only by case
+// or Unicode form, which would overwrite the existing memory on case-insensitive filesystems
+type NameCollisionError struct {
+	Name     string
+	Existing string
+}
+
+func (e *NameCollisionError) Error() string {
+	return fmt.Sprintf("memory name '%s' collides with existing name '%s' (names are compared case-insensitively)", e.Name, e.Existing)
+}
+
+// NameRules normalizes the names of new memories into slugs
+type NameRules struct {
+	nfc       bool
+	lowercase bool
+	ascii     bool
+	extra     string
+	separator string
+	maxLength int
+}
+
+// DefaultNameRules returns the naming rules used when none are configured
+func DefaultNameRules() *NameRules {
+	return &NameRules{nfc: true, lowercase: true, extra: "-_.", separator: "-", maxLength: defaultMaxNameLength}
+}
+
+// NewNameRules creates naming rules from the configuration
+func NewNameRules(cfg config.NamingConfig) (*NameRules, error) {
+	rules := &NameRules{
+		nfc:       cfg.NFC,
+		lowercase: cfg.Lowercase,
+		extra:     cfg.ExtraChars,
+		separator: cfg.Separator,
+		maxLength: cfg.MaxLength,
+	}
+
+	switch cfg.Charset {
+	case "", CharsetUnicode:
+	case CharsetASCII:
+		rules.ascii = true
+	default:
+		return nil, fmt.Errorf("unknown naming charset '%s' (expected %s or %s)", cfg.Charset, CharsetUnicode, CharsetASCII)
+	}
+
+	if strings.ContainsAny(rules.extra, `/\`) {
+		return nil, fmt.Errorf("naming extra_chars may not contain path separators")
+	}
+	for _, r := range rules.separator {
+		if !rules.allowed(r) {
+			return nil, fmt.Errorf("naming separator '%s' must consist of allowed characters", rules.separator)
+		}
+	}
+	if rules.maxLength <= 0 {
+		rules.maxLength = defaultMaxNameLength
+	}
+
+	return rules, nil
+}
+
+// Normalize turns a requested name into the name a new memory is stored under. Each namespace
+// segment is composed (NFC), lowercased and stripped of disallowed characters according to the
+// rules. Names that would escape the memory directory are rejected rather than repaired.
+func (r *NameRules) Normalize(name string) (string, error) {
+	requested := name
+	name = strings.TrimSuffix(strings.TrimSpace(name), ".md")
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		if segment = r.normalizeSegment(segment); segment == "" {
+			return "", &InvalidNameError{Name: requested, Reason: fmt.Sprintf("'%s' has no usable characters", segments[i])}
+		}
+		segments[i] = segment
+	}
+	name = strings.Join(segments, "/")
+
+	if len(name) > r.maxLength {
+		return "", &InvalidNameError{Name: requested, Reason: fmt.Sprintf("longer than %d bytes", r.maxLength)}
+	}
+	if err := ValidateName(name); err != nil {
+		return "", &InvalidNameError{Name: requested, Reason: err.(*InvalidNameError).Reason}
+	}
+	return name, nil
+}
+
+// normalizeSegment normalizes a single namespace segment, returning an empty string if nothing
+// usable is left
+func (r *NameRules) normalizeSegment(segment string) string {
+	if r.nfc {
+		segment = norm.NFC.String(segment)
+	}
+	if r.ascii {
+		segment = stripMarks(segment)
+	}
+	if r.lowercase {
+		segment = strings.ToLower(segment)
+	}
+
+	var b strings.Builder
+	pending := false // A run of disallowed characters awaits its separator
+	for _, c := range segment {
+		if !r.allowed(c) {
+			pending = b.Len() > 0
+			continue
+		}
+		if pending {
+			b.WriteString(r.separator)
+			pending = false
+		}
+		b.WriteRune(c)
+	}
+
+	// Collapse repeated separators; leading dots would hide the memory, and dangling separators are noise
+	segment = b.String()
+	if r.separator != "" {
+		for strings.Contains(segment, r.separator+r.separator) {
+			segment = strings.ReplaceAll(segment, r.separator+r.separator, r.separator)
+		}
+		segment = strings.Trim(segment, r.separator)
+	}
+	return strings.TrimLeft(segment, ".")
+}
+
+// allowed reports whether a character may appear in a normalized name
+func (r *NameRules) allowed(c rune) bool {
+	if strings.ContainsRune(r.extra, c) {
+		return true
+	}
+	if r.ascii {
+		return c < utf8.RuneSelf && (unicode.IsLetter(c) || unicode.IsDigit(c))
+	}
+	return unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+// stripMarks removes accents and other combining marks, e.g. "café" becomes "cafe"
+func stripMarks(s string) string {
+	var b strings.Builder
+	for _, c := range norm.NFD.String(s) {
+		if !unicode.Is(unicode.Mn, c) {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// ValidateName checks that a memory name, without its .md extension, is safe to use as a path
+// below the memory directory: it must be relative, use "/" to separate namespaces, and contain no
+// empty, ".", ".." or hidden segments or control characters.
+func ValidateName(name string) error {
+	invalid := func(reason string) error {
+		return &InvalidNameError{Name: name, Reason: reason}
+	}
+
+	switch {
+	case strings.TrimSpace(name) == "":
+		return invalid("name is empty")
+	case !utf8.ValidString(name):
+		return invalid("not valid UTF-8")
+	case strings.HasPrefix(name, "/") || isDriveLetter(name):
+		return invalid("absolute paths are not allowed")
+	case strings.Contains(name, `\`):
+		return invalid(`backslashes are not allowed; use "/" to separate namespaces`)
+	case strings.IndexFunc(name, unicode.IsControl) >= 0:
+		return invalid("control characters are not allowed")
+	}
+
+	for _, segment := range strings.Split(name, "/") {
+		switch {
+		case segment == "":
+			return invalid("empty path segments are not allowed")
+		case segment == "." || segment == "..":
+			return invalid(`"." and ".." path segments are not allowed`)
+		case strings.HasPrefix(segment, "."):
+			return invalid(`segments starting with "." are reserved`)
+		case len(segment) > maxSegmentLength:
+			return invalid(fmt.Sprintf("segments may be at most %d bytes long", maxSegmentLength))
+		}
+	}
+	return nil
+}
+
+// isDriveLetter reports whether a name starts with a Windows drive such as "C:"
+func isDriveLetter(name string) bool {
+	return len(name) >= 2 && name[1] == ':' && ('a' <= name[0]|0x20 && name[0]|0x20 <= 'z')
+}
+
+// NormalizeName normalizes the name of a new memory with the store's naming rules
+func (s *Store) NormalizeName(name string) (string, error) {
+	return s.names.Normalize(name)
+}
+
+// checkName validates a memory name, with or without its .md extension
+func checkName(name string) error {
+	return ValidateName(strings.TrimSuffix(name, ".md"))
+}
+
+// nameCollision returns the existing memory or namespace that name matches only case-insensitively
+// (after NFC composition), or an empty string. The memory ignore, which is being renamed, never
+// collides. The caller must hold the lock.
+func (s *Store) nameCollision(name, ignore string) (string, error) {
+	names, err := s.listNames()
+	if err != nil {
+		return "", err
+	}
+
+	fold := func(name string) string {
+		return strings.ToLower(norm.NFC.String(name))
+	}
+	target := fold(name)
+
+	// Namespaces are directories, so they collide too
+	namespaces := make(map[string]string)
+	for ns := NamespaceOf(name); ns != ""; ns = NamespaceOf(ns) {
+		namespaces[fold(ns)] = ns
+	}
+
+	for _, existing := range names {
+		if existing == ignore {
+			continue
+		}
+		if existing != name && fold(existing) == target {
+			return existing, nil
+		}
+		for ns := NamespaceOf(existing); ns != ""; ns = NamespaceOf(ns) {
+			if requested, ok := namespaces[fold(ns)]; ok && requested != ns {
+				return ns, nil
+			}
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jcdickinson/simplemem/internal/config"
+)
+
+func TestNormalizeName(t *testing.T) {
+	rules := DefaultNameRules()
+
+	tests := map[string]string{
+		"My Notes":                 "my-notes",
+		"notes.md":                 "notes",
+		"Project/DB Schema (v2)":   "project/db-schema-v2",
+		"  release -- checklist  ": "release-checklist",
+		"cafe\u0301":               "caf\u00e9", // Decomposed accent is composed
+		"Ünïcode_Names":            "ünïcode_names",
+		"v1.2-notes":               "v1.2-notes",
+	}
+	for input, want := range tests {
+		got, err := rules.Normalize(input)
+		if err != nil {
+			t.Errorf("Normalize(%q) error = %v", input, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	ascii, err := NewNameRules(config.NamingConfig{Charset: CharsetASCII, ExtraChars: "-_", Separator: "_"})
+	if err != nil {
+		t.Fatalf("NewNameRules() error = %v", err)
+	}
+	if got, _ := ascii.Normalize("Café Notes"); got != "Cafe_Notes" {
+		t.Errorf("expected accents stripped and spaces replaced, got %q", got)
+	}
+
+	for _, input := range []string{"", "../../etc/passwd", "/etc/passwd", `C:\notes`, "a//b", "notes/./x", ".archive/x", "???", "bad\x00name"} {
+		_, err := rules.Normalize(input)
+		var invalid *InvalidNameError
+		if !errors.As(err, &invalid) {
+			t.Errorf("Normalize(%q) error = %v, want InvalidNameError", input, err)
+		}
+	}
+
+	if _, err := NewNameRules(config.NamingConfig{Charset: "latin"}); err == nil {
+		t.Error("expected unknown charset to be rejected")
+	}
+}
+
+func TestStoreRejectsUnsafeNames(t *testing.T) {
+	store := newNamespaceStore(t, "Notes", "Project/readme")
+
+	var invalid *InvalidNameError
+	if err := store.Create("../escape", "body"); !errors.As(err, &invalid) {
+		t.Errorf("Create(../escape) error = %v, want InvalidNameError", err)
+	}
+	if _, err := store.Read("../../etc/passwd"); !errors.As(err, &invalid) {
+		t.Errorf("Read(../../etc/passwd) error = %v, want InvalidNameError", err)
+	}
+
+	var collision *NameCollisionError
+	if err := store.Create("notes", "body"); !errors.As(err, &collision) || collision.Existing != "Notes" {
+		t.Errorf("Create(notes) error = %v, want collision with Notes", err)
+	}
+	if err := store.Create("project/todo", "body"); !errors.As(err, &collision) || collision.Existing != "Project" {
+		t.Errorf("Create(project/todo) error = %v, want collision with namespace Project", err)
+	}
+	if err := store.Create("Project/todo", "body"); err != nil {
+		t.Errorf("Create(Project/todo) error = %v", err)
+	}
+}
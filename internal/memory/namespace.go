@@ -0,0 +1,229 @@
+package memory
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// NamespaceOf returns the namespace of a path-style memory name ("project/db/schema" is in
+// "project/db"), or an empty string for memories at the top level
+func NamespaceOf(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// NormalizeNamespace trims slashes and a "./" prefix from a namespace
+func NormalizeNamespace(namespace string) string {
+	namespace = strings.TrimPrefix(strings.TrimSpace(namespace), "./")
+	return strings.Trim(namespace, "/")
+}
+
+// InNamespace reports whether a memory is in a namespace or one of its sub-namespaces. Every
+// memory is in the empty (root) namespace.
+func InNamespace(name, namespace string) bool {
+	namespace = NormalizeNamespace(namespace)
+	return namespace == "" || strings.HasPrefix(name, namespace+"/")
+}
+
+// NamespaceInfo summarizes a namespace
+type NamespaceInfo struct {
+	Namespace string `json:"namespace"`
+	Memories  int    `json:"memories" jsonschema:"description=Memories directly in the namespace"`
+	Total     int    `json:"total" jsonschema:"description=Memories in the namespace and all of its sub-namespaces"`
+}
+
+// Namespaces lists the namespaces under a namespace (all of them for the root), in name order,
+// with their memory counts. The namespace itself is included first.
+func (s *Store) Namespaces(namespace string) ([]NamespaceInfo, error) {
+	namespace = NormalizeNamespace(namespace)
+
+	names, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make(map[string]*NamespaceInfo)
+	info := func(ns string) *NamespaceInfo {
+		if infos[ns] == nil {
+			infos[ns] = &NamespaceInfo{Namespace: ns}
+		}
+		return infos[ns]
+	}
+	info(namespace)
+
+	for _, name := range names {
+		if !InNamespace(name, namespace) {
+			continue
+		}
+
+		ns := NamespaceOf(name)
+		info(ns).Memories++
+		for {
+			info(ns).Total++
+			if ns == namespace {
+				break
+			}
+			ns = NamespaceOf(ns)
+		}
+	}
+
+	result := make([]NamespaceInfo, 0, len(infos))
+	for _, info := range infos {
+		result = append(result, *info)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Namespace < result[j].Namespace
+	})
+	return result, nil
+}
+
+// FormatNamespacesMarkdown formats a namespace listing as an indented markdown list
+func FormatNamespacesMarkdown(namespaces []NamespaceInfo) string {
+	if len(namespaces) == 0 {
+		return "No namespaces found."
+	}
+
+	// The listed namespace comes first; the others are indented by their depth below it
+	root := namespaces[0].Namespace
+	depth := func(ns string) int {
+		if ns == root {
+			return 0
+		}
+		if root != "" {
+			ns = strings.TrimPrefix(ns, root+"/")
+		}
+		return strings.Count(ns, "/") + 1
+	}
+
+	var md strings.Builder
+	if root == "" {
+		md.WriteString("# Namespaces\n\n")
+	} else {
+		md.WriteString(fmt.Sprintf("# Namespaces in '%s'\n\n", root))
+	}
+	for _, info := range namespaces {
+		label := path.Base(info.Namespace) + "/"
+		if info.Namespace == "" {
+			label = "(top level)"
+		} else if info.Namespace == root {
+			label = root + "/"
+		}
+		md.WriteString(fmt.Sprintf("%s- **%s** (%d memories, %d in total)\n",
+			strings.Repeat("  ", depth(info.Namespace)), label, info.Memories, info.Total))
+	}
+	md.WriteString("\nPass a namespace to search_memories or grep_memories to search within it.\n")
+
+	return md.String()
+}
+
+// ResolveLink resolves a link target name, as returned by LinkTargetName, written in memory
+// from. Targets starting with "/" and other targets containing a "/" are full names, while "./"
+// and "../" are relative to the namespace of from. Bare names are looked up in the namespace of
+// from, then in each parent namespace; bare names that do not exist anywhere resolve to the top
+// level.
+func (s *Store) ResolveLink(from, target string) string {
+	namespace := NamespaceOf(from)
+
+	switch {
+	case target == "":
+		return ""
+	case strings.HasPrefix(target, "./") || strings.HasPrefix(target, "../"):
+		return cleanName(path.Join(namespace, target))
+	case strings.Contains(target, "/"):
+		return cleanName(target)
+	}
+
+	for namespace != "" {
+		if candidate := namespace + "/" + target; s.exists(candidate) {
+			return candidate
+		}
+		namespace = NamespaceOf(namespace)
+	}
+	return target
+}
+
+// linkReference returns the shortest link target that memory from can use to refer to name
+func (s *Store) linkReference(from, name string) string {
+	namespace := NamespaceOf(from)
+	if NamespaceOf(name) == namespace {
+		return path.Base(name)
+	}
+	if strings.Contains(name, "/") {
+		return name
+	}
+
+	// A top-level name is shadowed by a memory of the same name in a namespace of from
+	for ns := namespace; ns != ""; ns = NamespaceOf(ns) {
+		if s.exists(ns + "/" + name) {
+			return "/" + name
+		}
+	}
+	return name
+}
+
+// cleanName normalizes a path-style name, dropping leading slashes and "." segments and any
+// ".." segments that would leave the memory directory
+func cleanName(name string) string {
+	name = path.Clean("/" + name)
+	return strings.TrimPrefix(name, "/")
+}
+
+// exists reports whether a memory file exists
+func (s *Store) exists(name string) bool {
+	_, err := os.Stat(s.path(name))
+	return err == nil
+}
+
+// listNames lists memory names, including those in subdirectories, in name order. Hidden files
+// and directories, such as the archive, are skipped. The caller must hold the lock.
+func (s *Store) listNames() ([]string, error) {
+	names := []string{}
+	err := filepath.WalkDir(s.basePath, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == s.basePath {
+				return filepath.SkipAll
+			}
+			return err
+		}
+
+		if p != s.basePath && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.basePath, p)
+		if err != nil {
+			return err
+		}
+		names = append(names, strings.TrimSuffix(filepath.ToSlash(rel), ".md"))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memories: %w", err)
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// pruneEmptyDirs removes the directory of a memory and its parents while they are empty,
+// stopping at the memory directory
+func (s *Store) pruneEmptyDirs(name string) {
+	for ns := NamespaceOf(name); ns != ""; ns = NamespaceOf(ns) {
+		if err := os.Remove(filepath.Join(s.basePath, filepath.FromSlash(ns))); err != nil {
+			return // Not empty, or already gone
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newNamespaceStore(t *testing.T, names ...string) *Store {
+	t.Helper()
+	store := NewStore(t.TempDir())
+	for _, name := range names {
+		if err := store.Create(name, "---\ntitle: "+name+"\n---\nBody of "+name+"\n"); err != nil {
+			t.Fatalf("Create(%q) error = %v", name, err)
+		}
+	}
+	return store
+}
+
+func TestStoreListNamespaces(t *testing.T) {
+	store := newNamespaceStore(t, "overview", "project/readme", "project/db/schema", "project/db/migrations")
+	if err := os.MkdirAll(filepath.Join(store.basePath, archiveDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(store.basePath, archiveDir, "old.md"), []byte("archived"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	want := []string{"overview", "project/db/migrations", "project/db/schema", "project/readme"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("List() = %v, want %v", names, want)
+	}
+
+	namespaces, err := store.Namespaces("project/")
+	if err != nil {
+		t.Fatalf("Namespaces() error = %v", err)
+	}
+	wantNamespaces := []NamespaceInfo{
+		{Namespace: "project", Memories: 1, Total: 3},
+		{Namespace: "project/db", Memories: 2, Total: 2},
+	}
+	if !reflect.DeepEqual(namespaces, wantNamespaces) {
+		t.Errorf("Namespaces() = %+v, want %+v", namespaces, wantNamespaces)
+	}
+
+	if md := FormatNamespacesMarkdown(namespaces); !strings.Contains(md, "  - **db/** (2 memories, 2 in total)") {
+		t.Errorf("expected db nested under project, got:\n%s", md)
+	}
+
+	// Deleting the last memory of a namespace removes its directory
+	for _, name := range []string{"project/db/schema", "project/db/migrations"} {
+		if err := store.Delete(name); err != nil {
+			t.Fatalf("Delete(%q) error = %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(store.basePath, "project", "db")); !os.IsNotExist(err) {
+		t.Errorf("expected empty namespace directory to be removed, got %v", err)
+	}
+}
+
+func TestResolveLink(t *testing.T) {
+	store := newNamespaceStore(t, "schema", "glossary", "project/glossary", "project/db/schema", "project/db/migrations")
+
+	tests := []struct {
+		from, target, want string
+	}{
+		{"project/db/migrations", "schema", "project/db/schema"},
+		{"project/db/migrations", "glossary", "project/glossary"},
+		{"project/db/migrations", "/schema", "schema"},
+		{"project/db/migrations", "./schema", "project/db/schema"},
+		{"project/db/migrations", "../readme", "project/readme"},
+		{"project/db/migrations", "project/glossary", "project/glossary"},
+		{"project/db/migrations", "missing", "missing"},
+		{"overview", "schema", "schema"},
+		{"overview", "../../escape", "escape"},
+	}
+
+	for _, tt := range tests {
+		if got := store.ResolveLink(tt.from, tt.target); got != tt.want {
+			t.Errorf("ResolveLink(%q, %q) = %q, want %q", tt.from, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestLinkReference(t *testing.T) {
+	store := newNamespaceStore(t, "schema", "glossary", "project/db/schema")
+
+	tests := []struct {
+		from, name, want string
+	}{
+		{"project/db/migrations", "project/db/schema", "schema"},
+		{"project/db/migrations", "schema", "/schema"},
+		{"project/db/migrations", "glossary", "glossary"},
+		{"overview", "project/db/schema", "project/db/schema"},
+	}
+
+	for _, tt := range tests {
+		got := store.linkReference(tt.from, tt.name)
+		if got != tt.want {
+			t.Errorf("linkReference(%q, %q) = %q, want %q", tt.from, tt.name, got, tt.want)
+		}
+		if resolved := store.ResolveLink(tt.from, got); resolved != tt.name {
+			t.Errorf("reference %q from %q resolves to %q, want %q", got, tt.from, resolved, tt.name)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jcdickinson/simplemem/internal/db"
+)
+
+// ReconcileReport lists the memories whose database rows were removed because their files are gone
+type ReconcileReport struct {
+	Checked int      `json:"checked" jsonschema:"description=Memories in the database that were checked"`
+	Removed []string `json:"removed" jsonschema:"description=Memories whose files were deleted, in name order"`
+	DryRun  bool     `json:"dry_run,omitempty" jsonschema:"description=Whether the memories were only reported, not removed"`
+}
+
+// OpenIndex opens the database of a memory directory without the embedding client and without
+// syncing the files first, for maintenance that only compares the index with the files, such as
+// Reconcile. The caller must close the store.
+func OpenIndex(basePath, dbPath string) (*EnhancedStore, error) {
+	database, err := db.New(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return &EnhancedStore{Store: NewStore(basePath), db: database, dbPath: dbPath}, nil
+}
+
+// Reconcile removes the database rows (tags, embeddings, links and semantic backlinks) of memories
+// whose files were deleted outside simplemem, so searches stop returning them. With dryRun, the
+// memories are only reported.
+func (es *EnhancedStore) Reconcile(dryRun bool) (*ReconcileReport, error) {
+	indexed, err := es.db.GetMemoryNames()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexed memories: %w", err)
+	}
+
+	report := &ReconcileReport{Checked: len(indexed), Removed: []string{}, DryRun: dryRun}
+	for _, name := range indexed {
+		if _, err := os.Stat(es.path(name)); err == nil {
+			continue
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to check memory %s: %w", name, err)
+		}
+
+		if !dryRun {
+			if _, err := es.pruneMemory(name); err != nil {
+				return nil, err
+			}
+		}
+		report.Removed = append(report.Removed, name)
+	}
+
+	sort.Strings(report.Removed)
+	return report, nil
+}
+
+// pruneMemory removes a memory whose file is gone from the database, reporting whether it was
+// there. A memory whose file is back by the time its lock is taken is kept.
+func (es *EnhancedStore) pruneMemory(name string) (bool, error) {
+	defer es.syncLocks.lock(name)()
+
+	if _, err := os.Stat(es.path(name)); err == nil {
+		return false, nil
+	}
+	existing, err := es.db.GetMemory(name)
+	if err != nil {
+		return false, fmt.Errorf("failed to check indexed memory %s: %w", name, err)
+	}
+	if existing == nil {
+		return false, nil
+	}
+	if err := es.db.DeleteMemory(name); err != nil {
+		return false, fmt.Errorf("failed to remove memory %s from the database: %w", name, err)
+	}
+	return true, nil
+}
+
+// FormatReconcileReportMarkdown formats a reconciliation report
+func FormatReconcileReportMarkdown(report *ReconcileReport) string {
+	var md strings.Builder
+	md.WriteString("# Reconcile\n\n")
+
+	if len(report.Removed) == 0 {
+		md.WriteString(fmt.Sprintf("Checked %d memories; the database matches the memory files.\n", report.Checked))
+		return md.String()
+	}
+
+	verb := "Removed"
+	if report.DryRun {
+		verb = "Would remove"
+	}
+	md.WriteString(fmt.Sprintf("Checked %d memories. %s %d whose files were deleted:\n\n", report.Checked, verb, len(report.Removed)))
+	for _, name := range report.Removed {
+		md.WriteString(fmt.Sprintf("- %s\n", name))
+	}
+	return md.String()
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jcdickinson/simplemem/internal/db"
+)
+
+func TestReconcile(t *testing.T) {
+	dir := t.TempDir()
+	es, err := OpenIndex(filepath.Join(dir, "memories"), filepath.Join(dir, "test.duckdb"))
+	if err != nil {
+		t.Fatalf("OpenIndex() error = %v", err)
+	}
+	defer es.Close()
+
+	for _, name := range []string{"kept", "gone", "project/gone"} {
+		if err := es.Store.Create(name, "Body.\n"); err != nil {
+			t.Fatal(err)
+		}
+		if err := es.db.UpsertMemory(&db.Memory{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"gone", "project/gone"} {
+		if err := os.Remove(es.path(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	indexed := func() []string {
+		t.Helper()
+		names, err := es.db.GetMemoryNames()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var list []string
+		for _, name := range names {
+			list = append(list, name)
+		}
+		return list
+	}
+
+	report, err := es.Reconcile(true)
+	if err != nil {
+		t.Fatalf("Reconcile(dry run) error = %v", err)
+	}
+	if want := []string{"gone", "project/gone"}; report.Checked != 3 || !reflect.DeepEqual(report.Removed, want) {
+		t.Errorf("Reconcile(dry run) = %+v, want %v removed", report, want)
+	}
+	if got := indexed(); len(got) != 3 {
+		t.Errorf("expected a dry run to keep every row, got %v", got)
+	}
+
+	// A file that is back by the time it is pruned is kept
+	if err := os.WriteFile(es.path("gone"), []byte("Back.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if removed, err := es.pruneMemory("gone"); err != nil || removed {
+		t.Errorf("pruneMemory() of a restored file = %v, %v, want it kept", removed, err)
+	}
+	if err := os.Remove(es.path("gone")); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = es.Reconcile(false)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if len(report.Removed) != 2 {
+		t.Errorf("Reconcile() = %+v, want two removed", report)
+	}
+	if got := indexed(); !reflect.DeepEqual(got, []string{"kept"}) {
+		t.Errorf("indexed memories = %v, want [kept]", got)
+	}
+}
@@ -0,0 +1,329 @@
+package memory
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jcdickinson/simplemem/internal/config"
+)
+
+// Built-in relation types between memories
+const (
+	RelationDependsOn  = "depends_on"
+	RelationSupersedes = "supersedes"
+	RelationBlocks     = "blocks"
+	RelationImplements = "implements"
+	RelationSeeAlso    = "see_also"
+)
+
+// DefaultRelationTypes lists the relation types available when none are configured
+var DefaultRelationTypes = []string{RelationDependsOn, RelationSupersedes, RelationBlocks, RelationImplements, RelationSeeAlso}
+
+// DefaultRelationInverses names the built-in relations as seen from their targets
+var DefaultRelationInverses = map[string]string{
+	RelationDependsOn:  "required_by",
+	RelationSupersedes: "superseded_by",
+	RelationBlocks:     "blocked_by",
+	RelationImplements: "implemented_by",
+	RelationSeeAlso:    RelationSeeAlso,
+}
+
+var relationNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// RelationSchema holds the known relation types and their inverses
+type RelationSchema struct {
+	types    []string          // Relation types followed by inverse names, without duplicates
+	inverses map[string]string // Both directions: type → inverse and inverse → type
+}
+
+// Relation is a typed link between two memories, from the point of view of one of them
+type Relation struct {
+	Type     string `json:"type" jsonschema:"description=Relation type from this memory's point of view (the configured inverse for incoming relations)"`
+	Name     string `json:"name" jsonschema:"description=Memory at the other end of the relation"`
+	Incoming bool   `json:"incoming,omitempty" jsonschema:"description=True if the relation is declared by the other memory"`
+}
+
+// NewRelationSchema builds the relation schema from configuration, falling back to the built-in
+// relation types and inverses
+func NewRelationSchema(cfg config.RelationsConfig) (*RelationSchema, error) {
+	declared := cfg.Types
+	if len(declared) == 0 {
+		declared = DefaultRelationTypes
+	}
+
+	schema := &RelationSchema{inverses: make(map[string]string)}
+	add := func(name string) error {
+		if !relationNameRegex.MatchString(name) {
+			return fmt.Errorf("invalid relation type '%s': use lowercase letters, digits and underscores", name)
+		}
+		if containsString(EdgeTypes, name) {
+			return fmt.Errorf("relation type '%s' is reserved for link edges", name)
+		}
+		if !containsString(schema.types, name) {
+			schema.types = append(schema.types, name)
+		}
+		return nil
+	}
+
+	for _, relation := range declared {
+		if err := add(relation); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, relation := range declared {
+		inverse, ok := cfg.Inverses[relation]
+		if !ok {
+			inverse = DefaultRelationInverses[relation]
+		}
+		if inverse == "" {
+			continue
+		}
+		if err := add(inverse); err != nil {
+			return nil, err
+		}
+		schema.inverses[relation] = inverse
+		schema.inverses[inverse] = relation
+	}
+
+	return schema, nil
+}
+
+// Types returns every relation type that may be declared, including inverse names
+func (rs *RelationSchema) Types() []string {
+	return append([]string(nil), rs.types...)
+}
+
+// IsRelation reports whether a type is a known relation type or inverse name
+func (rs *RelationSchema) IsRelation(relation string) bool {
+	return containsString(rs.types, relation)
+}
+
+// Inverse returns the name of a relation as seen from its target, or "" if none is configured
+func (rs *RelationSchema) Inverse(relation string) string {
+	return rs.inverses[relation]
+}
+
+// validate checks that every relation declared in frontmatter has a known type
+func (rs *RelationSchema) validate(fm *Frontmatter) error {
+	for relation := range fm.Relations {
+		if !rs.IsRelation(relation) {
+			return fmt.Errorf("unknown relation type '%s' (expected one of: %s)", relation, strings.Join(rs.types, ", "))
+		}
+	}
+	return nil
+}
+
+// ValidateRelations checks the relation types declared in a document's frontmatter
+func (es *EnhancedStore) ValidateRelations(fm *Frontmatter) error {
+	return es.relations.validate(fm)
+}
+
+// RelationTypes returns every relation type that may be declared, including inverse names
+func (es *EnhancedStore) RelationTypes() []string {
+	return es.relations.Types()
+}
+
+// EdgeTypes returns the link edge types followed by the relation types
+func (es *EnhancedStore) EdgeTypes() []string {
+	return append(append([]string(nil), EdgeTypes...), es.relations.Types()...)
+}
+
+// Relations returns a memory's outgoing relations followed by relations declared by other
+// memories, named by their inverse where one is configured
+func (es *EnhancedStore) Relations(name string) ([]Relation, error) {
+	relations := []Relation{}
+
+	memory, err := es.db.GetMemory(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memory: %w", err)
+	}
+	if memory == nil {
+		return relations, nil
+	}
+
+	type relationKey struct{ relationType, name string }
+	seen := make(map[relationKey]bool)
+
+	outbound, err := es.db.GetOutboundLinks(memory.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range outbound {
+		key := relationKey{link.LinkType, link.ToMemoryName}
+		if containsString(EdgeTypes, link.LinkType) || seen[key] {
+			continue
+		}
+		seen[key] = true
+		relations = append(relations, Relation{Type: link.LinkType, Name: link.ToMemoryName})
+	}
+
+	inbound, err := es.db.GetInboundLinks(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var incoming []Relation
+	for _, link := range inbound {
+		if containsString(EdgeTypes, link.Link.LinkType) || link.Source.Name == name {
+			continue
+		}
+
+		relationType := link.Link.LinkType
+		if inverse := es.relations.Inverse(relationType); inverse != "" {
+			relationType = inverse
+		}
+
+		// A relation declared on both ends is reported once, as outgoing
+		key := relationKey{relationType, link.Source.Name}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		incoming = append(incoming, Relation{Type: relationType, Name: link.Source.Name, Incoming: true})
+	}
+
+	sort.Slice(incoming, func(i, j int) bool {
+		if incoming[i].Type != incoming[j].Type {
+			return incoming[i].Type < incoming[j].Type
+		}
+		return incoming[i].Name < incoming[j].Name
+	})
+
+	return append(relations, incoming...), nil
+}
+
+// LinkMemories adds targets to a relation in a memory's frontmatter, returning the targets that
+// were not already present
+func (es *EnhancedStore) LinkMemories(name, relation string, targets []string) ([]string, error) {
+	if !es.relations.IsRelation(relation) {
+		return nil, fmt.Errorf("unknown relation type '%s' (expected one of: %s)", relation, strings.Join(es.relations.types, ", "))
+	}
+	for _, target := range targets {
+		if target == name {
+			return nil, fmt.Errorf("a memory cannot be related to itself")
+		}
+		if _, err := os.Stat(es.Store.path(target)); err != nil {
+			return nil, fmt.Errorf("memory %s not found", target)
+		}
+	}
+
+	return es.editRelations(name, func(fm *Frontmatter) []string {
+		return es.Store.addRelationTargets(name, fm, relation, targets)
+	})
+}
+
+// UnlinkMemories removes targets from a relation in a memory's frontmatter, or from every relation
+// if relation is empty, returning the targets that were removed
+func (es *EnhancedStore) UnlinkMemories(name, relation string, targets []string) ([]string, error) {
+	if relation != "" && !es.relations.IsRelation(relation) {
+		return nil, fmt.Errorf("unknown relation type '%s' (expected one of: %s)", relation, strings.Join(es.relations.types, ", "))
+	}
+
+	return es.editRelations(name, func(fm *Frontmatter) []string {
+		return es.Store.removeRelationTargets(name, fm, relation, targets)
+	})
+}
+
+// editRelations applies a change to a memory's frontmatter relations and saves the memory if
+// anything changed
+func (es *EnhancedStore) editRelations(name string, edit func(fm *Frontmatter) []string) ([]string, error) {
+	content, err := es.Store.Read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fm, body, err := ParseDocument(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse memory document: %w", err)
+	}
+
+	changed := edit(fm)
+	if len(changed) == 0 {
+		return changed, nil
+	}
+
+	updated, err := FormatDocument(fm, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format updated document: %w", err)
+	}
+
+	if err := es.Update(name, updated); err != nil {
+		return nil, fmt.Errorf("failed to update memory: %w", err)
+	}
+	return changed, nil
+}
+
+// addRelationTargets appends targets missing from a relation of memory from, returning the
+// ones added. Targets are written as the shortest link that resolves to them.
+func (s *Store) addRelationTargets(from string, fm *Frontmatter, relation string, targets []string) []string {
+	added := []string{}
+	for _, target := range targets {
+		if s.relationIndex(from, fm.Relations[relation], target) >= 0 || containsString(added, target) {
+			continue
+		}
+		if fm.Relations == nil {
+			fm.Relations = make(map[string][]string)
+		}
+		fm.Relations[relation] = append(fm.Relations[relation], s.linkReference(from, target))
+		added = append(added, target)
+	}
+	return added
+}
+
+// removeRelationTargets drops targets from a relation of memory from, or from every relation if
+// relation is empty, returning the ones removed. Relations left without targets are deleted.
+func (s *Store) removeRelationTargets(from string, fm *Frontmatter, relation string, targets []string) []string {
+	removed := []string{}
+	for relationType, current := range fm.Relations {
+		if relation != "" && relationType != relation {
+			continue
+		}
+
+		var kept []string
+		for _, entry := range current {
+			if target := s.ResolveLink(from, LinkTargetName(entry)); containsString(targets, target) {
+				if !containsString(removed, target) {
+					removed = append(removed, target)
+				}
+				continue
+			}
+			kept = append(kept, entry)
+		}
+
+		if len(kept) == 0 {
+			delete(fm.Relations, relationType)
+		} else {
+			fm.Relations[relationType] = kept
+		}
+	}
+
+	sort.Strings(removed)
+	return removed
+}
+
+// relationIndex returns the position of a target in the relation entries of memory from, or -1
+func (s *Store) relationIndex(from string, entries []string, target string) int {
+	for i, entry := range entries {
+		if s.ResolveLink(from, LinkTargetName(entry)) == target {
+			return i
+		}
+	}
+	return -1
+}
+
+// FormatRelationsMarkdown formats a memory's relations as a markdown list
+func FormatRelationsMarkdown(relations []Relation) string {
+	var md strings.Builder
+	for _, relation := range relations {
+		if relation.Incoming {
+			md.WriteString(fmt.Sprintf("- %s **%s** (incoming)\n", relation.Type, relation.Name))
+		} else {
+			md.WriteString(fmt.Sprintf("- %s **%s**\n", relation.Type, relation.Name))
+		}
+	}
+	return md.String()
+}
@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jcdickinson/simplemem/internal/config"
+)
+
+func TestNewRelationSchema(t *testing.T) {
+	schema, err := NewRelationSchema(config.RelationsConfig{})
+	if err != nil {
+		t.Fatalf("NewRelationSchema() error = %v", err)
+	}
+	if !schema.IsRelation(RelationBlocks) || !schema.IsRelation("blocked_by") {
+		t.Errorf("expected built-in relations and inverses, got %v", schema.Types())
+	}
+	if got := schema.Inverse("blocked_by"); got != RelationBlocks {
+		t.Errorf("Inverse(blocked_by) = %q, want %q", got, RelationBlocks)
+	}
+	if got := schema.Inverse(RelationSeeAlso); got != RelationSeeAlso {
+		t.Errorf("Inverse(see_also) = %q, want see_also", got)
+	}
+
+	schema, err = NewRelationSchema(config.RelationsConfig{
+		Types:    []string{"parent", "depends_on"},
+		Inverses: map[string]string{"parent": "child", "depends_on": ""},
+	})
+	if err != nil {
+		t.Fatalf("NewRelationSchema() error = %v", err)
+	}
+	if want := []string{"parent", "depends_on", "child"}; !reflect.DeepEqual(schema.Types(), want) {
+		t.Errorf("Types() = %v, want %v", schema.Types(), want)
+	}
+	if schema.Inverse("depends_on") != "" || schema.IsRelation(RelationBlocks) {
+		t.Errorf("expected configured types to replace the built-in ones")
+	}
+
+	for _, types := range [][]string{{"wiki"}, {"Depends On"}} {
+		if _, err := NewRelationSchema(config.RelationsConfig{Types: types}); err == nil {
+			t.Errorf("expected error for relation types %v", types)
+		}
+	}
+}
+
+func TestEditRelationTargets(t *testing.T) {
+	store := NewStore(t.TempDir())
+	fm := &Frontmatter{Relations: map[string][]string{"depends_on": {"a"}, "blocks": {"[[b]]", "c"}}}
+
+	if added := store.addRelationTargets("x", fm, "depends_on", []string{"a", "d", "d"}); !reflect.DeepEqual(added, []string{"d"}) {
+		t.Errorf("addRelationTargets() = %v, want [d]", added)
+	}
+
+	if removed := store.removeRelationTargets("x", fm, "", []string{"b", "d", "missing"}); !reflect.DeepEqual(removed, []string{"b", "d"}) {
+		t.Errorf("removeRelationTargets() = %v, want [b d]", removed)
+	}
+
+	want := map[string][]string{"depends_on": {"a"}, "blocks": {"c"}}
+	if !reflect.DeepEqual(fm.Relations, want) {
+		t.Errorf("relations = %v, want %v", fm.Relations, want)
+	}
+
+	store.removeRelationTargets("x", fm, "blocks", []string{"c"})
+	if _, ok := fm.Relations["blocks"]; ok {
+		t.Errorf("expected empty relation to be deleted, got %v", fm.Relations)
+	}
+}
+
+func TestSetMetadata(t *testing.T) {
+	fm := &Frontmatter{}
+	if err := fm.SetMetadata("relations", map[string]interface{}{"depends_on": []interface{}{"a", "b"}, "see_also": "c"}); err != nil {
+		t.Fatalf("SetMetadata(relations) error = %v", err)
+	}
+	if err := fm.SetMetadata("links", []interface{}{"d"}); err != nil {
+		t.Fatalf("SetMetadata(links) error = %v", err)
+	}
+	if err := fm.SetMetadata("priority", "high"); err != nil {
+		t.Fatalf("SetMetadata(priority) error = %v", err)
+	}
+
+	if !reflect.DeepEqual(fm.Relations, map[string][]string{"depends_on": {"a", "b"}, "see_also": {"c"}}) ||
+		!reflect.DeepEqual(fm.Links, []string{"d"}) || fm.Metadata["priority"] != "high" {
+		t.Errorf("unexpected frontmatter: %+v", fm)
+	}
+
+	// Dedicated fields must not leak into the inline metadata map, which YAML cannot encode
+	if _, err := FormatDocument(fm, "body"); err != nil {
+		t.Errorf("FormatDocument() error = %v", err)
+	}
+
+	if err := fm.SetMetadata("relations", map[string]interface{}{"depends_on": []interface{}{1}}); err == nil {
+		t.Errorf("expected error for non-string relation target")
+	}
+}
@@ -0,0 +1,360 @@
+package memory
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// Wiki links with optional heading anchor or alias: [[target#section|alias]]
+	wikiTargetRegex = regexp.MustCompile(`\[\[([^\]|#]+)([^\]]*)\]\]`)
+	// Markdown link destinations with optional title: [text](target.md "title")
+	markdownTargetRegex = regexp.MustCompile(`\]\(([^)\s]+)([^)]*)\)`)
+)
+
+// RenameResult describes a completed rename
+type RenameResult struct {
+	OldName         string
+	NewName         string
+	UpdatedMemories []string // Memories whose links were rewritten
+	LinksRewritten  int
+	Redirect        bool // Whether a redirect stub was left at the old name
+}
+
+// fileChange records a file write so it can be undone
+type fileChange struct {
+	name     string
+	original []byte // nil if the file did not exist before
+}
+
+// Rename renames a memory, keeping its embeddings and backlinks, and rewrites wiki, markdown
+// and frontmatter links and relations to it across the store. With redirect, a stub linking to
+// the new name is left at the old name. File changes are rolled back if any step fails.
+func (es *EnhancedStore) Rename(oldName, newName string, redirect bool) (*RenameResult, error) {
+	oldName = strings.TrimSuffix(oldName, ".md")
+	newName = strings.TrimSuffix(newName, ".md")
+
+	if newName == "" {
+		return nil, fmt.Errorf("new memory name is required")
+	}
+	if err := ValidateName(newName); err != nil {
+		return nil, err
+	}
+	if oldName == newName {
+		return nil, fmt.Errorf("new name is the same as the current name")
+	}
+	if err := checkName(oldName); err != nil {
+		return nil, err
+	}
+
+	unlock := es.syncLocks.lock(oldName, newName)
+	result, err := es.renameLocked(oldName, newName, redirect)
+	unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// Sync everything that changed; unchanged content is not re-embedded
+	synced := append([]string{newName}, result.UpdatedMemories...)
+	if redirect {
+		synced = append(synced, oldName)
+	}
+	for _, name := range synced {
+		if err := es.syncMemoryToDatabase(name); err != nil {
+			log.Printf("Warning: failed to sync memory %s to database: %v", name, err)
+		}
+	}
+
+	log.Printf("[RENAME] Renamed %s to %s, rewrote %d links in %d memories", oldName, newName, result.LinksRewritten, len(result.UpdatedMemories))
+	return result, nil
+}
+
+// renameLocked plans and applies the file changes of a rename and renames the database row,
+// holding the store lock throughout so no other write can slip in between reading a memory
+// and rewriting its links
+func (es *EnhancedStore) renameLocked(oldName, newName string, redirect bool) (*RenameResult, error) {
+	defer es.Store.lock()()
+
+	if _, err := os.Stat(es.Store.path(oldName)); err != nil {
+		return nil, fmt.Errorf("memory %s not found", oldName)
+	}
+	if _, err := os.Stat(es.Store.path(newName)); err == nil {
+		return nil, fmt.Errorf("memory %s already exists", newName)
+	}
+	if existing, err := es.Store.nameCollision(newName, oldName); err != nil {
+		return nil, err
+	} else if existing != "" {
+		return nil, &NameCollisionError{Name: newName, Existing: existing}
+	}
+
+	// Plan every link rewrite before touching any file
+	names, err := es.Store.listNames()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RenameResult{OldName: oldName, NewName: newName, UpdatedMemories: []string{}, Redirect: redirect}
+	rewrites := make(map[string]string)
+	for _, name := range names {
+		data, err := os.ReadFile(es.Store.path(name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read memory %s: %w", name, err)
+		}
+		content := string(data)
+
+		var updated string
+		var count int
+		if name == oldName && NamespaceOf(oldName) != NamespaceOf(newName) {
+			// Relative links of a memory moving to another namespace are rewritten as well
+			updated, count, err = es.Store.relocateLinks(content, oldName, newName)
+		} else {
+			updated, count, err = es.Store.rewriteLinksTo(name, content, oldName, newName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewrite links in memory %s: %w", name, err)
+		}
+		if count > 0 {
+			rewrites[name] = updated
+			result.LinksRewritten += count
+			if name != oldName {
+				result.UpdatedMemories = append(result.UpdatedMemories, name)
+			}
+		}
+	}
+
+	// Apply file changes, undoing them all on the first failure
+	var changes []fileChange
+	rollback := func(cause error) (*RenameResult, error) {
+		for i := len(changes) - 1; i >= 0; i-- {
+			if err := es.Store.restoreFile(changes[i].name, changes[i].original); err != nil {
+				log.Printf("[RENAME] ERROR: Failed to roll back %s: %v", changes[i].name, err)
+			}
+		}
+		if _, err := os.Stat(es.Store.path(oldName)); os.IsNotExist(err) {
+			if err := es.Store.moveFile(newName, oldName); err != nil {
+				log.Printf("[RENAME] ERROR: Failed to move %s back to %s: %v", newName, oldName, err)
+			}
+		}
+		return nil, cause
+	}
+
+	if err := es.Store.moveFile(oldName, newName); err != nil {
+		return nil, fmt.Errorf("failed to rename memory file: %w", err)
+	}
+
+	for name, content := range rewrites {
+		target := name
+		if name == oldName {
+			target = newName // Self-links inside the renamed memory
+		}
+		original, err := es.Store.writeFile(target, content, RevisionRelinked)
+		if err != nil {
+			return rollback(fmt.Errorf("failed to rewrite links in memory %s: %w", target, err))
+		}
+		changes = append(changes, fileChange{name: target, original: original})
+	}
+
+	if redirect {
+		if err := es.Store.create(oldName, redirectStub(oldName, newName)); err != nil {
+			return rollback(fmt.Errorf("failed to create redirect stub: %w", err))
+		}
+		changes = append(changes, fileChange{name: oldName})
+	}
+
+	// Rename the database row so embeddings and semantic backlinks are kept
+	if existing, err := es.db.GetMemory(oldName); err != nil {
+		return rollback(fmt.Errorf("failed to check database memory: %w", err))
+	} else if existing != nil {
+		if err := es.db.RenameMemory(oldName, newName); err != nil {
+			return rollback(fmt.Errorf("failed to rename memory in database: %w", err))
+		}
+	}
+	return result, nil
+}
+
+// redirectStub builds the document left at the old name of a renamed memory
+func redirectStub(oldName, newName string) string {
+	fm := &Frontmatter{
+		Title:       fmt.Sprintf("Renamed: %s", oldName),
+		Description: fmt.Sprintf("This memory was renamed to %s", newName),
+		Tags:        map[string]interface{}{"redirect": true},
+		Metadata:    map[string]interface{}{"redirect_to": newName},
+	}
+
+	content, err := FormatDocument(fm, fmt.Sprintf("This memory was renamed to [[%s]].\n", newName))
+	if err != nil {
+		// Frontmatter of plain strings always marshals; fall back to the bare body regardless
+		return fmt.Sprintf("This memory was renamed to [[%s]].\n", newName)
+	}
+	return content
+}
+
+// relinkFunc maps the target name of a link, as returned by LinkTargetName, to the link target
+// it should be replaced with, reporting whether the link changes
+type relinkFunc func(name string) (string, bool)
+
+// rewriteLinksTo rewrites the links in a document of memory from that refer to oldName so they
+// refer to newName, returning the updated document and the number of links changed
+func (s *Store) rewriteLinksTo(from, content, oldName, newName string) (string, int, error) {
+	reference := s.linkReference(from, newName)
+	return relinkDocument(content, func(name string) (string, bool) {
+		return reference, s.ResolveLink(from, name) == oldName
+	})
+}
+
+// relocateLinks rewrites the links in the document of a memory moving from oldName to newName
+// so relative links keep referring to the same memories and links to itself follow the move
+func (s *Store) relocateLinks(content, oldName, newName string) (string, int, error) {
+	return relinkDocument(content, func(name string) (string, bool) {
+		target := s.ResolveLink(oldName, name)
+		if target == oldName {
+			target = newName
+		}
+		reference := s.linkReference(newName, target)
+		return reference, reference != name
+	})
+}
+
+// relinkDocument rewrites the links in a document's body, frontmatter links and relations,
+// returning the updated document and the number of links changed
+func relinkDocument(content string, relink relinkFunc) (string, int, error) {
+	fm, body, err := ParseDocument(content)
+	if err != nil {
+		// Unparseable frontmatter: rewrite the whole document as body text
+		updated, count := relinkText(content, relink)
+		return updated, count, nil
+	}
+
+	body, count := relinkText(body, relink)
+	for i, link := range fm.Links {
+		if reference, ok := relink(LinkTargetName(link)); ok {
+			fm.Links[i] = replaceLinkTarget(link, reference)
+			count++
+		}
+	}
+	for _, targets := range fm.Relations {
+		for i, target := range targets {
+			if reference, ok := relink(LinkTargetName(target)); ok {
+				targets[i] = replaceLinkTarget(target, reference)
+				count++
+			}
+		}
+	}
+
+	if count == 0 {
+		return content, 0, nil
+	}
+
+	fm.UpdateTimestamps(false)
+	updated, err := FormatDocument(fm, body)
+	if err != nil {
+		return "", 0, err
+	}
+	return updated, count, nil
+}
+
+// relinkText rewrites wiki and markdown links to memories in markdown text
+func relinkText(text string, relink relinkFunc) (string, int) {
+	count := 0
+
+	text = wikiTargetRegex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := wikiTargetRegex.FindStringSubmatch(match)
+		reference, ok := relink(LinkTargetName(parts[1]))
+		if !ok {
+			return match
+		}
+		count++
+		return "[[" + replaceLinkTarget(parts[1], reference) + parts[2] + "]]"
+	})
+
+	text = markdownTargetRegex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := markdownTargetRegex.FindStringSubmatch(match)
+		if !strings.Contains(parts[1], ".md") {
+			return match
+		}
+		reference, ok := relink(LinkTargetName(parts[1]))
+		if !ok {
+			return match
+		}
+		count++
+		return "](" + replaceLinkTarget(parts[1], reference) + parts[2] + ")"
+	})
+
+	return text, count
+}
+
+// replaceLinkTarget swaps the memory name in a link target, keeping any "./" prefix, ".md"
+// extension, wiki brackets and anchor
+func replaceLinkTarget(target, reference string) string {
+	name := LinkTargetName(target)
+	i := strings.Index(target, name)
+	if name == "" || i < 0 {
+		return target
+	}
+
+	prefix := target[:i]
+	if strings.Contains(reference, "/") {
+		prefix = strings.TrimSuffix(prefix, "./") // "./" would make a full name relative
+	}
+	return prefix + reference + target[i+len(name):]
+}
+
+// path returns the file path of a memory; path-style names map to subdirectories. Names are
+// cleaned first, so even an unvalidated name cannot point outside the memory directory.
+func (s *Store) path(name string) string {
+	return filepath.Join(s.basePath, filepath.FromSlash(cleanName(strings.TrimSuffix(name, ".md"))+".md"))
+}
+
+// moveFile renames a memory file without changing its content, moving it between namespace
+// directories as needed. The caller must hold the lock.
+func (s *Store) moveFile(oldName, newName string) error {
+	if err := os.MkdirAll(filepath.Dir(s.path(newName)), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(s.path(oldName), s.path(newName)); err != nil {
+		return err
+	}
+	s.written.note(oldName, nil)
+	if data, err := os.ReadFile(s.path(newName)); err == nil {
+		s.written.note(newName, data)
+	}
+
+	s.pruneEmptyDirs(oldName)
+	s.moveHistory(oldName, newName)
+	return nil
+}
+
+// writeFile replaces a memory file verbatim, returning its previous content. The change is
+// recorded in the history with the given action. The caller must hold the lock.
+func (s *Store) writeFile(name, content, action string) ([]byte, error) {
+	original, err := os.ReadFile(s.path(name))
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(s.path(name), []byte(content), 0644); err != nil {
+		return original, err
+	}
+	s.recordRevision(name, original, []byte(content), action)
+	return original, nil
+}
+
+// restoreFile puts back a memory file's previous content, removing it if it did not exist. The
+// caller must hold the lock.
+func (s *Store) restoreFile(name string, original []byte) error {
+	if original == nil {
+		if err := os.Remove(s.path(name)); err != nil {
+			return err
+		}
+		s.pruneEmptyDirs(name)
+		return nil
+	}
+	if err := writeFileAtomic(s.path(name), original, 0644); err != nil {
+		return err
+	}
+	s.recordRevision(name, nil, original, RevisionRolledBack)
+	return nil
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jcdickinson/simplemem/internal/config"
+	"github.com/jcdickinson/simplemem/internal/db"
+)
+
+// newTestEnhancedStore creates an enhanced store with its database in a temporary directory. The
+// API key is fake, so memories are synced without embeddings.
+func newTestEnhancedStore(t *testing.T, memories map[string]string) *EnhancedStore {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.VoyageAI.ApiKey.Value = "test"
+	es, err := NewEnhancedStoreWithDBPath(filepath.Join(dir, "memories"), cfg, filepath.Join(dir, "test.duckdb"))
+	if err != nil {
+		t.Fatalf("NewEnhancedStoreWithDBPath() error = %v", err)
+	}
+	t.Cleanup(func() { es.db.Close() })
+
+	for name, content := range memories {
+		if err := es.Store.Create(name, content); err != nil {
+			t.Fatal(err)
+		}
+		es.syncMemoryToDatabase(name)
+	}
+	return es
+}
+
+func TestRename(t *testing.T) {
+	es := newTestEnhancedStore(t, map[string]string{
+		"target": "---\ntitle: Target\n---\nLinks to [[target]].\n",
+		"linker": "---\ntitle: Linker\n---\nSee [[target]] and [[other]].\n",
+	})
+
+	result, err := es.Rename("target", "renamed", true)
+	if err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	if strings.Join(result.UpdatedMemories, ",") != "linker" || result.LinksRewritten != 2 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if content, _ := es.Store.Read("linker"); !strings.Contains(content, "See [[renamed]] and [[other]].") {
+		t.Errorf("expected the link to be rewritten, got:\n%s", content)
+	}
+	if content, _ := es.Store.Read("renamed"); !strings.Contains(content, "Links to [[renamed]].") {
+		t.Errorf("expected the self-link to follow the rename, got:\n%s", content)
+	}
+	if content, _ := es.Store.Read("target"); !strings.Contains(content, "renamed to [[renamed]]") {
+		t.Errorf("expected a redirect stub, got:\n%s", content)
+	}
+	if memory, err := es.db.GetMemory("renamed"); err != nil || memory == nil || memory.Title != "Target" {
+		t.Errorf("GetMemory(renamed) = %+v, %v", memory, err)
+	}
+
+	// A database row under the new name fails the rename after the files changed
+	if err := es.db.UpsertMemory(&db.Memory{Name: "taken"}); err != nil {
+		t.Fatal(err)
+	}
+	linker, _ := es.Store.Read("linker")
+	if _, err := es.Rename("renamed", "taken", false); err == nil {
+		t.Fatal("expected the rename to fail")
+	}
+	if _, err := es.Store.Read("taken"); err == nil {
+		t.Error("expected the file move to be rolled back")
+	}
+	if _, err := es.Store.Read("renamed"); err != nil {
+		t.Errorf("expected the memory back under its name: %v", err)
+	}
+	if content, _ := es.Store.Read("linker"); content != linker {
+		t.Errorf("expected the link rewrite to be rolled back, got:\n%s", content)
+	}
+}
@@ -0,0 +1,213 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Resolution methods, in the order they are tried
+const (
+	ResolvedExact           = "exact"
+	ResolvedCaseInsensitive = "case-insensitive"
+	ResolvedTitle           = "title"
+	ResolvedNormalized      = "normalized"
+)
+
+// maxSuggestions caps the number of candidates listed in a not-found error
+const maxSuggestions = 5
+
+// NotFoundError reports a memory name that could not be resolved, with the closest candidates
+type NotFoundError struct {
+	Name        string
+	Suggestions []string
+}
+
+func (e *NotFoundError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("memory %s not found", e.Name)
+	}
+	return fmt.Sprintf("memory %s not found. Did you mean: %s?", e.Name, strings.Join(e.Suggestions, ", "))
+}
+
+// ResolveName maps a user-supplied memory name to an existing memory. It tries an exact match,
+// then a unique case-insensitive match, then a unique match on the frontmatter title, then the
+// name as create_memory would have normalized it. Anything
+// fuzzier is never applied automatically; instead a *NotFoundError lists the closest names by
+// edit distance.
+func (s *Store) ResolveName(name string) (string, string, error) {
+	resolved, method, candidates, err := s.resolveName(name)
+	if err != nil {
+		return "", "", err
+	}
+	if resolved != "" {
+		return resolved, method, nil
+	}
+
+	return "", "", &NotFoundError{
+		Name:        strings.TrimSuffix(name, ".md"),
+		Suggestions: nearestByEditDistance(strings.TrimSuffix(name, ".md"), candidates, maxSuggestions),
+	}
+}
+
+// resolveName performs the non-fuzzy resolution steps, returning all names when nothing matched
+func (s *Store) resolveName(name string) (string, string, []string, error) {
+	name = strings.TrimSuffix(name, ".md")
+
+	names, err := s.List()
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	// 1. Exact match
+	for _, candidate := range names {
+		if candidate == name {
+			return candidate, ResolvedExact, nil, nil
+		}
+	}
+
+	// 2. Case-insensitive match (only if unambiguous)
+	var folded []string
+	for _, candidate := range names {
+		if strings.EqualFold(candidate, name) {
+			folded = append(folded, candidate)
+		}
+	}
+	if len(folded) == 1 {
+		return folded[0], ResolvedCaseInsensitive, nil, nil
+	}
+
+	// 3. Frontmatter title match (only if unambiguous)
+	var titled []string
+	for _, candidate := range names {
+		info, err := s.ReadWithMetadata(candidate)
+		if err != nil {
+			continue
+		}
+		if info.Frontmatter.Title != "" && strings.EqualFold(strings.TrimSpace(info.Frontmatter.Title), strings.TrimSpace(name)) {
+			titled = append(titled, candidate)
+		}
+	}
+	if len(titled) == 1 {
+		return titled[0], ResolvedTitle, nil, nil
+	}
+
+	// 4. Normalized name match, e.g. "My Notes" for "my-notes"
+	if normalized, err := s.NormalizeName(name); err == nil && normalized != name {
+		for _, candidate := range names {
+			if candidate == normalized {
+				return candidate, ResolvedNormalized, nil, nil
+			}
+		}
+	}
+
+	return "", "", names, nil
+}
+
+// nearestByEditDistance returns up to limit candidates closest to name, ignoring case.
+// Candidates that are too far away to be plausible typos are dropped.
+func nearestByEditDistance(name string, candidates []string, limit int) []string {
+	target := strings.ToLower(name)
+
+	type scored struct {
+		name     string
+		distance int
+	}
+	var matches []scored
+	for _, candidate := range candidates {
+		lower := strings.ToLower(candidate)
+		distance := levenshtein(target, lower)
+
+		// Allow roughly one edit per three characters, and always treat containment as close
+		maxDistance := len([]rune(target))/3 + 1
+		if distance <= maxDistance || strings.Contains(lower, target) || strings.Contains(target, lower) {
+			matches = append(matches, scored{name: candidate, distance: distance})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	var result []string
+	for i := 0; i < len(matches) && i < limit; i++ {
+		result = append(result, matches[i].name)
+	}
+	return result
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// minInt returns the smallest of the given integers
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// suggestName picks the most plausible existing memory for a name that does not exist, given
+// all memory titles keyed by name. It returns an empty string when nothing is close.
+func suggestName(name string, titles map[string]string) string {
+	slug := func(s string) string {
+		return strings.ToLower(strings.NewReplacer(" ", "-", "_", "-").Replace(strings.TrimSpace(s)))
+	}
+
+	names := make([]string, 0, len(titles))
+	for candidate := range titles {
+		names = append(names, candidate)
+	}
+	sort.Strings(names)
+
+	// Same name up to case and word separators, then a matching title
+	for _, candidate := range names {
+		if slug(candidate) == slug(name) {
+			return candidate
+		}
+	}
+	for _, candidate := range names {
+		if titles[candidate] != "" && strings.EqualFold(strings.TrimSpace(titles[candidate]), strings.TrimSpace(name)) {
+			return candidate
+		}
+	}
+
+	if nearest := nearestByEditDistance(name, names, 1); len(nearest) > 0 {
+		return nearest[0]
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResolveName(t *testing.T) {
+	store := NewStore(t.TempDir())
+	if err := store.Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	docs := map[string]string{
+		"duckdb-schema":  "---\ntitle: DuckDB Schema\n---\nTables and sequences.",
+		"API-Guidelines": "---\ntitle: API Guidelines\n---\nREST conventions.",
+		"release-notes":  "---\ntitle: Release Notes\n---\nChanges.",
+	}
+	for name, content := range docs {
+		if err := store.Create(name, content); err != nil {
+			t.Fatalf("Create(%s) error = %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		input      string
+		wantName   string
+		wantMethod string
+	}{
+		{input: "duckdb-schema", wantName: "duckdb-schema", wantMethod: ResolvedExact},
+		{input: "duckdb-schema.md", wantName: "duckdb-schema", wantMethod: ResolvedExact},
+		{input: "api-guidelines", wantName: "API-Guidelines", wantMethod: ResolvedCaseInsensitive},
+		{input: "release notes", wantName: "release-notes", wantMethod: ResolvedTitle},
+		{input: "DuckDB Schema!", wantName: "duckdb-schema", wantMethod: ResolvedNormalized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			name, method, err := store.ResolveName(tt.input)
+			if err != nil {
+				t.Fatalf("ResolveName() error = %v", err)
+			}
+			if name != tt.wantName || method != tt.wantMethod {
+				t.Errorf("ResolveName() = (%s, %s), want (%s, %s)", name, method, tt.wantName, tt.wantMethod)
+			}
+		})
+	}
+
+	_, _, err := store.ResolveName("duckdb-schma")
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+	if !reflect.DeepEqual(notFound.Suggestions, []string{"duckdb-schema"}) {
+		t.Errorf("unexpected suggestions: %v", notFound.Suggestions)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"same", "same", 0},
+		{"café", "cafe", 1},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestName(t *testing.T) {
+	titles := map[string]string{
+		"duckdb-schema": "DuckDB Schema",
+		"voyage-config": "Voyage AI Configuration",
+	}
+
+	tests := map[string]string{
+		"DuckDB Schema":           "duckdb-schema",
+		"duckdb_schema":           "duckdb-schema",
+		"Voyage AI Configuration": "voyage-config",
+		"voyage-confg":            "voyage-config",
+		"something-else":          "",
+	}
+
+	for input, want := range tests {
+		if got := suggestName(input, titles); got != want {
+			t.Errorf("suggestName(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
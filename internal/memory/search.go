@@ -0,0 +1,189 @@
+package memory
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Text search modes
+const (
+	SearchModeLiteral    = "literal"     // Exact, case-sensitive substring
+	SearchModeIgnoreCase = "ignore_case" // Case-insensitive substring
+	SearchModeRegex      = "regex"       // RE2 regular expression
+)
+
+// SearchOptions controls a line-level text search over memory bodies
+type SearchOptions struct {
+	Mode         string            // One of the SearchMode constants (default: literal)
+	Tags         map[string]string // Tag filters; empty values only check for presence
+	RequireAll   bool              // Require all tag filters to match instead of any
+	NameGlob     string            // Optional glob on memory names, e.g. "project-*"
+	Namespace    string            // Optional namespace; only memories in it and its sub-namespaces are searched
+	ContextLines int               // Lines of context to include before and after each hit
+}
+
+// SearchMatch is a single matching line in a memory body
+type SearchMatch struct {
+	Name   string
+	Line   int // 1-based line number within the body
+	Text   string
+	Before []string
+	After  []string
+}
+
+// SearchWithOptions finds every body line matching query, ordered by memory name and line number
+func (s *Store) SearchWithOptions(query string, opts SearchOptions) ([]SearchMatch, error) {
+	matcher, err := newLineMatcher(query, opts.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.NameGlob != "" {
+		if _, err := path.Match(opts.NameGlob, ""); err != nil {
+			return nil, fmt.Errorf("invalid name glob '%s': %w", opts.NameGlob, err)
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names, err := s.listNames()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []SearchMatch{}
+	for _, name := range names {
+		if !InNamespace(name, opts.Namespace) {
+			continue
+		}
+		if opts.NameGlob != "" {
+			if ok, _ := path.Match(opts.NameGlob, name); !ok {
+				continue
+			}
+		}
+
+		content, err := os.ReadFile(s.path(name))
+		if err != nil {
+			continue
+		}
+
+		// Parse document to search in body only (not frontmatter)
+		fm, body, err := ParseDocument(string(content))
+		if err != nil {
+			fm = &Frontmatter{}
+			body = string(content)
+		}
+
+		if !matchesTagFilters(&MemoryInfo{Name: name, Frontmatter: fm}, opts.Tags, opts.RequireAll) {
+			continue
+		}
+
+		lines := strings.Split(body, "\n")
+		for i, line := range lines {
+			if !matcher(line) {
+				continue
+			}
+
+			match := SearchMatch{Name: name, Line: i + 1, Text: line}
+			if opts.ContextLines > 0 {
+				start := i - opts.ContextLines
+				if start < 0 {
+					start = 0
+				}
+				end := i + 1 + opts.ContextLines
+				if end > len(lines) {
+					end = len(lines)
+				}
+				match.Before = lines[start:i]
+				match.After = lines[i+1 : end]
+			}
+			matches = append(matches, match)
+		}
+	}
+
+	return matches, nil
+}
+
+// newLineMatcher builds the line predicate for a search mode
+func newLineMatcher(query string, mode string) (func(string) bool, error) {
+	if query == "" {
+		return nil, fmt.Errorf("search pattern is required")
+	}
+
+	switch mode {
+	case "", SearchModeLiteral:
+		return func(line string) bool {
+			return strings.Contains(line, query)
+		}, nil
+	case SearchModeIgnoreCase:
+		queryLower := strings.ToLower(query)
+		return func(line string) bool {
+			return strings.Contains(strings.ToLower(line), queryLower)
+		}, nil
+	case SearchModeRegex:
+		re, err := regexp.Compile(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+		return re.MatchString, nil
+	default:
+		return nil, fmt.Errorf("unknown search mode '%s' (expected %s, %s or %s)", mode, SearchModeLiteral, SearchModeIgnoreCase, SearchModeRegex)
+	}
+}
+
+// FormatSearchMatchesMarkdown formats one page of text search matches; total is the number of
+// matches across all pages and offset is the index of the first match on this page
+func FormatSearchMatchesMarkdown(query string, mode string, page []SearchMatch, total int, offset int) string {
+	if mode == "" {
+		mode = SearchModeLiteral
+	}
+
+	var md strings.Builder
+	md.WriteString(fmt.Sprintf("# Text Search: `%s` (%s)\n\n", query, mode))
+
+	if total == 0 {
+		md.WriteString("No matching lines found.\n")
+		return md.String()
+	}
+	if len(page) == 0 {
+		md.WriteString(fmt.Sprintf("No matches at offset %d (%d total).\n", offset, total))
+		return md.String()
+	}
+
+	md.WriteString(fmt.Sprintf("Showing matches %d-%d of %d.\n", offset+1, offset+len(page), total))
+
+	current := ""
+	for _, match := range page {
+		if match.Name != current {
+			if current != "" {
+				md.WriteString("```\n")
+			}
+			current = match.Name
+			md.WriteString(fmt.Sprintf("\n## %s\n\n```\n", match.Name))
+		}
+
+		// grep-style prefixes: ':' marks the matching line, '-' marks context
+		first := match.Line - len(match.Before)
+		for i, line := range match.Before {
+			md.WriteString(fmt.Sprintf("%d-%s\n", first+i, line))
+		}
+		md.WriteString(fmt.Sprintf("%d:%s\n", match.Line, match.Text))
+		for i, line := range match.After {
+			md.WriteString(fmt.Sprintf("%d-%s\n", match.Line+1+i, line))
+		}
+		if len(match.Before) > 0 || len(match.After) > 0 {
+			md.WriteString("--\n")
+		}
+	}
+	md.WriteString("```\n")
+
+	if next := offset + len(page); next < total {
+		md.WriteString(fmt.Sprintf("\nMore matches available: call again with offset %d.\n", next))
+	}
+
+	return md.String()
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchWithOptions(t *testing.T) {
+	store := NewStore(t.TempDir())
+	if err := store.Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	docs := map[string]string{
+		"project-schema": "---\ntitle: Schema\ntags:\n  area: db\n---\nintro\nembedding FLOAT[1024]\noutro",
+		"project-notes":  "---\ntitle: Notes\ntags:\n  area: docs\n---\nvectors are float[1024] too",
+		"other":          "---\ntitle: Other\n---\nFLOAT[1024] elsewhere",
+	}
+	for name, content := range docs {
+		if err := store.Create(name, content); err != nil {
+			t.Fatalf("Create(%s) error = %v", name, err)
+		}
+	}
+
+	names := func(matches []SearchMatch) []string {
+		var result []string
+		for _, match := range matches {
+			result = append(result, match.Name)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		opts  SearchOptions
+		want  []string
+	}{
+		{name: "literal is case-sensitive", query: "FLOAT[1024]", opts: SearchOptions{}, want: []string{"other", "project-schema"}},
+		{name: "ignore case", query: "FLOAT[1024]", opts: SearchOptions{Mode: SearchModeIgnoreCase}, want: []string{"other", "project-notes", "project-schema"}},
+		{name: "regex", query: `(?i)^vectors.*\[\d+\]`, opts: SearchOptions{Mode: SearchModeRegex}, want: []string{"project-notes"}},
+		{name: "name glob", query: "1024", opts: SearchOptions{NameGlob: "project-*"}, want: []string{"project-notes", "project-schema"}},
+		{name: "tag filter", query: "1024", opts: SearchOptions{Tags: map[string]string{"area": "db"}}, want: []string{"project-schema"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := store.SearchWithOptions(tt.query, tt.opts)
+			if err != nil {
+				t.Fatalf("SearchWithOptions() error = %v", err)
+			}
+			if got := names(matches); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	matches, err := store.SearchWithOptions("FLOAT", SearchOptions{NameGlob: "project-schema", ContextLines: 5})
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("expected one match, got %v (err: %v)", matches, err)
+	}
+	match := matches[0]
+	if match.Line != 2 || !reflect.DeepEqual(match.Before, []string{"intro"}) || match.After[0] != "outro" {
+		t.Errorf("unexpected match context: %+v", match)
+	}
+
+	if _, err := store.SearchWithOptions("[", SearchOptions{Mode: SearchModeRegex}); err == nil {
+		t.Errorf("expected error for invalid regex")
+	}
+}
@@ -10,12 +10,16 @@ import (
 
 type Store struct {
 	basePath string
+	names    *NameRules
+	history  *historyPolicy // nil when revision history is disabled
+	written  writeLog       // The store's own writes, skipped by the watcher
 	mu       sync.RWMutex
 }
 
 func NewStore(basePath string) *Store {
 	return &Store{
 		basePath: basePath,
+		names:    DefaultNameRules(),
 	}
 }
 
@@ -24,19 +28,34 @@ func (s *Store) Initialize() error {
 }
 
 func (s *Store) Create(name, content string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.lock()()
 
+	return s.create(name, content)
+}
+
+// create writes a new memory document. The caller must hold the lock.
+func (s *Store) create(name, content string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
 	if !strings.HasSuffix(name, ".md") {
 		name = name + ".md"
 	}
 
-	path := filepath.Join(s.basePath, name)
+	path := s.path(name)
 	
 	if _, err := os.Stat(path); err == nil {
 		return fmt.Errorf("memory %s already exists", name)
 	}
 
+	existing, err := s.nameCollision(strings.TrimSuffix(name, ".md"), "")
+	if err != nil {
+		return err
+	}
+	if existing != "" {
+		return &NameCollisionError{Name: strings.TrimSuffix(name, ".md"), Existing: existing}
+	}
+
 	// Parse frontmatter if present, or create new one
 	fm, body, err := ParseDocument(content)
 	if err != nil {
@@ -54,18 +73,30 @@ func (s *Store) Create(name, content string) error {
 		return fmt.Errorf("failed to format document: %w", err)
 	}
 
-	return os.WriteFile(path, []byte(finalContent), 0644)
+	// Namespaced memories live in subdirectories
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create namespace directory: %w", err)
+	}
+
+	if err := writeFileAtomic(path, []byte(finalContent), 0644); err != nil {
+		return err
+	}
+	s.recordRevision(name, nil, []byte(finalContent), RevisionCreated)
+	return nil
 }
 
 func (s *Store) Read(name string) (string, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	if err := checkName(name); err != nil {
+		return "", err
+	}
 	if !strings.HasSuffix(name, ".md") {
 		name = name + ".md"
 	}
 
-	path := filepath.Join(s.basePath, name)
+	path := s.path(name)
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -77,15 +108,41 @@ func (s *Store) Read(name string) (string, error) {
 	return string(data), nil
 }
 
+// readFile reads a memory's content. The caller must hold the lock.
+func (s *Store) readFile(name string) (string, error) {
+	if err := checkName(name); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(s.path(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("memory %s.md not found", strings.TrimSuffix(name, ".md"))
+		}
+		return "", err
+	}
+	return string(data), nil
+}
+
 func (s *Store) Update(name, content string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	return s.Modify(name, func(string) (string, error) {
+		return content, nil
+	})
+}
+
+// Modify replaces a memory's content with the result of applying modify to its current content.
+// Reading and writing happen under the write lock, so concurrent changes are not lost.
+func (s *Store) Modify(name string, modify func(current string) (string, error)) error {
+	defer s.lock()()
 
+	if err := checkName(name); err != nil {
+		return err
+	}
 	if !strings.HasSuffix(name, ".md") {
 		name = name + ".md"
 	}
 
-	path := filepath.Join(s.basePath, name)
+	path := s.path(name)
 	
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return fmt.Errorf("memory %s not found", name)
@@ -99,6 +156,11 @@ func (s *Store) Update(name, content string) error {
 
 	existingFm, _, _ := ParseDocument(string(existingData))
 
+	content, err := modify(string(existingData))
+	if err != nil {
+		return err
+	}
+
 	// Parse new content
 	fm, body, err := ParseDocument(content)
 	if err != nil {
@@ -120,90 +182,79 @@ func (s *Store) Update(name, content string) error {
 		return fmt.Errorf("failed to format document: %w", err)
 	}
 
-	return os.WriteFile(path, []byte(finalContent), 0644)
+	if err := writeFileAtomic(path, []byte(finalContent), 0644); err != nil {
+		return err
+	}
+	s.recordRevision(name, existingData, []byte(finalContent), RevisionUpdated)
+	return nil
 }
 
 func (s *Store) Delete(name string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	return s.DeleteIfMatch(name, "")
+}
+
+// DeleteIfMatch deletes a memory, failing with a ConflictError unless its current content matches
+// the expected version. An empty ifMatch deletes any version.
+func (s *Store) DeleteIfMatch(name, ifMatch string) error {
+	defer s.lock()()
 
+	return s.deleteFile(name, ifMatch)
+}
+
+// deleteFile deletes a memory like DeleteIfMatch. The caller must hold the lock.
+func (s *Store) deleteFile(name, ifMatch string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
 	if !strings.HasSuffix(name, ".md") {
 		name = name + ".md"
 	}
 
-	path := filepath.Join(s.basePath, name)
+	path := s.path(name)
 	
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return fmt.Errorf("memory %s not found", name)
 	}
 
-	return os.Remove(path)
-}
-
-func (s *Store) List() ([]string, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	entries, err := os.ReadDir(s.basePath)
+	data, err := os.ReadFile(path)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return []string{}, nil
-		}
-		return nil, err
+		return fmt.Errorf("failed to read existing file: %w", err)
+	}
+	if err := s.checkVersion(name, string(data), ifMatch); err != nil {
+		return err
 	}
 
-	var memories []string
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
-			name := strings.TrimSuffix(entry.Name(), ".md")
-			memories = append(memories, name)
-		}
+	// Keep the final content in the history so the memory can be restored
+	s.recordRevision(name, data, nil, "")
+
+	if err := os.Remove(path); err != nil {
+		return err
 	}
 
-	return memories, nil
+	s.pruneEmptyDirs(name)
+	return nil
 }
 
-func (s *Store) Search(query string) (map[string][]string, error) {
+// List returns the names of all memories, including path-style names of memories in
+// namespace subdirectories, in name order
+func (s *Store) List() ([]string, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	entries, err := os.ReadDir(s.basePath)
+	return s.listNames()
+}
+
+// Search finds body lines containing query, ignoring case, keyed by memory name
+func (s *Store) Search(query string) (map[string][]string, error) {
+	matches, err := s.SearchWithOptions(query, SearchOptions{Mode: SearchModeIgnoreCase})
 	if err != nil {
-		if os.IsNotExist(err) {
-			return map[string][]string{}, nil
-		}
 		return nil, err
 	}
 
 	results := make(map[string][]string)
-	queryLower := strings.ToLower(query)
-
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
-			path := filepath.Join(s.basePath, entry.Name())
-			content, err := os.ReadFile(path)
-			if err != nil {
-				continue
-			}
-
-			// Parse document to search in body only (not frontmatter)
-			_, body, _ := ParseDocument(string(content))
-			
-			lines := strings.Split(body, "\n")
-			var matches []string
-			
-			for i, line := range lines {
-				if strings.Contains(strings.ToLower(line), queryLower) {
-					context := fmt.Sprintf("Line %d: %s", i+1, line)
-					matches = append(matches, context)
-				}
-			}
-
-			if len(matches) > 0 {
-				name := strings.TrimSuffix(entry.Name(), ".md")
-				results[name] = matches
-			}
-		}
+	for _, match := range matches {
+		context := fmt.Sprintf("Line %d: %s", match.Line, match.Text)
+		results[match.Name] = append(results[match.Name], context)
 	}
 
 	return results, nil
@@ -222,11 +273,14 @@ func (s *Store) ReadWithMetadata(name string) (*MemoryInfo, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
 	if !strings.HasSuffix(name, ".md") {
 		name = name + ".md"
 	}
 
-	path := filepath.Join(s.basePath, name)
+	path := s.path(name)
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -256,32 +310,25 @@ func (s *Store) SearchByTag(tag string) ([]string, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	entries, err := os.ReadDir(s.basePath)
+	names, err := s.listNames()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return []string{}, nil
-		}
 		return nil, err
 	}
 
 	var results []string
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
-			path := filepath.Join(s.basePath, entry.Name())
-			content, err := os.ReadFile(path)
-			if err != nil {
-				continue
-			}
-
-			fm, _, err := ParseDocument(string(content))
-			if err != nil {
-				continue
-			}
-
-			if fm.HasTag(tag) {
-				name := strings.TrimSuffix(entry.Name(), ".md")
-				results = append(results, name)
-			}
+	for _, name := range names {
+		content, err := os.ReadFile(s.path(name))
+		if err != nil {
+			continue
+		}
+
+		fm, _, err := ParseDocument(string(content))
+		if err != nil {
+			continue
+		}
+
+		if fm.HasTag(tag) {
+			results = append(results, name)
 		}
 	}
 
@@ -293,32 +340,25 @@ func (s *Store) GetAllTags() (map[string][]string, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	entries, err := os.ReadDir(s.basePath)
+	names, err := s.listNames()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return map[string][]string{}, nil
-		}
 		return nil, err
 	}
 
 	tagMap := make(map[string][]string)
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
-			path := filepath.Join(s.basePath, entry.Name())
-			content, err := os.ReadFile(path)
-			if err != nil {
-				continue
-			}
-
-			fm, _, err := ParseDocument(string(content))
-			if err != nil {
-				continue
-			}
-
-			name := strings.TrimSuffix(entry.Name(), ".md")
-			for tag := range fm.Tags {
-				tagMap[tag] = append(tagMap[tag], name)
-			}
+	for _, name := range names {
+		content, err := os.ReadFile(s.path(name))
+		if err != nil {
+			continue
+		}
+
+		fm, _, err := ParseDocument(string(content))
+		if err != nil {
+			continue
+		}
+
+		for tag := range fm.Tags {
+			tagMap[tag] = append(tagMap[tag], name)
 		}
 	}
 
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Actions reported for a tag change
+const (
+	TagSet     = "set"
+	TagChanged = "changed"
+	TagRemoved = "removed"
+	TagAbsent  = "absent"
+)
+
+// TagChange describes the effect of a tag change on a single tag
+type TagChange struct {
+	Tag      string `json:"tag"`
+	Action   string `json:"action" jsonschema:"enum=set,enum=changed,enum=removed,enum=absent"`
+	OldValue any    `json:"old_value,omitempty"`
+	NewValue any    `json:"new_value,omitempty"`
+}
+
+// String describes the change for humans
+func (c TagChange) String() string {
+	switch c.Action {
+	case TagRemoved:
+		return fmt.Sprintf("'%s' removed (was: %v)", c.Tag, c.OldValue)
+	case TagAbsent:
+		return fmt.Sprintf("'%s' already absent", c.Tag)
+	case TagChanged:
+		return fmt.Sprintf("'%s' changed from %v to %v", c.Tag, c.OldValue, c.NewValue)
+	default:
+		return fmt.Sprintf("'%s' set to %v", c.Tag, c.NewValue)
+	}
+}
+
+// ChangeTags sets tags on a memory, removing those with nil values, and returns the changes in
+// tag order together with all tags after the change. A non-empty ifMatch fails the change with a
+// ConflictError unless the memory matches that version.
+func (es *EnhancedStore) ChangeTags(name string, tags map[string]interface{}, ifMatch string) ([]TagChange, map[string]interface{}, error) {
+	var changes []TagChange
+	var updatedTags map[string]interface{}
+
+	// The tags are changed under the write lock so concurrent changes to other tags are kept
+	err := es.Modify(name, func(content string) (string, error) {
+		if err := es.checkVersion(name, content, ifMatch); err != nil {
+			return "", err
+		}
+
+		fm, body, err := ParseDocument(content)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse memory document: %w", err)
+		}
+
+		if fm.Tags == nil {
+			fm.Tags = make(map[string]interface{})
+		}
+
+		keys := make([]string, 0, len(tags))
+		for key := range tags {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		changes = []TagChange{}
+		for _, key := range keys {
+			value := tags[key]
+			oldValue := fm.Tags[key]
+
+			switch {
+			case value == nil && oldValue != nil:
+				delete(fm.Tags, key)
+				changes = append(changes, TagChange{Tag: key, Action: TagRemoved, OldValue: oldValue})
+			case value == nil:
+				changes = append(changes, TagChange{Tag: key, Action: TagAbsent})
+			case oldValue != nil:
+				fm.Tags[key] = value
+				changes = append(changes, TagChange{Tag: key, Action: TagChanged, OldValue: oldValue, NewValue: value})
+			default:
+				fm.Tags[key] = value
+				changes = append(changes, TagChange{Tag: key, Action: TagSet, NewValue: value})
+			}
+		}
+		updatedTags = fm.Tags
+
+		updated, err := FormatDocument(fm, body)
+		if err != nil {
+			return "", fmt.Errorf("failed to format updated document: %w", err)
+		}
+		return updated, nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to update memory '%s': %w", name, err)
+	}
+
+	return changes, updatedTags, nil
+}
@@ -0,0 +1,364 @@
+package memory
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jcdickinson/simplemem/internal/db"
+)
+
+// trashDir is the directory, relative to the memory directory, that deleted memories are kept in
+const trashDir = ".trash"
+
+// TrashEntry is a deleted memory kept in the trash
+type TrashEntry struct {
+	ID      string    `json:"id" jsonschema:"description=Identifies this copy when a memory was deleted more than once"`
+	Name    string    `json:"name"`
+	Title   string    `json:"title,omitempty"`
+	Deleted time.Time `json:"deleted"`
+	Size    int       `json:"size"`
+	file    string
+}
+
+// trashEntry creates the entry of a trash file, named <escaped name>~<timestamp>.md
+func (s *Store) trashEntry(file string) (TrashEntry, bool) {
+	id, ok := strings.CutSuffix(file, ".md")
+	if !ok {
+		return TrashEntry{}, false
+	}
+	sep := strings.LastIndex(id, "~")
+	if sep < 0 {
+		return TrashEntry{}, false
+	}
+	name, err := url.PathUnescape(id[:sep])
+	if err != nil {
+		return TrashEntry{}, false
+	}
+	deleted, err := time.Parse(revisionTimeFormat, id[sep+1:])
+	if err != nil {
+		return TrashEntry{}, false
+	}
+	return TrashEntry{ID: id, Name: name, Deleted: deleted, file: filepath.Join(s.basePath, trashDir, file)}, true
+}
+
+// Trash lists the memories in the trash, most recently deleted first
+func (s *Store) Trash() ([]TrashEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.trashEntries()
+}
+
+// trashEntries lists the memories in the trash, most recently deleted first. The caller must hold
+// the lock.
+func (s *Store) trashEntries() ([]TrashEntry, error) {
+	files, err := os.ReadDir(filepath.Join(s.basePath, trashDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []TrashEntry{}, nil
+		}
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	entries := []TrashEntry{}
+	for _, file := range files {
+		entry, ok := s.trashEntry(file.Name())
+		if file.IsDir() || !ok {
+			continue
+		}
+		data, err := os.ReadFile(entry.file)
+		if err != nil {
+			continue
+		}
+		entry.Size = len(data)
+		if fm, _, err := ParseDocument(string(data)); err == nil {
+			entry.Title = fm.Title
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].Deleted.Equal(entries[j].Deleted) {
+			return entries[i].Deleted.After(entries[j].Deleted)
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+// FindTrashed returns the trash entry with the given ID or, without an ID, the most recently
+// deleted copy of the named memory, along with how the name was matched. Like ResolveName, it
+// falls back to a unique case-insensitive name or title match among the deleted memories.
+func (s *Store) FindTrashed(name, id string) (TrashEntry, string, error) {
+	entries, err := s.Trash()
+	if err != nil {
+		return TrashEntry{}, "", err
+	}
+
+	if id != "" {
+		for _, entry := range entries {
+			if entry.ID == id {
+				return entry, ResolvedExact, nil
+			}
+		}
+		return TrashEntry{}, "", fmt.Errorf("no trash entry with id '%s'", id)
+	}
+
+	name = strings.TrimSuffix(name, ".md")
+	matchers := []struct {
+		method  string
+		matches func(entry TrashEntry) bool
+	}{
+		{ResolvedExact, func(entry TrashEntry) bool { return entry.Name == name }},
+		{ResolvedCaseInsensitive, func(entry TrashEntry) bool { return strings.EqualFold(entry.Name, name) }},
+		{ResolvedTitle, func(entry TrashEntry) bool {
+			return entry.Title != "" && strings.EqualFold(strings.TrimSpace(entry.Title), strings.TrimSpace(name))
+		}},
+	}
+	for _, matcher := range matchers {
+		// Entries are newest first, so the first match of a name is its latest copy
+		var found []TrashEntry
+		seen := make(map[string]bool)
+		for _, entry := range entries {
+			if matcher.matches(entry) && !seen[entry.Name] {
+				seen[entry.Name] = true
+				found = append(found, entry)
+			}
+		}
+		if len(found) == 1 {
+			return found[0], matcher.method, nil
+		}
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	if suggestions := nearestByEditDistance(name, names, maxSuggestions); len(suggestions) > 0 {
+		return TrashEntry{}, "", fmt.Errorf("memory '%s' is not in the trash. Did you mean: %s?", name, strings.Join(suggestions, ", "))
+	}
+	return TrashEntry{}, "", fmt.Errorf("memory '%s' is not in the trash", name)
+}
+
+// trashFile moves a memory file into the trash, failing with a ConflictError unless it matches the
+// expected version. Its final content is kept in the history too.
+func (s *Store) trashFile(name, ifMatch string) (TrashEntry, error) {
+	defer s.lock()()
+
+	return s.moveFileToTrash(name, ifMatch)
+}
+
+// moveFileToTrash moves a memory file into the trash like trashFile. The caller must hold the lock.
+func (s *Store) moveFileToTrash(name, ifMatch string) (TrashEntry, error) {
+	name = strings.TrimSuffix(name, ".md")
+	if err := checkName(name); err != nil {
+		return TrashEntry{}, err
+	}
+
+	data, err := os.ReadFile(s.path(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return TrashEntry{}, fmt.Errorf("memory %s.md not found", name)
+		}
+		return TrashEntry{}, err
+	}
+	if err := s.checkVersion(name, string(data), ifMatch); err != nil {
+		return TrashEntry{}, err
+	}
+	s.recordRevision(name, data, nil, "")
+
+	dir := filepath.Join(s.basePath, trashDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return TrashEntry{}, fmt.Errorf("failed to create trash directory: %w", err)
+	}
+	file := url.PathEscape(name) + "~" + time.Now().UTC().Format(revisionTimeFormat) + ".md"
+	entry, _ := s.trashEntry(file)
+	if err := os.Rename(s.path(name), entry.file); err != nil {
+		return TrashEntry{}, fmt.Errorf("failed to move memory to trash: %w", err)
+	}
+	s.pruneEmptyDirs(name)
+
+	entry.Size = len(data)
+	if fm, _, err := ParseDocument(string(data)); err == nil {
+		entry.Title = fm.Title
+	}
+	return entry, nil
+}
+
+// restoreTrashed moves a memory out of the trash, refusing to replace an existing memory
+func (s *Store) restoreTrashed(entry TrashEntry) error {
+	defer s.lock()()
+
+	path := s.path(entry.Name)
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("memory '%s' already exists; rename or delete it before restoring it from the trash", entry.Name)
+	}
+	if existing, err := s.nameCollision(entry.Name, ""); err != nil {
+		return err
+	} else if existing != "" {
+		return &NameCollisionError{Name: entry.Name, Existing: existing}
+	}
+
+	data, err := os.ReadFile(entry.file)
+	if err != nil {
+		return fmt.Errorf("failed to read trashed memory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create namespace directory: %w", err)
+	}
+	if err := os.Rename(entry.file, path); err != nil {
+		return fmt.Errorf("failed to restore memory from trash: %w", err)
+	}
+
+	s.recordRevision(entry.Name, nil, data, RevisionRestored)
+	return nil
+}
+
+// purgeTrashed permanently removes entries from the trash
+func (s *Store) purgeTrashed(entries []TrashEntry) error {
+	defer s.lock()()
+
+	for _, entry := range entries {
+		if err := os.Remove(entry.file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to purge %s from trash: %w", entry.Name, err)
+		}
+	}
+	return nil
+}
+
+// moveToTrash moves a memory to the trash, keeping its embeddings aside so a restore does not
+// have to generate them again
+func (es *EnhancedStore) moveToTrash(name, ifMatch string) error {
+	defer es.syncLocks.lock(name)()
+
+	existing, err := es.db.GetMemory(name)
+	if err != nil {
+		log.Printf("Warning: failed to look up memory %s in database: %v", name, err)
+	}
+
+	entry, err := es.trashFile(name, ifMatch)
+	if err != nil {
+		return err
+	}
+
+	es.trashIndexed(name, existing, entry.ID)
+	return nil
+}
+
+// trashIndexed removes the database rows of a memory whose file was moved to the trash, keeping
+// its embeddings under the trash ID so a restore does not re-embed it
+func (es *EnhancedStore) trashIndexed(name string, existing *db.Memory, trashID string) {
+	if existing != nil {
+		if err := es.db.TrashEmbeddings(existing.ID, trashID); err != nil {
+			log.Printf("Warning: failed to keep embeddings of %s: %v", name, err)
+		}
+		if err := es.db.DeleteMemory(name); err != nil {
+			log.Printf("Warning: failed to delete memory from database: %v", err)
+		}
+	}
+
+	es.autoPurgeTrash()
+}
+
+// RestoreFromTrash brings back a memory from the trash: the copy with the given ID or, without an
+// ID, the most recently deleted copy of the named memory, along with how the name was matched
+func (es *EnhancedStore) RestoreFromTrash(name, id string) (TrashEntry, string, error) {
+	entry, method, err := es.FindTrashed(name, id)
+	if err != nil {
+		return TrashEntry{}, "", err
+	}
+
+	saved, err := es.db.GetTrashedEmbeddings(entry.ID)
+	if err != nil {
+		log.Printf("Warning: failed to load saved embeddings of %s: %v", entry.Name, err)
+	}
+
+	if err := es.restoreTrashed(entry); err != nil {
+		return TrashEntry{}, "", err
+	}
+
+	if err := es.syncMemoryToDatabaseReusing(entry.Name, saved); err != nil {
+		log.Printf("Warning: failed to sync memory to database: %v", err)
+	}
+	if err := es.db.DeleteTrashedEmbeddings(entry.ID); err != nil {
+		log.Printf("Warning: failed to delete saved embeddings of %s: %v", entry.Name, err)
+	}
+	return entry, method, nil
+}
+
+// PurgeTrash permanently removes memories from the trash, together with their saved embeddings.
+// An empty name purges every memory, and a positive age only those deleted at least that long ago.
+func (es *EnhancedStore) PurgeTrash(name string, olderThan time.Duration) ([]TrashEntry, error) {
+	entries, err := es.Trash()
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSuffix(name, ".md")
+	purged := []TrashEntry{}
+	for _, entry := range entries {
+		if name != "" && entry.Name != name {
+			continue
+		}
+		if olderThan > 0 && time.Since(entry.Deleted) < olderThan {
+			continue
+		}
+		purged = append(purged, entry)
+	}
+
+	if err := es.purgeTrashed(purged); err != nil {
+		return nil, err
+	}
+	for _, entry := range purged {
+		if err := es.db.DeleteTrashedEmbeddings(entry.ID); err != nil {
+			log.Printf("Warning: failed to delete saved embeddings of %s: %v", entry.Name, err)
+		}
+	}
+	return purged, nil
+}
+
+// autoPurgeTrash purges memories that have been in the trash longer than configured
+func (es *EnhancedStore) autoPurgeTrash() {
+	if es.trash.AutoPurgeDays <= 0 {
+		return
+	}
+
+	purged, err := es.PurgeTrash("", time.Duration(es.trash.AutoPurgeDays)*24*time.Hour)
+	if err != nil {
+		log.Printf("Warning: failed to purge trash: %v", err)
+	} else if len(purged) > 0 {
+		log.Printf("[TRASH] Purged %d memories deleted more than %d days ago", len(purged), es.trash.AutoPurgeDays)
+	}
+}
+
+// FormatTrashMarkdown formats the contents of the trash. A positive autoPurgeDays shows when each
+// memory will be purged.
+func FormatTrashMarkdown(entries []TrashEntry, autoPurgeDays int) string {
+	var md strings.Builder
+	md.WriteString("# Trash\n\n")
+	if len(entries) == 0 {
+		md.WriteString("The trash is empty.\n")
+		return md.String()
+	}
+
+	for _, entry := range entries {
+		md.WriteString(fmt.Sprintf("- **%s**", entry.Name))
+		if entry.Title != "" {
+			md.WriteString(fmt.Sprintf(" (%s)", entry.Title))
+		}
+		md.WriteString(fmt.Sprintf(": deleted %s, %d bytes, id `%s`", entry.Deleted.Local().Format("2006-01-02 15:04:05"), entry.Size, entry.ID))
+		if autoPurgeDays > 0 {
+			md.WriteString(fmt.Sprintf(", purged after %s", entry.Deleted.AddDate(0, 0, autoPurgeDays).Local().Format("2006-01-02")))
+		}
+		md.WriteString("\n")
+	}
+	md.WriteString("\nUse restore_memory to bring a memory back and purge_trash to remove memories for good.\n")
+
+	return md.String()
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrash(t *testing.T) {
+	store := newNamespaceStore(t, "project/schema", "notes")
+
+	entry, err := store.trashFile("project/schema", "")
+	if err != nil {
+		t.Fatalf("trashFile() error = %v", err)
+	}
+	if _, err := store.Read("project/schema"); err == nil {
+		t.Fatal("expected the trashed memory to be gone")
+	}
+	if names, _ := store.List(); strings.Join(names, ",") != "notes" {
+		t.Errorf("expected the trash to be hidden from the listing, got %v", names)
+	}
+
+	entries, err := store.Trash()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Trash() = %+v, %v", entries, err)
+	}
+	if entries[0].ID != entry.ID || entries[0].Name != "project/schema" || entries[0].Title != "project/schema" {
+		t.Errorf("unexpected trash entry %+v", entries[0])
+	}
+
+	// A new memory with the same name blocks the restore instead of being replaced
+	if err := store.Create("project/schema", "---\ntitle: New\n---\nNew body.\n"); err != nil {
+		t.Fatal(err)
+	}
+	found, _, err := store.FindTrashed("project/schema", "")
+	if err != nil {
+		t.Fatalf("FindTrashed() error = %v", err)
+	}
+	if err := store.restoreTrashed(found); err == nil {
+		t.Error("expected restoring over an existing memory to fail")
+	}
+	if err := store.Delete("project/schema"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.restoreTrashed(found); err != nil {
+		t.Fatalf("restoreTrashed() error = %v", err)
+	}
+	if content, _ := store.Read("project/schema"); !strings.Contains(content, "Body of project/schema") {
+		t.Errorf("restored content = %q", content)
+	}
+	if entries, _ := store.Trash(); len(entries) != 0 {
+		t.Errorf("expected the trash to be empty after the restore, got %+v", entries)
+	}
+
+	if _, _, err := store.FindTrashed("notes", ""); err == nil {
+		t.Error("expected notes not to be in the trash")
+	}
+	if _, err := store.trashFile("notes", ""); err != nil {
+		t.Fatal(err)
+	}
+	if found, method, err := store.FindTrashed("NOTES.md", ""); err != nil || found.Name != "notes" || method != ResolvedCaseInsensitive {
+		t.Errorf("FindTrashed(NOTES.md) = %+v, %q, %v", found, method, err)
+	}
+	if _, _, err := store.FindTrashed("note", ""); err == nil || !strings.Contains(err.Error(), "Did you mean: notes?") {
+		t.Errorf("FindTrashed(note) error = %v, want a suggestion", err)
+	}
+	entries, _ = store.Trash()
+	if err := store.purgeTrashed(entries); err != nil {
+		t.Fatalf("purgeTrashed() error = %v", err)
+	}
+	if entries, _ := store.Trash(); len(entries) != 0 {
+		t.Errorf("expected the trash to be empty after purging, got %+v", entries)
+	}
+}
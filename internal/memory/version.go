@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// minVersionPrefix is the shortest version prefix accepted by if_match, the length of the hashes
+// shown for revisions
+const minVersionPrefix = 12
+
+// ContentVersion returns the version token of a memory's content: the hex SHA-256 of the file,
+// which is also stored as its file hash in the database
+func ContentVersion(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// ConflictError reports a change that expected another version of a memory than the current one
+type ConflictError struct {
+	Name     string `json:"name"`
+	Expected string `json:"expected_version"`
+	Current  string `json:"current_version"`
+	Diff     string `json:"diff,omitempty" jsonschema:"description=Unified diff from the expected version to the current content, when the expected version is in the revision history"`
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("memory '%s' has changed since version %s was read (current version is %s); read it again and reapply the change", e.Name, shortVersion(e.Expected), e.Current)
+}
+
+// matchesVersion reports whether an if_match token, the full version or a prefix of it, matches a
+// version
+func matchesVersion(version, ifMatch string) bool {
+	return strings.HasPrefix(version, strings.ToLower(strings.TrimSpace(ifMatch)))
+}
+
+// checkVersion returns a ConflictError when the current content of a memory does not match the
+// expected version. An empty ifMatch matches any version; one shorter than minVersionPrefix is
+// rejected before comparing, as it is not a version that could have been read. The caller must
+// hold the lock.
+func (s *Store) checkVersion(name, current, ifMatch string) error {
+	if ifMatch == "" {
+		return nil
+	}
+	if trimmed := strings.TrimSpace(ifMatch); len(trimmed) < minVersionPrefix {
+		return fmt.Errorf("if_match must be a version or a prefix of at least %d characters, got %q", minVersionPrefix, trimmed)
+	}
+	name = strings.TrimSuffix(name, ".md")
+	version := ContentVersion(current)
+	if matchesVersion(version, ifMatch) {
+		return nil
+	}
+
+	conflict := &ConflictError{Name: name, Expected: strings.TrimSpace(ifMatch), Current: version}
+	if s.history == nil {
+		return conflict
+	}
+
+	// The expected content can be found in the history if simplemem wrote it
+	revisions, err := s.revisions(name)
+	if err != nil {
+		return conflict
+	}
+	for _, revision := range revisions {
+		if revision.Hash != strings.ToLower(shortVersion(conflict.Expected)) {
+			continue
+		}
+		if data, err := os.ReadFile(revision.file); err == nil {
+			conflict.Diff = UnifiedDiff("version "+shortVersion(conflict.Expected), "current", string(data), current, 3)
+		}
+		break
+	}
+	return conflict
+}
+
+// UpdateIfMatch updates a memory like Update, failing with a ConflictError unless its current
+// content matches the expected version
+func (es *EnhancedStore) UpdateIfMatch(name, content, ifMatch string) error {
+	return es.Modify(name, func(current string) (string, error) {
+		if err := es.checkVersion(name, current, ifMatch); err != nil {
+			return "", err
+		}
+		return content, nil
+	})
+}
+
+// shortVersion abbreviates a version token for messages
+func shortVersion(version string) string {
+	if len(version) > minVersionPrefix {
+		return version[:minVersionPrefix]
+	}
+	return version
+}
+
+// FormatConflictMarkdown formats a conflict, including the changes made since the expected version
+func FormatConflictMarkdown(conflict *ConflictError) string {
+	var md strings.Builder
+	md.WriteString("# Conflict\n\n")
+	md.WriteString(conflict.Error() + "\n")
+	if conflict.Diff != "" {
+		md.WriteString("\nChanges since the version you read:\n\n```diff\n" + strings.TrimRight(conflict.Diff, "\n") + "\n```\n")
+	} else {
+		md.WriteString("\nThe version you read is not in the revision history, so no diff is available.\n")
+	}
+	return md.String()
+}
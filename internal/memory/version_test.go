@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDeleteIfMatch(t *testing.T) {
+	store := NewStore(t.TempDir())
+	store.history = &historyPolicy{}
+	if err := store.Create("notes", "---\ntitle: Notes\n---\nFirst draft.\n"); err != nil {
+		t.Fatal(err)
+	}
+	read, err := store.Read("notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := ContentVersion(read)
+
+	// Another writer changes the memory after it was read
+	if err := store.Update("notes", "---\ntitle: Notes\n---\nSecond draft.\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.DeleteIfMatch("notes", stale[:minVersionPrefix])
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("DeleteIfMatch() error = %v, want a conflict", err)
+	}
+	current, _ := store.Read("notes")
+	if conflict.Current != ContentVersion(current) {
+		t.Errorf("conflict reports version %s, want %s", conflict.Current, ContentVersion(current))
+	}
+	if !strings.Contains(conflict.Diff, "-First draft.") || !strings.Contains(conflict.Diff, "+Second draft.") {
+		t.Errorf("unexpected conflict diff:\n%s", conflict.Diff)
+	}
+
+	if err := store.DeleteIfMatch("notes", "abc"); err == nil || errors.As(err, &conflict) {
+		t.Errorf("DeleteIfMatch() with a short version error = %v, want a validation error", err)
+	}
+	if err := store.DeleteIfMatch("notes", ContentVersion(current)); err != nil {
+		t.Fatalf("DeleteIfMatch() with the current version error = %v", err)
+	}
+}
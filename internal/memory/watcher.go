@@ -0,0 +1,397 @@
+package memory
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/jcdickinson/simplemem/internal/config"
+)
+
+// Watcher reports changes to the memory directory made by other programs, such as an editor.
+// Events are debounced: once none have arrived for the debounce interval, the changed paths are
+// passed to the callback in one batch.
+type Watcher struct {
+	root     string
+	debounce time.Duration
+	onChange func(paths []string)
+	fsw      *fsnotify.Watcher
+	done     chan struct{}
+
+	mu      sync.Mutex // Guards pending, timer and closed
+	pending map[string]bool
+	timer   *time.Timer
+	closed  bool
+
+	flushMu sync.Mutex // Serializes callbacks
+}
+
+// NewWatcher watches a memory directory and its namespace directories. The callback receives the
+// changed paths relative to the root, using "/" as separator: memory files ending in .md, and
+// other paths, typically directories, that were created, removed or renamed. Hidden files and
+// directories such as .history are ignored.
+func NewWatcher(root string, debounce time.Duration, onChange func(paths []string)) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	w := &Watcher{
+		root:     root,
+		debounce: debounce,
+		onChange: onChange,
+		fsw:      fsw,
+		done:     make(chan struct{}),
+		pending:  make(map[string]bool),
+	}
+	if err := w.addTree(root, false); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+
+	go w.run()
+	return w, nil
+}
+
+// Close stops watching; changes that have not been reported yet are dropped
+func (w *Watcher) Close() error {
+	err := w.fsw.Close()
+	<-w.done
+
+	w.mu.Lock()
+	w.closed = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+
+	// Wait for a callback in progress
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+	return err
+}
+
+// run handles events until the watcher is closed
+func (w *Watcher) run() {
+	defer close(w.done)
+
+	for {
+		select {
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			w.handle(event)
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("[WATCH] Error: %v", err)
+		}
+	}
+}
+
+// handle queues the path of an event, watching directories as they appear
+func (w *Watcher) handle(event fsnotify.Event) {
+	rel, ok := w.relative(event.Name)
+	if !ok {
+		return
+	}
+
+	switch {
+	case event.Has(fsnotify.Create):
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			// Files may have been created in the directory before it was watched
+			if err := w.addTree(event.Name, true); err != nil {
+				log.Printf("[WATCH] Failed to watch %s: %v", rel, err)
+			}
+			w.queue(rel)
+		} else if strings.HasSuffix(rel, ".md") {
+			w.queue(rel)
+		}
+	case event.Has(fsnotify.Write):
+		if strings.HasSuffix(rel, ".md") {
+			w.queue(rel)
+		}
+	case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+		// Removed directories are no longer watched automatically; their memories are queued by path
+		w.queue(rel)
+	}
+}
+
+// relative returns the slash-separated path of a file below the root, or false for the root
+// itself and for hidden files and directories
+func (w *Watcher) relative(path string) (string, bool) {
+	rel, err := filepath.Rel(w.root, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+
+	rel = filepath.ToSlash(rel)
+	for segment := range strings.SplitSeq(rel, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return "", false
+		}
+	}
+	return rel, true
+}
+
+// addTree watches a directory and its visible subdirectories. With queueFiles, memory files found
+// in them are queued as well.
+func (w *Watcher) addTree(dir string, queueFiles bool) error {
+	return filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != w.root && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() {
+			return w.fsw.Add(p)
+		}
+		if queueFiles && strings.HasSuffix(entry.Name(), ".md") {
+			if rel, ok := w.relative(p); ok {
+				w.queue(rel)
+			}
+		}
+		return nil
+	})
+}
+
+// queue adds a changed path and restarts the debounce interval
+func (w *Watcher) queue(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending[path] = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(w.debounce, w.flush)
+}
+
+// flush passes the queued paths to the callback
+func (w *Watcher) flush() {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	paths := make([]string, 0, len(w.pending))
+	for path := range w.pending {
+		paths = append(paths, path)
+	}
+	w.pending = make(map[string]bool)
+	w.mu.Unlock()
+
+	if len(paths) == 0 {
+		return
+	}
+	sort.Strings(paths)
+	w.onChange(paths)
+}
+
+// writeLog remembers the version of the content the store last wrote to each memory, an empty
+// version for memories it deleted or moved away, so the watcher can skip the events of the
+// store's own writes
+type writeLog struct {
+	mu       sync.Mutex
+	versions map[string]string
+}
+
+// note records a write of content to a memory; nil content records a deletion
+func (l *writeLog) note(name string, content []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.versions == nil {
+		l.versions = make(map[string]string)
+	}
+	version := ""
+	if content != nil {
+		version = ContentVersion(string(content))
+	}
+	l.versions[strings.TrimSuffix(name, ".md")] = version
+}
+
+// forget drops the write recorded for a memory once another program has changed it
+func (l *writeLog) forget(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.versions, name)
+}
+
+// wrote reports whether the store itself last left a memory with content of the given version,
+// or deleted it when version is empty
+func (l *writeLog) wrote(name, version string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	written, ok := l.versions[name]
+	return ok && written == version
+}
+
+// nameLocks serializes the database bookkeeping of each memory, so syncs and prunes started by
+// the watcher cannot interleave with those of the tools
+type nameLocks struct {
+	mu    sync.Mutex
+	locks map[string]*nameLock
+}
+
+type nameLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks the given memory names, in sorted order so concurrent callers cannot deadlock, and
+// returns the function that unlocks them
+func (l *nameLocks) lock(names ...string) func() {
+	names = append([]string(nil), names...)
+	for i := range names {
+		names[i] = strings.TrimSuffix(names[i], ".md")
+	}
+	sort.Strings(names)
+
+	var held []string
+	for i, name := range names {
+		if i > 0 && name == names[i-1] {
+			continue
+		}
+
+		l.mu.Lock()
+		if l.locks == nil {
+			l.locks = make(map[string]*nameLock)
+		}
+		entry := l.locks[name]
+		if entry == nil {
+			entry = &nameLock{}
+			l.locks[name] = entry
+		}
+		entry.refs++
+		l.mu.Unlock()
+
+		entry.Lock()
+		held = append(held, name)
+	}
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for _, name := range held {
+			entry := l.locks[name]
+			entry.Unlock()
+			if entry.refs--; entry.refs == 0 {
+				delete(l.locks, name)
+			}
+		}
+	}
+}
+
+// Watch keeps the database in sync with edits made to the memory directory by other programs
+// until the store is closed
+func (es *EnhancedStore) Watch(cfg config.WatchConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	debounce := time.Duration(cfg.DebounceMS) * time.Millisecond
+	watcher, err := NewWatcher(es.basePath, debounce, es.syncChangedPaths)
+	if err != nil {
+		return err
+	}
+	es.watcher = watcher
+	log.Printf("[WATCH] Watching %s for external changes", es.basePath)
+	return nil
+}
+
+// syncChangedPaths syncs changed memory files to the database through the same path as the tools,
+// removing memories whose files are gone. Other paths are treated as namespace directories: new
+// ones are synced and memories below removed ones are deleted.
+func (es *EnhancedStore) syncChangedPaths(paths []string) {
+	changed := make(map[string]bool)
+	for _, path := range paths {
+		if name, ok := strings.CutSuffix(path, ".md"); ok {
+			changed[name] = true
+			continue
+		}
+
+		names, err := es.List()
+		if err != nil {
+			log.Printf("[WATCH] Failed to list memories: %v", err)
+			continue
+		}
+		indexed, err := es.db.GetMemoryNames()
+		if err != nil {
+			log.Printf("[WATCH] Failed to list indexed memories: %v", err)
+			continue
+		}
+		for _, name := range names {
+			if InNamespace(name, path) {
+				changed[name] = true
+			}
+		}
+		for _, name := range indexed {
+			if InNamespace(name, path) {
+				changed[name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(changed))
+	for name := range changed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	log.Printf("[WATCH] Syncing %d changed memories", len(names))
+	for _, name := range names {
+		es.syncChangedMemory(name)
+	}
+}
+
+// syncChangedMemory syncs a memory that may have changed or been deleted outside simplemem.
+// Changes that leave the memory as simplemem itself last wrote it are skipped; the tool that
+// wrote it syncs it.
+func (es *EnhancedStore) syncChangedMemory(name string) {
+	version := ""
+	data, err := os.ReadFile(es.path(name))
+	if err == nil {
+		version = ContentVersion(string(data))
+	}
+	if (err == nil || os.IsNotExist(err)) && es.Store.written.wrote(name, version) {
+		return
+	}
+	es.Store.written.forget(name)
+
+	if _, err := os.Stat(es.path(name)); err == nil {
+		if err := es.syncMemoryToDatabase(name); err != nil {
+			log.Printf("[WATCH] Failed to sync memory %s: %v", name, err)
+		}
+		return
+	} else if !os.IsNotExist(err) {
+		log.Printf("[WATCH] Failed to check memory %s: %v", name, err)
+		return
+	}
+
+	removed, err := es.pruneMemory(name)
+	if err != nil {
+		log.Printf("[WATCH] Failed to remove deleted memory %s: %v", name, err)
+	} else if removed {
+		log.Printf("[WATCH] Removed %s, whose file was deleted", name)
+	}
+}
@@ -0,0 +1,156 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".history"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	batches := make(chan []string, 10)
+	w, err := NewWatcher(root, 50*time.Millisecond, func(paths []string) {
+		batches <- paths
+	})
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+	defer w.Close()
+
+	next := func() []string {
+		t.Helper()
+		select {
+		case paths := <-batches:
+			return paths
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for changes")
+			return nil
+		}
+	}
+	write := func(rel, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(rel)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A burst of edits is reported once; hidden files are ignored
+	write("notes.md", "first\n")
+	write("notes.md", "second\n")
+	write(".history/ignored.md", "x\n")
+	write("scratch.txt", "x\n")
+	if got := next(); !slices.Equal(got, []string{"notes.md"}) {
+		t.Errorf("expected notes.md once, got %v", got)
+	}
+
+	// Memories in new namespace directories are picked up, even if written before the watch
+	if err := os.MkdirAll(filepath.Join(root, "project", "db"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write("project/db/schema.md", "schema\n")
+	if got := strings.Join(next(), ","); !strings.Contains(got, "project/db/schema.md") {
+		t.Errorf("expected project/db/schema.md, got %s", got)
+	}
+	write("project/db/schema.md", "schema v2\n")
+	if got := next(); !slices.Equal(got, []string{"project/db/schema.md"}) {
+		t.Errorf("expected the nested edit to be watched, got %v", got)
+	}
+
+	// Renames and deletions report the old paths
+	if err := os.Rename(filepath.Join(root, "notes.md"), filepath.Join(root, "renamed.md")); err != nil {
+		t.Fatal(err)
+	}
+	if got := next(); !slices.Equal(got, []string{"notes.md", "renamed.md"}) {
+		t.Errorf("expected both names of the rename, got %v", got)
+	}
+	if err := os.RemoveAll(filepath.Join(root, "project")); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(next(), ","); !strings.Contains(got, "project") {
+		t.Errorf("expected the removed namespace, got %s", got)
+	}
+}
+
+func TestSyncChangedMemory(t *testing.T) {
+	es := newTestEnhancedStore(t, map[string]string{"synced": "---\ntitle: Synced\n---\nBody.\n"})
+	indexed := func(name string) bool {
+		t.Helper()
+		memory, err := es.db.GetMemory(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return memory != nil
+	}
+
+	// The store's own writes are left to the tool that made them
+	if err := es.Store.Create("own", "---\ntitle: Own\n---\nBody.\n"); err != nil {
+		t.Fatal(err)
+	}
+	es.syncChangedMemory("own")
+	if indexed("own") {
+		t.Error("expected the store's own write to be skipped")
+	}
+	if err := es.Store.Delete("synced"); err != nil {
+		t.Fatal(err)
+	}
+	es.syncChangedMemory("synced")
+	if !indexed("synced") {
+		t.Error("expected the store's own deletion to be skipped")
+	}
+
+	// Edits by other programs are synced, and deleted files pruned
+	if err := os.WriteFile(es.path("own"), []byte("---\ntitle: Edited\n---\nBody.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	es.syncChangedMemory("own")
+	if !indexed("own") {
+		t.Error("expected an external edit to be synced")
+	}
+	if err := os.Remove(es.path("own")); err != nil {
+		t.Fatal(err)
+	}
+	es.syncChangedMemory("own")
+	if indexed("own") {
+		t.Error("expected an external deletion to be pruned")
+	}
+}
+
+func TestNameLocks(t *testing.T) {
+	var locks nameLocks
+	unlock := locks.lock("b", "a.md", "a")
+
+	acquired, released := make(chan struct{}), make(chan struct{})
+	go func() {
+		unlockA := locks.lock("a")
+		close(acquired)
+		unlockA()
+		close(released)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("expected the name to stay locked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the lock")
+	}
+
+	// Unused locks are dropped
+	<-released
+	locks.mu.Lock()
+	defer locks.mu.Unlock()
+	if len(locks.locks) != 0 {
+		t.Errorf("expected no locks left, got %v", locks.locks)
+	}
+}
@@ -0,0 +1,144 @@
+package rag
+
+import (
+	"math"
+	"math/rand"
+)
+
+// maxKMeansIterations bounds the number of assignment/update rounds
+const maxKMeansIterations = 100
+
+// KMeans partitions vectors into k clusters by cosine similarity (spherical k-means with
+// k-means++ seeding) and returns the cluster index of each vector. The seed makes the result
+// reproducible.
+func KMeans(vectors [][]float32, k int, seed int64) []int {
+	assignments := make([]int, len(vectors))
+	if len(vectors) == 0 {
+		return assignments
+	}
+	if k > len(vectors) {
+		k = len(vectors)
+	}
+	if k <= 1 {
+		return assignments
+	}
+
+	points := make([][]float32, len(vectors))
+	for i, vector := range vectors {
+		points[i] = normalize(vector)
+	}
+
+	centers := seedCenters(points, k, rand.New(rand.NewSource(seed)))
+
+	for iteration := 0; iteration < maxKMeansIterations; iteration++ {
+		changed := iteration == 0
+		for i, point := range points {
+			best, bestSimilarity := 0, float32(math.Inf(-1))
+			for c, center := range centers {
+				if similarity := dot(point, center); similarity > bestSimilarity {
+					best, bestSimilarity = c, similarity
+				}
+			}
+			if assignments[i] != best {
+				assignments[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+
+		// Move each center to the normalized mean of its members; empty clusters keep their center
+		members := make([][][]float32, k)
+		for i, point := range points {
+			members[assignments[i]] = append(members[assignments[i]], point)
+		}
+		for c := range centers {
+			if len(members[c]) > 0 {
+				centers[c] = normalize(centroid(members[c]))
+			}
+		}
+	}
+
+	return assignments
+}
+
+// MemoryVectors reduces each memory's chunk embeddings to a single unit-length centroid
+func MemoryVectors(chunks map[int][][]float32) map[int][]float32 {
+	vectors := make(map[int][]float32, len(chunks))
+	for memoryID, memoryChunks := range chunks {
+		if len(memoryChunks) > 0 {
+			vectors[memoryID] = normalize(centroid(memoryChunks))
+		}
+	}
+	return vectors
+}
+
+// seedCenters picks k initial centers with k-means++: each new center is chosen with probability
+// proportional to its squared cosine distance from the nearest existing center
+func seedCenters(points [][]float32, k int, rng *rand.Rand) [][]float32 {
+	centers := [][]float32{points[rng.Intn(len(points))]}
+	distances := make([]float64, len(points))
+
+	for len(centers) < k {
+		var total float64
+		for i, point := range points {
+			nearest := math.Inf(1)
+			for _, center := range centers {
+				distance := 1 - float64(dot(point, center))
+				nearest = math.Min(nearest, distance*distance)
+			}
+			distances[i] = nearest
+			total += nearest
+		}
+
+		// All remaining points coincide with a center
+		if total == 0 {
+			centers = append(centers, points[len(centers)%len(points)])
+			continue
+		}
+
+		target := rng.Float64() * total
+		chosen := len(points) - 1
+		for i, distance := range distances {
+			target -= distance
+			if target <= 0 {
+				chosen = i
+				break
+			}
+		}
+		centers = append(centers, points[chosen])
+	}
+
+	return centers
+}
+
+// normalize returns a unit-length copy of a vector, or a zero vector unchanged
+func normalize(vector []float32) []float32 {
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+
+	normalized := make([]float32, len(vector))
+	if norm == 0 {
+		return normalized
+	}
+	norm = math.Sqrt(norm)
+	for i, v := range vector {
+		normalized[i] = float32(float64(v) / norm)
+	}
+	return normalized
+}
+
+// dot returns the dot product of two vectors
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		if i >= len(b) {
+			break
+		}
+		sum += a[i] * b[i]
+	}
+	return sum
+}
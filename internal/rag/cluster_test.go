@@ -0,0 +1,31 @@
+package rag
+
+import "testing"
+
+func TestKMeans(t *testing.T) {
+	vectors := [][]float32{
+		{1, 0.1, 0}, {0.9, 0, 0.1}, {1, 0, 0},
+		{0, 1, 0.1}, {0.1, 0.9, 0}, {0, 1, 0},
+		{0, 0.1, 1},
+	}
+
+	assignments := KMeans(vectors, 3, 1)
+	groups := [][]int{{0, 1, 2}, {3, 4, 5}, {6}}
+	for _, group := range groups {
+		for _, i := range group[1:] {
+			if assignments[i] != assignments[group[0]] {
+				t.Errorf("expected vectors %v in one cluster, got %v", group, assignments)
+			}
+		}
+	}
+	if assignments[0] == assignments[3] || assignments[0] == assignments[6] || assignments[3] == assignments[6] {
+		t.Errorf("expected three distinct clusters, got %v", assignments)
+	}
+
+	if got := KMeans(vectors, 1, 1); got[0] != 0 || got[6] != 0 {
+		t.Errorf("expected a single cluster, got %v", got)
+	}
+	if got := KMeans(vectors[:2], 5, 1); len(got) != 2 {
+		t.Errorf("expected one assignment per vector, got %v", got)
+	}
+}
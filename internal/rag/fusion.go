@@ -0,0 +1,126 @@
+package rag
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/jcdickinson/simplemem/internal/db"
+)
+
+// rrfK is the rank constant for reciprocal rank fusion (60 is the value from the original RRF paper)
+const rrfK = 60
+
+// FusedResult represents a memory matched by one or more queries in a multi-query search
+type FusedResult struct {
+	Memory         db.Memory
+	Score          float32            // Reciprocal rank fusion score
+	MatchedQueries []string           // Queries that returned this memory, in query order
+	Similarities   map[string]float32 // Best similarity per matching query
+}
+
+// rankedList is the ordered result of a single query, used as input to fusion
+type rankedList struct {
+	Query   string
+	Results []struct {
+		Memory     db.Memory
+		Similarity float32
+	}
+}
+
+// SearchMultipleQueriesWithTags embeds all queries in one batch, searches them in parallel and
+// merges the results with reciprocal rank fusion. A non-empty namespace restricts the search to it.
+func (p *Processor) SearchMultipleQueriesWithTags(queries []string, tagFilters []db.TagFilter, requireAll bool, namespace string, limit int) ([]FusedResult, error) {
+	log.Printf("[MULTI SEARCH] Starting search - Queries: %q, TagFilters: %+v, RequireAll: %v, Namespace: '%s', Limit: %d", queries, tagFilters, requireAll, namespace, limit)
+
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("at least one query is required")
+	}
+
+	// Generate embeddings for all uncached queries in a single batch
+	queryEmbeddings, err := p.embedQueries(queries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query embeddings: %w", err)
+	}
+
+	// Fetch more candidates per query than requested so fusion has something to work with
+	perQueryLimit := limit * 3
+
+	lists := make([]rankedList, len(queries))
+	errs := make([]error, len(queries))
+
+	var wg sync.WaitGroup
+	for i := range queries {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results, err := p.searchByEmbedding(queryEmbeddings[i], tagFilters, requireAll, namespace, perQueryLimit)
+			lists[i] = rankedList{Query: queries[i], Results: results}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("search failed for query '%s': %w", queries[i], err)
+		}
+	}
+
+	fused := fuseRankings(lists)
+	if limit > 0 && len(fused) > limit {
+		fused = fused[:limit]
+	}
+
+	log.Printf("[MULTI SEARCH] Returning %d fused results", len(fused))
+	return fused, nil
+}
+
+// fuseRankings merges several ranked lists using reciprocal rank fusion
+func fuseRankings(lists []rankedList) []FusedResult {
+	byID := make(map[int]*FusedResult)
+	var order []int
+
+	for _, list := range lists {
+		// The vector search returns one row per matching chunk, so a memory can appear
+		// more than once in a single list; only its best (first) rank counts
+		rank := 0
+		seen := make(map[int]bool)
+		for _, result := range list.Results {
+			if seen[result.Memory.ID] {
+				continue
+			}
+			seen[result.Memory.ID] = true
+			rank++
+
+			fused, exists := byID[result.Memory.ID]
+			if !exists {
+				fused = &FusedResult{
+					Memory:       result.Memory,
+					Similarities: make(map[string]float32),
+				}
+				byID[result.Memory.ID] = fused
+				order = append(order, result.Memory.ID)
+			}
+
+			fused.Score += 1.0 / float32(rrfK+rank)
+			if _, matched := fused.Similarities[list.Query]; !matched {
+				fused.MatchedQueries = append(fused.MatchedQueries, list.Query)
+				fused.Similarities[list.Query] = result.Similarity
+			}
+		}
+	}
+
+	results := make([]FusedResult, 0, len(order))
+	for _, id := range order {
+		results = append(results, *byID[id])
+	}
+
+	// Stable sort keeps first-seen order for ties
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results
+}
@@ -0,0 +1,79 @@
+package rag
+
+import (
+	"testing"
+
+	"github.com/jcdickinson/simplemem/internal/db"
+)
+
+func makeRankedList(query string, names ...string) rankedList {
+	list := rankedList{Query: query}
+	for i, name := range names {
+		list.Results = append(list.Results, struct {
+			Memory     db.Memory
+			Similarity float32
+		}{
+			Memory:     db.Memory{ID: int(name[0]), Name: name},
+			Similarity: 1 - float32(i)*0.1,
+		})
+	}
+	return list
+}
+
+func TestFuseRankings(t *testing.T) {
+	lists := []rankedList{
+		makeRankedList("duckdb schema", "a", "b", "c"),
+		makeRankedList("migrations", "b", "d"),
+		makeRankedList("table creation", "b", "a"),
+	}
+
+	results := fuseRankings(lists)
+	if len(results) != 4 {
+		t.Fatalf("expected 4 fused results, got %d", len(results))
+	}
+
+	// b is ranked by all three queries, so it must come first
+	if results[0].Memory.Name != "b" {
+		t.Errorf("expected 'b' first, got %q", results[0].Memory.Name)
+	}
+	if len(results[0].MatchedQueries) != 3 {
+		t.Errorf("expected 'b' to match 3 queries, got %v", results[0].MatchedQueries)
+	}
+
+	// a is matched by two queries and should beat single-query matches
+	if results[1].Memory.Name != "a" {
+		t.Errorf("expected 'a' second, got %q", results[1].Memory.Name)
+	}
+	expected := []string{"duckdb schema", "table creation"}
+	for i, q := range expected {
+		if results[1].MatchedQueries[i] != q {
+			t.Errorf("expected matched queries %v, got %v", expected, results[1].MatchedQueries)
+			break
+		}
+	}
+}
+
+func TestFuseRankingsDeduplicatesChunks(t *testing.T) {
+	// Multiple chunks of the same memory must only count once per query
+	lists := []rankedList{
+		makeRankedList("q1", "a", "a", "b"),
+		makeRankedList("q2", "b", "c"),
+	}
+
+	results := fuseRankings(lists)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 fused results, got %d", len(results))
+	}
+
+	for _, r := range results {
+		if r.Memory.Name == "a" {
+			want := float32(1.0 / (rrfK + 1))
+			if r.Score != want {
+				t.Errorf("expected score %f for 'a', got %f", want, r.Score)
+			}
+			if sim := r.Similarities["q1"]; sim != 1 {
+				t.Errorf("expected best similarity 1 for 'a', got %f", sim)
+			}
+		}
+	}
+}
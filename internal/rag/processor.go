@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 	"time"
 
@@ -20,7 +21,8 @@ type Processor struct {
 	chunkConfig     embeddings.ChunkConfig
 	model           string
 	rerankModel     string
-	similarityThreshold float32
+	backlinks       backlinkSettings
+	queryCache      *embeddings.QueryCache // nil when the query cache is disabled
 }
 
 // NewProcessor creates a new RAG processor
@@ -38,13 +40,18 @@ func NewProcessor(database *db.DB, cfg *config.Config) (*Processor, error) {
 		model = "voyage-3.5"
 	}
 
+	backlinks, err := newBacklinkSettings(cfg.SemanticBacklinks)
+	if err != nil {
+		return nil, err
+	}
+
 	processor := &Processor{
-		db:                  database,
-		voyageClient:        voyageClient,
-		batchEmbedder:       batchEmbedder,
-		chunkConfig:         embeddings.DefaultChunkConfig(),
-		model:               model,
-		similarityThreshold: 0.5, // Minimum similarity for semantic backlinks (lowered from 0.7)
+		db:            database,
+		voyageClient:  voyageClient,
+		batchEmbedder: batchEmbedder,
+		chunkConfig:   embeddings.DefaultChunkConfig(),
+		model:         model,
+		backlinks:     backlinks,
 	}
 
 	// Store rerank model for later use
@@ -53,9 +60,88 @@ func NewProcessor(database *db.DB, cfg *config.Config) (*Processor, error) {
 		processor.rerankModel = "rerank-lite-1"
 	}
 
+	// Cache query embeddings so repeated searches don't hit the API
+	if cfg.QueryCache.Size > 0 {
+		processor.queryCache = embeddings.NewQueryCache(cfg.QueryCache.Size, cfg.QueryCache.Path)
+		if err := processor.queryCache.Load(); err != nil {
+			log.Printf("Warning: failed to load query cache, starting empty: %v", err)
+		}
+	}
+
 	return processor, nil
 }
 
+// Close persists the query cache, if enabled
+func (p *Processor) Close() error {
+	if p.queryCache == nil {
+		return nil
+	}
+
+	stats := p.queryCache.Stats()
+	log.Printf("[QUERY CACHE] Final stats - hits: %d, misses: %d, evictions: %d, size: %d/%d, hit rate: %.1f%%",
+		stats.Hits, stats.Misses, stats.Evictions, stats.Size, stats.Capacity, stats.HitRate()*100)
+
+	return p.queryCache.Save()
+}
+
+// QueryCacheStats returns query cache metrics, and false if the cache is disabled
+func (p *Processor) QueryCacheStats() (embeddings.CacheStats, bool) {
+	if p.queryCache == nil {
+		return embeddings.CacheStats{}, false
+	}
+	return p.queryCache.Stats(), true
+}
+
+// queryInputType is the input type queries are embedded with. Stored chunks are embedded without
+// one, and queries must match them for their similarities to be comparable.
+const queryInputType = ""
+
+// embedQueries generates query embeddings, serving repeats from the query cache and embedding
+// the remaining queries in a single batch
+func (p *Processor) embedQueries(queries []string) ([][]float32, error) {
+	results := make([][]float32, len(queries))
+
+	var missing []string
+	var missingIndexes []int
+	for i, query := range queries {
+		if p.queryCache != nil {
+			if embedding, ok := p.queryCache.Get(p.model, queryInputType, query); ok {
+				results[i] = embedding
+				continue
+			}
+		}
+		missing = append(missing, query)
+		missingIndexes = append(missingIndexes, i)
+	}
+
+	if p.queryCache != nil {
+		stats := p.queryCache.Stats()
+		log.Printf("[QUERY CACHE] %d/%d queries served from cache (total hits: %d, misses: %d)",
+			len(queries)-len(missing), len(queries), stats.Hits, stats.Misses)
+	}
+
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	embedded, err := p.batchEmbedder.EmbedAllTexts(missing, p.model, queryInputType)
+	if err != nil {
+		return nil, err
+	}
+	if len(embedded) != len(missing) {
+		return nil, fmt.Errorf("expected %d query embeddings, got %d", len(missing), len(embedded))
+	}
+
+	for j, embedding := range embedded {
+		results[missingIndexes[j]] = embedding
+		if p.queryCache != nil {
+			p.queryCache.Put(p.model, queryInputType, missing[j], embedding)
+		}
+	}
+
+	return results, nil
+}
+
 // ProcessMemory handles the complete RAG workflow for a memory
 func (p *Processor) ProcessMemory(memory *db.Memory) error {
 	log.Printf("Processing memory: %s", memory.Name)
@@ -69,6 +155,9 @@ func (p *Processor) ProcessMemory(memory *db.Memory) error {
 	chunks := embeddings.ChunkMarkdown(memory.Body, p.chunkConfig)
 	if len(chunks) == 0 {
 		log.Printf("No chunks generated for memory: %s", memory.Name)
+		if err := p.updateSemanticBacklinks(memory.ID); err != nil {
+			log.Printf("Failed to clear semantic backlinks for %s: %v", memory.Name, err)
+		}
 		return p.db.MarkMemoryProcessed(memory.ID)
 	}
 
@@ -94,13 +183,10 @@ func (p *Processor) ProcessMemory(memory *db.Memory) error {
 		}
 	}
 
-	// 5. Find similar memories using the first chunk's embedding
-	// (We use the first chunk as it's typically the most representative)
-	if len(chunkEmbeddings) > 0 {
-		if err := p.updateSemanticBacklinks(memory.ID, chunkEmbeddings[0]); err != nil {
-			log.Printf("Failed to update semantic backlinks for %s: %v", memory.Name, err)
-			// Don't fail the entire process if backlinks fail
-		}
+	// 5. Recompute semantic backlinks from all chunks, dropping pairs that no longer qualify
+	if err := p.updateSemanticBacklinks(memory.ID); err != nil {
+		log.Printf("Failed to update semantic backlinks for %s: %v", memory.Name, err)
+		// Don't fail the entire process if backlinks fail
 	}
 
 	// 6. Mark memory as processed
@@ -112,33 +198,63 @@ func (p *Processor) ProcessMemory(memory *db.Memory) error {
 	return nil
 }
 
-// updateSemanticBacklinks finds similar memories and creates bidirectional links
-func (p *Processor) updateSemanticBacklinks(memoryID int, representativeEmbedding []float32) error {
-	// Find similar memories
-	similarMemories, err := p.db.FindSimilarMemories(
-		representativeEmbedding,
-		p.similarityThreshold,
-		20, // Limit to top 20 similar memories
-		memoryID,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to find similar memories: %w", err)
+// ProcessMemoryReusing processes a memory like ProcessMemory, but stores previously computed
+// embeddings instead of generating new ones when they were made from the same chunks, as for a
+// memory restored from the trash
+func (p *Processor) ProcessMemoryReusing(memory *db.Memory, saved []db.Embedding) error {
+	chunks := embeddings.ChunkMarkdown(memory.Body, p.chunkConfig)
+	if len(chunks) == 0 || len(chunks) != len(saved) {
+		return p.ProcessMemory(memory)
+	}
+	for i, chunk := range chunks {
+		if chunk.Text != saved[i].ChunkText || chunk.Index != saved[i].ChunkIndex {
+			return p.ProcessMemory(memory)
+		}
 	}
 
-	log.Printf("Found %d similar memories for memory ID %d", len(similarMemories), memoryID)
+	log.Printf("Reusing %d saved embeddings for memory: %s", len(saved), memory.Name)
 
-	// Create semantic backlinks
-	for _, similar := range similarMemories {
-		if err := p.db.UpsertSemanticBacklink(memoryID, similar.Memory.ID, similar.Similarity); err != nil {
-			log.Printf("Failed to create semantic backlink between %d and %d: %v", 
-				memoryID, similar.Memory.ID, err)
-			// Continue with other backlinks even if one fails
-		} else {
-			log.Printf("Created semantic backlink between %d and %d (similarity: %.3f)", 
-				memoryID, similar.Memory.ID, similar.Similarity)
+	if err := p.db.DeleteEmbeddingsByMemoryID(memory.ID); err != nil {
+		return fmt.Errorf("failed to delete existing embeddings: %w", err)
+	}
+
+	for i, embedding := range saved {
+		embedding.MemoryID = memory.ID
+		if err := p.db.InsertEmbedding(&embedding); err != nil {
+			return fmt.Errorf("failed to insert embedding %d: %w", i, err)
 		}
 	}
 
+	if err := p.updateSemanticBacklinks(memory.ID); err != nil {
+		log.Printf("Failed to update semantic backlinks for %s: %v", memory.Name, err)
+	}
+
+	return p.db.MarkMemoryProcessed(memory.ID)
+}
+
+// updateSemanticBacklinks scores a memory against every other memory from its stored chunk
+// embeddings, in the database, and replaces the backlinks it chose with the top matches
+func (p *Processor) updateSemanticBacklinks(memoryID int) error {
+	if err := p.db.UpdateMemoryCentroid(memoryID); err != nil {
+		return err
+	}
+
+	var similarities map[int]float32
+	var err error
+	if p.backlinks.method == SimilarityCentroid {
+		similarities, err = p.db.SimilarByCentroid(memoryID, p.backlinks.threshold, p.backlinks.topN)
+	} else {
+		similarities, err = p.db.SimilarByTopKPairs(memoryID, p.backlinks.topK, p.backlinks.threshold, p.backlinks.topN)
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("Found %d similar memories for memory ID %d (method: %s)", len(similarities), memoryID, p.backlinks.method)
+
+	if err := p.db.ReplaceSemanticBacklinks(memoryID, similarities); err != nil {
+		return fmt.Errorf("failed to replace semantic backlinks: %w", err)
+	}
+
 	return nil
 }
 
@@ -168,17 +284,18 @@ func (p *Processor) ProcessAllPendingMemories() error {
 
 // SearchSimilarMemories performs semantic search using embeddings
 func (p *Processor) SearchSimilarMemories(query string, limit int) ([]db.Memory, []float32, error) {
-	return p.SearchSimilarMemoriesWithTags(query, nil, false, limit)
+	return p.SearchSimilarMemoriesWithTags(query, nil, false, "", limit)
 }
 
-// SearchSimilarMemoriesWithTags performs semantic search using embeddings with tag filtering
-func (p *Processor) SearchSimilarMemoriesWithTags(query string, tagFilters []db.TagFilter, requireAll bool, limit int) ([]db.Memory, []float32, error) {
-	log.Printf("[SEMANTIC SEARCH] Starting search - Query: '%s', TagFilters: %+v, RequireAll: %v, Limit: %d", query, tagFilters, requireAll, limit)
+// SearchSimilarMemoriesWithTags performs semantic search using embeddings with tag filtering.
+// A non-empty namespace restricts the search to memories in it and its sub-namespaces.
+func (p *Processor) SearchSimilarMemoriesWithTags(query string, tagFilters []db.TagFilter, requireAll bool, namespace string, limit int) ([]db.Memory, []float32, error) {
+	log.Printf("[SEMANTIC SEARCH] Starting search - Query: '%s', TagFilters: %+v, RequireAll: %v, Namespace: '%s', Limit: %d", query, tagFilters, requireAll, namespace, limit)
 	
 	// If only tag filtering (no semantic search), use direct tag search
-	if query == "" && len(tagFilters) > 0 {
+	if query == "" && (len(tagFilters) > 0 || namespace != "") {
 		log.Printf("[SEMANTIC SEARCH] Empty query with tag filters - using direct tag search")
-		memories, err := p.db.GetMemoriesByTags(tagFilters, requireAll, limit)
+		memories, err := p.db.GetMemoriesByTags(tagFilters, requireAll, namespace, limit)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get memories by tags: %w", err)
 		}
@@ -196,11 +313,12 @@ func (p *Processor) SearchSimilarMemoriesWithTags(query string, tagFilters []db.
 
 	log.Printf("[SEMANTIC SEARCH] Generating embedding for query using model: %s", p.model)
 	// Generate embedding for the search query
-	queryEmbedding, err := p.voyageClient.EmbedSingle(query, p.model)
+	queryEmbeddings, err := p.embedQueries([]string{query})
 	if err != nil {
 		log.Printf("[SEMANTIC SEARCH] ERROR: Failed to generate query embedding: %v", err)
 		return nil, nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
+	queryEmbedding := queryEmbeddings[0]
 	
 	log.Printf("[SEMANTIC SEARCH] Successfully generated embedding vector of length: %d", len(queryEmbedding))
 	if len(queryEmbedding) > 0 {
@@ -212,34 +330,7 @@ func (p *Processor) SearchSimilarMemoriesWithTags(query string, tagFilters []db.
 	}
 
 	// Find similar memories with tag filtering
-	var similarMemories []struct {
-		Memory     db.Memory
-		Similarity float32
-	}
-	
-	threshold := float32(0.1)
-	log.Printf("[SEMANTIC SEARCH] Searching with threshold: %.3f, limit: %d", threshold, limit)
-	
-	if len(tagFilters) > 0 {
-		log.Printf("[SEMANTIC SEARCH] Using tagged search with %d tag filters", len(tagFilters))
-		similarMemories, err = p.db.FindSimilarMemoriesWithTags(
-			queryEmbedding,
-			threshold, // Much lower threshold for search results (was 0.3)
-			limit,
-			-1, // Don't exclude any memories
-			tagFilters,
-			requireAll,
-		)
-	} else {
-		log.Printf("[SEMANTIC SEARCH] Using unfiltered semantic search")
-		similarMemories, err = p.db.FindSimilarMemories(
-			queryEmbedding,
-			threshold, // Much lower threshold for search results (was 0.3)
-			limit,
-			-1, // Don't exclude any memories
-		)
-	}
-	
+	similarMemories, err := p.searchByEmbedding(queryEmbedding, tagFilters, requireAll, namespace, limit)
 	if err != nil {
 		log.Printf("[SEMANTIC SEARCH] ERROR: Database search failed: %v", err)
 		return nil, nil, fmt.Errorf("failed to find similar memories: %w", err)
@@ -260,6 +351,37 @@ func (p *Processor) SearchSimilarMemoriesWithTags(query string, tagFilters []db.
 	return memories, similarities, nil
 }
 
+// searchByEmbedding runs a vector search for a single query embedding, applying tag and namespace
+// filters when present
+func (p *Processor) searchByEmbedding(queryEmbedding []float32, tagFilters []db.TagFilter, requireAll bool, namespace string, limit int) ([]struct {
+	Memory     db.Memory
+	Similarity float32
+}, error) {
+	threshold := float32(0.1)
+	log.Printf("[SEMANTIC SEARCH] Searching with threshold: %.3f, limit: %d", threshold, limit)
+
+	if len(tagFilters) > 0 || namespace != "" {
+		log.Printf("[SEMANTIC SEARCH] Using filtered search with %d tag filters, namespace '%s'", len(tagFilters), namespace)
+		return p.db.FindSimilarMemoriesWithTags(
+			queryEmbedding,
+			threshold, // Much lower threshold for search results (was 0.3)
+			limit,
+			-1, // Don't exclude any memories
+			tagFilters,
+			requireAll,
+			namespace,
+		)
+	}
+
+	log.Printf("[SEMANTIC SEARCH] Using unfiltered semantic search")
+	return p.db.FindSimilarMemories(
+		queryEmbedding,
+		threshold, // Much lower threshold for search results (was 0.3)
+		limit,
+		-1, // Don't exclude any memories
+	)
+}
+
 // GetSemanticBacklinks retrieves memories semantically related to the given memory
 func (p *Processor) GetSemanticBacklinks(memoryName string, minSimilarity float32) ([]db.Memory, []float32, error) {
 	// Get the memory first
@@ -322,7 +444,7 @@ type BacklinkResult struct {
 	Snippet        string
 	LinkType       string // "explicit", "semantic"
 	RelevanceScore float32
-	SourceType     string // "wiki", "markdown", "embedding"
+	SourceType     string // "wiki", "markdown", "frontmatter", "embedding"
 }
 
 // GetEnhancedBacklinks retrieves and reranks both explicit and semantic backlinks
@@ -351,7 +473,16 @@ func (p *Processor) GetEnhancedBacklinks(memoryName string, query string, limit
 	if err != nil {
 		log.Printf("Warning: failed to get semantic backlinks: %v", err)
 	} else {
-		allResults = append(allResults, semanticBacklinks...)
+		// Memories that already link explicitly are not repeated as semantic matches
+		explicitIDs := make(map[int]bool)
+		for _, result := range allResults {
+			explicitIDs[result.Memory.ID] = true
+		}
+		for _, result := range semanticBacklinks {
+			if !explicitIDs[result.Memory.ID] {
+				allResults = append(allResults, result)
+			}
+		}
 	}
 
 	// 3. If we have a query and results, rerank them
@@ -372,9 +503,47 @@ func (p *Processor) GetEnhancedBacklinks(memoryName string, query string, limit
 
 // getExplicitBacklinks finds memories that explicitly link to the target memory
 func (p *Processor) getExplicitBacklinks(targetMemoryName string) ([]BacklinkResult, error) {
-	// This would need to be implemented by scanning all memories for links
-	// For now, return empty slice as we'd need to integrate with the memory store
-	return []BacklinkResult{}, nil
+	links, err := p.db.GetInboundLinks(targetMemoryName)
+	if err != nil {
+		return nil, err
+	}
+
+	// A memory may link to the target several times; report it once, preferring body links
+	// (which carry the surrounding sentence) over frontmatter links
+	var results []BacklinkResult
+	index := make(map[int]int)
+	for _, inbound := range links {
+		if inbound.Source.Name == targetMemoryName {
+			continue // Ignore self-links
+		}
+
+		snippet := inbound.Link.Context
+		if snippet == "" {
+			snippet = inbound.Source.Body
+			if len(snippet) > 200 {
+				snippet = snippet[:200] + "..."
+			}
+		}
+
+		result := BacklinkResult{
+			Memory:         inbound.Source,
+			Snippet:        snippet,
+			LinkType:       "explicit",
+			RelevanceScore: 1.0,
+			SourceType:     inbound.Link.LinkType,
+		}
+
+		if i, seen := index[inbound.Source.ID]; seen {
+			if results[i].SourceType == "frontmatter" && result.SourceType != "frontmatter" {
+				results[i] = result
+			}
+			continue
+		}
+		index[inbound.Source.ID] = len(results)
+		results = append(results, result)
+	}
+
+	return results, nil
 }
 
 // getSemanticBacklinksAsResults converts semantic backlinks to BacklinkResult format
@@ -439,24 +608,76 @@ func (p *Processor) rerankBacklinks(query string, backlinks []BacklinkResult, to
 		documents[i] = doc
 	}
 
-	// Rerank using VoyageAI
-	rerankResults, err := p.voyageClient.RerankDocuments(query, documents, p.rerankModel, topK)
+	scores, err := p.rerankScores(query, documents)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reorder backlinks by rerank score
+	order := make([]int, len(backlinks))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scores[order[i]] > scores[order[j]]
+	})
+	if topK > 0 && len(order) > topK {
+		order = order[:topK]
+	}
+
+	reorderedBacklinks := make([]BacklinkResult, len(order))
+	for i, index := range order {
+		backlink := backlinks[index]
+		// Update relevance score with rerank score
+		backlink.RelevanceScore = scores[index]
+		reorderedBacklinks[i] = backlink
+	}
+
+	return reorderedBacklinks, nil
+}
+
+// rerankInputType keys rerank scores in the query cache apart from query embeddings
+const rerankInputType = "rerank"
+
+// rerankCacheKey returns the query cache text of a rerank. The documents are part of the key, so a
+// changed set of backlinks is reranked again.
+func rerankCacheKey(query string, documents []string) string {
+	hash := sha256.New()
+	hash.Write([]byte(query))
+	for _, document := range documents {
+		hash.Write([]byte{0})
+		hash.Write([]byte(document))
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// rerankScores returns the relevance of each document to a query, serving a query reranked
+// against the same documents before from the query cache
+func (p *Processor) rerankScores(query string, documents []string) ([]float32, error) {
+	text := rerankCacheKey(query, documents)
+	if p.queryCache != nil {
+		if scores, ok := p.queryCache.Get(p.rerankModel, rerankInputType, text); ok && len(scores) == len(documents) {
+			return scores, nil
+		}
+	}
+
+	// Every document is scored so the cached scores serve any limit
+	rerankResults, err := p.voyageClient.RerankDocuments(query, documents, p.rerankModel, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to rerank: %w", err)
 	}
 
-	// Reorder backlinks based on rerank results
-	var reorderedBacklinks []BacklinkResult
+	scores := make([]float32, len(documents))
 	for _, result := range rerankResults {
-		if result.OriginalIndex < len(backlinks) {
-			backlink := backlinks[result.OriginalIndex]
-			// Update relevance score with rerank score
-			backlink.RelevanceScore = result.RelevanceScore
-			reorderedBacklinks = append(reorderedBacklinks, backlink)
+		if result.OriginalIndex < len(scores) {
+			scores[result.OriginalIndex] = result.RelevanceScore
 		}
 	}
 
-	return reorderedBacklinks, nil
+	if p.queryCache != nil {
+		p.queryCache.Put(p.rerankModel, rerankInputType, text, scores)
+	}
+	return scores, nil
 }
 
 // FormatBacklinksAsMarkdown formats backlink results as clean markdown
@@ -497,4 +718,77 @@ func (p *Processor) FormatBacklinksAsMarkdown(memoryName string, backlinks []Bac
 // ValidateConfiguration checks if the processor is properly configured
 func (p *Processor) ValidateConfiguration() error {
 	return p.voyageClient.ValidateAPIKey()
-}
\ No newline at end of file
+}
+
+// FindSimilarToMemory finds memories similar to an existing memory using its stored chunk embeddings.
+// Mode "centroid" compares against the mean of the memory's chunk vectors; mode "max" scores each
+// candidate by its best match against any of the memory's chunks. No embedding API calls are made.
+func (p *Processor) FindSimilarToMemory(memoryName string, mode string, tagFilters []db.TagFilter, requireAll bool, namespace string, exclude []string, limit int) ([]db.Memory, []float32, error) {
+	memory, err := p.db.GetMemory(memoryName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get memory: %w", err)
+	}
+	if memory == nil {
+		return nil, nil, fmt.Errorf("memory not found: %s", memoryName)
+	}
+
+	var vectors [][]float32
+	switch mode {
+	case "", "centroid":
+		stored, err := p.db.GetMemoryCentroid(memory.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get stored centroid: %w", err)
+		}
+		if stored != nil {
+			vectors = [][]float32{stored}
+		}
+	case "max":
+		stored, err := p.db.GetEmbeddingsByMemoryID(memory.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get stored embeddings: %w", err)
+		}
+		for _, embedding := range stored {
+			vectors = append(vectors, embedding.Embedding)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown similarity mode '%s' (expected centroid or max)", mode)
+	}
+	if len(vectors) == 0 {
+		return nil, nil, fmt.Errorf("memory %s has no stored embeddings yet", memoryName)
+	}
+
+	// Never return the memory itself
+	excludeNames := append([]string{memory.Name}, exclude...)
+
+	similarMemories, err := p.db.FindSimilarToVectors(vectors, 0.1, limit, excludeNames, tagFilters, requireAll, namespace)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find similar memories: %w", err)
+	}
+
+	memories := make([]db.Memory, len(similarMemories))
+	similarities := make([]float32, len(similarMemories))
+	for i, result := range similarMemories {
+		memories[i] = result.Memory
+		similarities[i] = result.Similarity
+	}
+
+	return memories, similarities, nil
+}
+
+// centroid returns the element-wise mean of the given vectors
+func centroid(vectors [][]float32) []float32 {
+	if len(vectors) == 0 {
+		return nil
+	}
+
+	mean := make([]float32, len(vectors[0]))
+	for _, vector := range vectors {
+		for i, v := range vector {
+			mean[i] += v
+		}
+	}
+	for i := range mean {
+		mean[i] /= float32(len(vectors))
+	}
+	return mean
+}
@@ -0,0 +1,31 @@
+package rag
+
+import (
+	"testing"
+
+	"github.com/jcdickinson/simplemem/internal/db"
+	"github.com/jcdickinson/simplemem/internal/embeddings"
+)
+
+func TestRerankBacklinksCached(t *testing.T) {
+	// No client: only a cached rerank can succeed
+	p := &Processor{queryCache: embeddings.NewQueryCache(10, ""), rerankModel: "rerank-lite-1"}
+	backlinks := []BacklinkResult{
+		{Memory: db.Memory{Name: "a", Title: "A"}, Snippet: "first"},
+		{Memory: db.Memory{Name: "b", Title: "B"}, Snippet: "second"},
+		{Memory: db.Memory{Name: "c", Title: "C"}, Snippet: "third"},
+	}
+	documents := []string{"A\n\nfirst", "B\n\nsecond", "C\n\nthird"}
+	p.queryCache.Put(p.rerankModel, rerankInputType, rerankCacheKey("schema", documents), []float32{0.2, 0.9, 0.5})
+
+	reranked, err := p.rerankBacklinks("schema", backlinks, 2)
+	if err != nil {
+		t.Fatalf("rerankBacklinks() error = %v", err)
+	}
+	if len(reranked) != 2 || reranked[0].Memory.Name != "b" || reranked[1].Memory.Name != "c" || reranked[0].RelevanceScore != 0.9 {
+		t.Errorf("unexpected reranked backlinks %+v", reranked)
+	}
+	if stats := p.queryCache.Stats(); stats.Hits != 1 {
+		t.Errorf("expected the rerank to be served from the cache, got %+v", stats)
+	}
+}
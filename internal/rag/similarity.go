@@ -0,0 +1,67 @@
+package rag
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jcdickinson/simplemem/internal/config"
+)
+
+// Methods for scoring memory-to-memory similarity from chunk embeddings
+const (
+	SimilarityCentroid  = "centroid"    // Cosine similarity of the memories' chunk centroids
+	SimilarityTopKPairs = "top_k_pairs" // Mean of the k most similar chunk pairs
+)
+
+// backlinkSettings controls how semantic backlinks are computed
+type backlinkSettings struct {
+	threshold float32
+	topN      int
+	method    string
+	topK      int
+}
+
+// newBacklinkSettings applies defaults to the configured backlink settings
+func newBacklinkSettings(cfg config.SemanticBacklinksConfig) (backlinkSettings, error) {
+	settings := backlinkSettings{
+		threshold: cfg.Threshold,
+		topN:      cfg.TopN,
+		method:    cfg.Method,
+		topK:      cfg.TopK,
+	}
+
+	if settings.topN <= 0 {
+		settings.topN = 20
+	}
+	if settings.topK <= 0 {
+		settings.topK = 3
+	}
+
+	switch settings.method {
+	case "":
+		settings.method = SimilarityCentroid
+	case SimilarityCentroid, SimilarityTopKPairs:
+	default:
+		return settings, fmt.Errorf("unknown semantic_backlinks.method '%s' (expected %s or %s)", settings.method, SimilarityCentroid, SimilarityTopKPairs)
+	}
+
+	return settings, nil
+}
+
+// CosineSimilarity returns the cosine similarity of two vectors, or 0 if either is zero
+func CosineSimilarity(a, b []float32) float32 {
+	var dot, normA, normB float64
+	for i := range a {
+		if i >= len(b) {
+			break
+		}
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
@@ -0,0 +1,164 @@
+package rag
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/jcdickinson/simplemem/internal/config"
+	"github.com/jcdickinson/simplemem/internal/db"
+)
+
+func TestNewBacklinkSettings(t *testing.T) {
+	settings, err := newBacklinkSettings(config.SemanticBacklinksConfig{Threshold: 0.5})
+	if err != nil {
+		t.Fatalf("newBacklinkSettings() error = %v", err)
+	}
+	if settings.method != SimilarityCentroid || settings.topN != 20 || settings.topK != 3 {
+		t.Errorf("expected defaults, got %+v", settings)
+	}
+
+	if _, err := newBacklinkSettings(config.SemanticBacklinksConfig{Method: "max"}); err == nil {
+		t.Errorf("expected error for unknown method")
+	}
+}
+
+func TestUpdateSemanticBacklinks(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "test.duckdb"))
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	defer database.Close()
+
+	settings, err := newBacklinkSettings(config.SemanticBacklinksConfig{Threshold: 0.5, TopN: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Processor{db: database, backlinks: settings}
+
+	// Memory IDs by name, each with a single chunk pointing in the given direction
+	ids := make(map[string]int)
+	store := func(name string, x, y float32) {
+		t.Helper()
+		if err := database.UpsertMemory(&db.Memory{Name: name, Content: name, Body: name}); err != nil {
+			t.Fatal(err)
+		}
+		memory, _ := database.GetMemory(name)
+		ids[name] = memory.ID
+		if err := database.DeleteEmbeddingsByMemoryID(memory.ID); err != nil {
+			t.Fatal(err)
+		}
+		vector := make([]float32, 1024)
+		vector[0], vector[1] = x, y
+		if err := database.InsertEmbedding(&db.Embedding{MemoryID: memory.ID, ChunkText: name, Embedding: vector}); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.updateSemanticBacklinks(memory.ID); err != nil {
+			t.Fatalf("updateSemanticBacklinks(%s) error = %v", name, err)
+		}
+	}
+	related := func(name string) []string {
+		t.Helper()
+		backlinks, err := database.GetSemanticBacklinks(ids[name], 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, backlink := range backlinks {
+			for other, id := range ids {
+				if other != name && (id == backlink.MemoryAID || id == backlink.MemoryBID) {
+					names = append(names, other)
+				}
+			}
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	store("east", 1, 0)
+	store("east-ish", 1, 0.1)
+	store("north-east", 1, 1)
+	store("north", 0, 1)
+	if got := related("east"); !reflect.DeepEqual(got, []string{"east-ish"}) {
+		t.Errorf("east is related to %v, want [east-ish]", got)
+	}
+	if got := related("north"); !reflect.DeepEqual(got, []string{"north-east"}) {
+		t.Errorf("north is related to %v, want [north-east]", got)
+	}
+
+	// east turning north keeps the pair east-ish chose but drops its own choice below the threshold
+	store("east", 0, 1)
+	if got := related("east"); !reflect.DeepEqual(got, []string{"east-ish", "north"}) {
+		t.Errorf("east is related to %v, want [east-ish north]", got)
+	}
+}
+
+func TestCentroid(t *testing.T) {
+	if got := centroid(nil); got != nil {
+		t.Errorf("centroid(nil) = %v, want nil", got)
+	}
+	got := centroid([][]float32{{1, 0, 2}, {0, 1, 4}})
+	if want := []float32{0.5, 0.5, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("centroid() = %v, want %v", got, want)
+	}
+}
+
+func TestFindSimilarToMemory(t *testing.T) {
+	database, err := db.New(filepath.Join(t.TempDir(), "test.duckdb"))
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	defer database.Close()
+	p := &Processor{db: database}
+
+	store := func(name string, chunks ...[2]float32) {
+		t.Helper()
+		if err := database.UpsertMemory(&db.Memory{Name: name, Content: name, Body: name}); err != nil {
+			t.Fatal(err)
+		}
+		memory, _ := database.GetMemory(name)
+		for i, chunk := range chunks {
+			vector := make([]float32, 1024)
+			vector[0], vector[1] = chunk[0], chunk[1]
+			if err := database.InsertEmbedding(&db.Embedding{MemoryID: memory.ID, ChunkText: name, ChunkIndex: i, Embedding: vector}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := database.UpdateMemoryCentroid(memory.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store("source", [2]float32{1, 0}, [2]float32{0, 1})
+	store("diagonal", [2]float32{1, 1})
+	store("east", [2]float32{1, 0})
+	store("west", [2]float32{-1, 0})
+
+	names := func(mode string, exclude ...string) []string {
+		t.Helper()
+		memories, _, err := p.FindSimilarToMemory("source", mode, nil, false, "", exclude, 10)
+		if err != nil {
+			t.Fatalf("FindSimilarToMemory(%s) error = %v", mode, err)
+		}
+		var names []string
+		for _, memory := range memories {
+			names = append(names, memory.Name)
+		}
+		return names
+	}
+
+	// The centroid (0.5, 0.5) points at diagonal; the source itself is never returned
+	if got := names("centroid"); !reflect.DeepEqual(got, []string{"diagonal", "east"}) {
+		t.Errorf("centroid mode = %v, want [diagonal east]", got)
+	}
+	// A chunk matches east exactly
+	if got := names("max"); !reflect.DeepEqual(got, []string{"east", "diagonal"}) {
+		t.Errorf("max mode = %v, want [east diagonal]", got)
+	}
+	if got := names("centroid", "diagonal"); !reflect.DeepEqual(got, []string{"east"}) {
+		t.Errorf("with exclusions = %v, want [east]", got)
+	}
+	if _, _, err := p.FindSimilarToMemory("source", "median", nil, false, "", nil, 10); err == nil {
+		t.Error("expected an unknown mode to fail")
+	}
+}